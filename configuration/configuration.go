@@ -15,17 +15,22 @@
 package configuration
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	thought "github.com/thoughtnetwork/rosetta-thought/thought"
 	"github.com/thoughtnetwork/rosetta-thought/thoughtd/chaincfg"
 
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
 	"github.com/coinbase/rosetta-sdk-go/storage/encoder"
+	"github.com/coinbase/rosetta-sdk-go/syncer"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -48,6 +53,10 @@ const (
 	// Testnet is Thought Testnet3.
 	Testnet string = "TESTNET"
 
+	// Regtest is a private Thought regression
+	// test network.
+	Regtest string = "REGTEST"
+
 	// mainnetConfigPath is the path of the Thought
 	// configuration file for mainnet.
 	mainnetConfigPath = "/app/thought-mainnet.conf"
@@ -56,6 +65,10 @@ const (
 	// configuration file for testnet.
 	testnetConfigPath = "/app/thought-testnet.conf"
 
+	// regtestConfigPath is the path of the Thought
+	// configuration file for regtest.
+	regtestConfigPath = "/app/thought-regtest.conf"
+
 	// Zstandard compression dictionaries
 	transactionNamespace         = "transaction"
 	testnetTransactionDictionary = "/app/testnet-transaction.zstd"
@@ -63,6 +76,7 @@ const (
 
 	mainnetRPCPort = 10617
 	testnetRPCPort = 11617
+	regtestRPCPort = 12617
 
 	// min prune depth is 288:
 	// https://github.com/bitcoin/bitcoin/blob/ad2952d17a2af419a04256b10b53c7377f826a27/src/validation.h#L84
@@ -75,6 +89,45 @@ const (
 	// attempt to prune once an hour
 	pruneFrequency = 60 * time.Minute
 
+	// readTimeout is the maximum duration for reading the entire
+	// request, including the body.
+	readTimeout = 5 * time.Second
+
+	// writeTimeout is the maximum duration before timing out
+	// writes of the response. It is reset whenever a new
+	// request's header is read.
+	writeTimeout = 15 * time.Second
+
+	// idleTimeout is the maximum amount of time to wait for the
+	// next request when keep-alives are enabled.
+	idleTimeout = 30 * time.Second
+
+	// sizeMultiplier is used to multiply the memory
+	// estimate for pre-fetching blocks. In other words,
+	// this is the estimated memory overhead for each
+	// block fetched by the indexer.
+	sizeMultiplier = 5
+
+	// badgerBlockSize is the size of each block
+	// in an SSTable (1MB).
+	badgerBlockSize = 1 << 20
+
+	// badgerNumCompactors is the number of badger
+	// compaction workers to run concurrently.
+	badgerNumCompactors = 2
+
+	// maxReadyLag is the maximum number of blocks the
+	// indexer can be behind thoughtd while ready.
+	maxReadyLag = int64(6)
+
+	// maxReadyBlockAge is the maximum age of the last
+	// block added by the indexer while ready.
+	maxReadyBlockAge = 2 * time.Hour
+
+	// healthCheckTimeout is the maximum duration of
+	// the thoughtd RPC request of a health check.
+	healthCheckTimeout = 5 * time.Second
+
 	// DataDirectory is the default location for all
 	// persistent data.
 	DataDirectory = "/data"
@@ -98,6 +151,75 @@ const (
 	// read to determine the port for the Rosetta
 	// implementation.
 	PortEnv = "PORT"
+
+	// ConfigFileEnv is the environment variable
+	// read to determine the path of an optional
+	// YAML (or JSON) configuration file. Any other
+	// environment variable overrides the value in
+	// the file.
+	ConfigFileEnv = "CONFIG_FILE"
+
+	// RegtestGenesisHashEnv is the environment variable
+	// read to determine the hash of the genesis block
+	// of a regtest chain. It must be populated to run
+	// on regtest in online mode.
+	RegtestGenesisHashEnv = "REGTEST_GENESIS_HASH"
+
+	// WebhookURLEnv is the environment variable
+	// read to determine the URL that webhook
+	// notifications are posted to. Webhooks are
+	// disabled if it is not populated.
+	WebhookURLEnv = "WEBHOOK_URL"
+
+	// WebhookSecretEnv is the environment variable
+	// read to determine the secret used to sign
+	// webhook notifications.
+	WebhookSecretEnv = "WEBHOOK_SECRET"
+
+	// WebhookAddressesEnv is the environment variable
+	// read to determine the comma-separated addresses
+	// to notify activity on.
+	WebhookAddressesEnv = "WEBHOOK_ADDRESSES"
+
+	// ThoughtdURLEnv is the environment variable
+	// read to determine the RPC URL of an external
+	// thoughtd. rosetta-thought only launches its
+	// own thoughtd if it is not populated.
+	ThoughtdURLEnv = "THOUGHTD_RPC_URL"
+
+	// ThoughtdUserEnv is the environment variable
+	// read to determine the RPC username of an
+	// external thoughtd.
+	ThoughtdUserEnv = "THOUGHTD_RPC_USER"
+
+	// ThoughtdPasswordEnv is the environment variable
+	// read to determine the RPC password of an
+	// external thoughtd.
+	ThoughtdPasswordEnv = "THOUGHTD_RPC_PASSWORD"
+
+	// ThoughtdCookieFileEnv is the environment variable
+	// read to determine the path of the cookie file of
+	// an external thoughtd, which is used instead of a
+	// username and password.
+	ThoughtdCookieFileEnv = "THOUGHTD_RPC_COOKIE_FILE"
+
+	// ThoughtdTLSCAFileEnv is the environment variable
+	// read to determine the CA certificates used to
+	// verify an external thoughtd served over https.
+	ThoughtdTLSCAFileEnv = "THOUGHTD_RPC_TLS_CA_FILE"
+
+	// ThoughtdTLSCertFileEnv and ThoughtdTLSKeyFileEnv
+	// are the environment variables read to determine
+	// the client certificate presented to an external
+	// thoughtd served over https.
+	ThoughtdTLSCertFileEnv = "THOUGHTD_RPC_TLS_CERT_FILE"
+	ThoughtdTLSKeyFileEnv  = "THOUGHTD_RPC_TLS_KEY_FILE"
+
+	// retry delivery of webhook notifications
+	// with exponential backoff up to
+	// maxWebhookRetryInterval
+	webhookRetryInterval    = 1 * time.Second
+	maxWebhookRetryInterval = 5 * time.Minute
 )
 
 // PruningConfiguration is the configuration to
@@ -108,6 +230,129 @@ type PruningConfiguration struct {
 	MinHeight int64
 }
 
+// ServerConfiguration is the configuration
+// of the Rosetta API server.
+type ServerConfiguration struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+// SyncerConfiguration is the configuration
+// of the syncer in the indexer.
+type SyncerConfiguration struct {
+	CacheSize      int
+	SizeMultiplier float64
+	PastBlockLimit int
+	MaxConcurrency int64
+}
+
+// BadgerConfiguration is the configuration of
+// the badger database of the indexer.
+type BadgerConfiguration struct {
+	MaxTableSize     int64
+	ValueLogFileSize int64
+
+	// IndexCacheSize is not limited if 0.
+	IndexCacheSize int64
+	NumMemtables   int
+	BlockSize      int
+	NumCompactors  int
+}
+
+// HealthConfiguration is the configuration of
+// the /readyz endpoint.
+type HealthConfiguration struct {
+	// MaxLag is the maximum number of blocks the
+	// indexer can be behind thoughtd while ready.
+	MaxLag int64
+
+	// MaxBlockAge is the maximum age of the last block
+	// added by the indexer while ready. It is not
+	// checked if 0.
+	MaxBlockAge time.Duration
+
+	// Timeout is the maximum duration of the
+	// thoughtd RPC request of a health check.
+	Timeout time.Duration
+}
+
+// DefaultPruningConfiguration returns the default
+// *PruningConfiguration.
+func DefaultPruningConfiguration() *PruningConfiguration {
+	return &PruningConfiguration{
+		Frequency: pruneFrequency,
+		Depth:     pruneDepth,
+		MinHeight: minPruneHeight,
+	}
+}
+
+// DefaultServerConfiguration returns the default
+// *ServerConfiguration.
+func DefaultServerConfiguration() *ServerConfiguration {
+	return &ServerConfiguration{
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
+	}
+}
+
+// DefaultSyncerConfiguration returns the default
+// *SyncerConfiguration.
+func DefaultSyncerConfiguration() *SyncerConfiguration {
+	return &SyncerConfiguration{
+		CacheSize:      syncer.DefaultCacheSize,
+		SizeMultiplier: sizeMultiplier,
+		PastBlockLimit: syncer.DefaultPastBlockLimit,
+		MaxConcurrency: syncer.DefaultMaxConcurrency,
+	}
+}
+
+// DefaultBadgerConfiguration returns the default
+// *BadgerConfiguration.
+func DefaultBadgerConfiguration() *BadgerConfiguration {
+	return &BadgerConfiguration{
+		MaxTableSize:     database.DefaultMaxTableSize,
+		ValueLogFileSize: database.DefaultLogValueSize,
+		NumMemtables:     1,
+		BlockSize:        badgerBlockSize,
+		NumCompactors:    badgerNumCompactors,
+	}
+}
+
+// DefaultHealthConfiguration returns the default
+// *HealthConfiguration.
+func DefaultHealthConfiguration() *HealthConfiguration {
+	return &HealthConfiguration{
+		MaxLag:      maxReadyLag,
+		MaxBlockAge: maxReadyBlockAge,
+		Timeout:     healthCheckTimeout,
+	}
+}
+
+// WebhookConfiguration is the configuration
+// of webhook notifications in the indexer.
+type WebhookConfiguration struct {
+	URL              string
+	Secret           string
+	Addresses        []string
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+}
+
+// ExternalThoughtdConfiguration is the configuration
+// of the connection to a thoughtd that is not launched
+// by rosetta-thought.
+type ExternalThoughtdConfiguration struct {
+	URL         string
+	Username    string
+	Password    string `json:"-"`
+	CookieFile  string
+	TLSCAFile   string
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
 // Configuration determines how
 type Configuration struct {
 	Mode                   Mode
@@ -123,19 +368,42 @@ type Configuration struct {
 	IndexerPath            string
 	ThoughtdPath           string
 	Compressors            []*encoder.CompressorEntry
+	Webhook                *WebhookConfiguration
+	Server                 *ServerConfiguration
+	Syncer                 *SyncerConfiguration
+	Badger                 *BadgerConfiguration
+	Health                 *HealthConfiguration
+
+	// ExternalThoughtd is nil if rosetta-thought
+	// launches its own thoughtd.
+	ExternalThoughtd *ExternalThoughtdConfiguration
 }
 
 // LoadConfiguration attempts to create a new Configuration
-// using the ENVs in the environment.
+// using the configuration file (if any) and the ENVs in the
+// environment. The data directory in the configuration file
+// overrides baseDirectory.
 func LoadConfiguration(baseDirectory string) (*Configuration, error) {
+	file, err := loadConfigurationFile(os.Getenv(ConfigFileEnv))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.DataDirectory) > 0 {
+		baseDirectory = file.DataDirectory
+	}
+
 	config := &Configuration{}
-	config.Pruning = &PruningConfiguration{
-		Frequency: pruneFrequency,
-		Depth:     pruneDepth,
-		MinHeight: minPruneHeight,
+	config.Pruning = DefaultPruningConfiguration()
+	config.Server = DefaultServerConfiguration()
+	config.Syncer = DefaultSyncerConfiguration()
+	config.Badger = DefaultBadgerConfiguration()
+	config.Health = DefaultHealthConfiguration()
+	if err := file.apply(config); err != nil {
+		return nil, err
 	}
 
-	modeValue := Mode(os.Getenv(ModeEnv))
+	modeValue := Mode(envOrDefault(ModeEnv, file.Mode))
 	switch modeValue {
 	case Online:
 		config.Mode = Online
@@ -156,7 +424,7 @@ func LoadConfiguration(baseDirectory string) (*Configuration, error) {
 		return nil, fmt.Errorf("%s is not a valid mode", modeValue)
 	}
 
-	networkValue := os.Getenv(NetworkEnv)
+	networkValue := envOrDefault(NetworkEnv, file.Network)
 	switch networkValue {
 	case Mainnet:
 		config.Network = &types.NetworkIdentifier{
@@ -192,13 +460,51 @@ func LoadConfiguration(baseDirectory string) (*Configuration, error) {
 				DictionaryPath: testnetTransactionDictionary,
 			},
 		}
+	case Regtest:
+		config.Network = &types.NetworkIdentifier{
+			Blockchain: thought.Blockchain,
+			Network:    thought.RegtestNetwork,
+		}
+		config.NetworkChain = networkValue
+		config.Params = thought.RegtestParams
+		config.Currency = thought.RegtestCurrency
+		config.ConfigPath = regtestConfigPath
+		config.RPCPort = regtestRPCPort
+
+		// There is no compression dictionary for regtest
+		// because every regtest chain is different.
+		config.Compressors = []*encoder.CompressorEntry{}
+
+		genesisBlockIdentifier, err := loadRegtestGenesisBlockIdentifier(
+			config.Mode,
+			envOrDefault(RegtestGenesisHashEnv, file.RegtestGenesisHash),
+		)
+		if err != nil {
+			return nil, err
+		}
+		config.GenesisBlockIdentifier = genesisBlockIdentifier
 	case "":
 		return nil, errors.New("NETWORK must be populated")
 	default:
 		return nil, fmt.Errorf("%s is not a valid network", networkValue)
 	}
 
-	portValue := os.Getenv(PortEnv)
+	if file.TransactionDictionary != nil {
+		config.Compressors = []*encoder.CompressorEntry{}
+		if len(*file.TransactionDictionary) > 0 {
+			config.Compressors = append(config.Compressors, &encoder.CompressorEntry{
+				Namespace:      transactionNamespace,
+				DictionaryPath: *file.TransactionDictionary,
+			})
+		}
+	}
+
+	filePort := ""
+	if file.Port != 0 {
+		filePort = strconv.Itoa(file.Port)
+	}
+
+	portValue := envOrDefault(PortEnv, filePort)
 	if len(portValue) == 0 {
 		return nil, errors.New("PORT must be populated")
 	}
@@ -209,9 +515,205 @@ func LoadConfiguration(baseDirectory string) (*Configuration, error) {
 	}
 	config.Port = port
 
+	webhook, err := loadWebhookConfiguration(config.Mode, file.Webhook)
+	if err != nil {
+		return nil, err
+	}
+	config.Webhook = webhook
+
+	externalThoughtd, err := loadExternalThoughtdConfiguration(config.Mode, file.Thoughtd)
+	if err != nil {
+		return nil, err
+	}
+	config.ExternalThoughtd = externalThoughtd
+
+	// An external thoughtd is managed independently,
+	// so it is never pruned by rosetta-thought.
+	if externalThoughtd != nil {
+		config.Pruning = nil
+	}
+
+	return config, nil
+}
+
+// envOrDefault returns the value of an environment
+// variable or defaultValue if it is not populated.
+func envOrDefault(key string, defaultValue string) string {
+	if value := os.Getenv(key); len(value) > 0 {
+		return value
+	}
+
+	return defaultValue
+}
+
+// loadExternalThoughtdConfiguration returns the
+// *ExternalThoughtdConfiguration in the configuration file
+// and the environment or nil if rosetta-thought launches
+// its own thoughtd.
+func loadExternalThoughtdConfiguration(
+	mode Mode,
+	file *fileThoughtdConfiguration,
+) (*ExternalThoughtdConfiguration, error) {
+	if file == nil {
+		file = &fileThoughtdConfiguration{}
+	}
+
+	urlValue := envOrDefault(ThoughtdURLEnv, file.RPCURL)
+	if len(urlValue) == 0 {
+		return nil, nil
+	}
+
+	urlName := settingName("thoughtd.rpc_url", ThoughtdURLEnv)
+	if mode != Online {
+		return nil, fmt.Errorf("%s can only be populated in %s mode", urlName, Online)
+	}
+
+	thoughtdURL, err := url.Parse(urlValue)
+	if err != nil || (thoughtdURL.Scheme != "http" && thoughtdURL.Scheme != "https") {
+		return nil, fmt.Errorf("%s is not a valid thoughtd url", urlValue)
+	}
+
+	config := &ExternalThoughtdConfiguration{
+		URL:         urlValue,
+		Username:    envOrDefault(ThoughtdUserEnv, file.RPCUser),
+		Password:    envOrDefault(ThoughtdPasswordEnv, file.RPCPassword),
+		CookieFile:  envOrDefault(ThoughtdCookieFileEnv, file.RPCCookieFile),
+		TLSCAFile:   envOrDefault(ThoughtdTLSCAFileEnv, file.RPCTLSCAFile),
+		TLSCertFile: envOrDefault(ThoughtdTLSCertFileEnv, file.RPCTLSCertFile),
+		TLSKeyFile:  envOrDefault(ThoughtdTLSKeyFileEnv, file.RPCTLSKeyFile),
+	}
+
+	userName := settingName("thoughtd.rpc_user", ThoughtdUserEnv)
+	passwordName := settingName("thoughtd.rpc_password", ThoughtdPasswordEnv)
+	cookieFileName := settingName("thoughtd.rpc_cookie_file", ThoughtdCookieFileEnv)
+
+	hasCredentials := len(config.Username) > 0 || len(config.Password) > 0
+	switch {
+	case hasCredentials && len(config.CookieFile) > 0:
+		return nil, fmt.Errorf(
+			"%s cannot be populated with %s or %s",
+			cookieFileName,
+			userName,
+			passwordName,
+		)
+	case hasCredentials && (len(config.Username) == 0 || len(config.Password) == 0):
+		return nil, fmt.Errorf(
+			"%s and %s must both be populated",
+			userName,
+			passwordName,
+		)
+	case !hasCredentials && len(config.CookieFile) == 0:
+		return nil, fmt.Errorf(
+			"%s and %s or %s must be populated to connect to thoughtd",
+			userName,
+			passwordName,
+			cookieFileName,
+		)
+	}
+
+	hasTLS := len(config.TLSCAFile) > 0 || len(config.TLSCertFile) > 0 || len(config.TLSKeyFile) > 0
+	if hasTLS && thoughtdURL.Scheme != "https" {
+		return nil, fmt.Errorf("%s must be an https url to use TLS", urlName)
+	}
+
+	if (len(config.TLSCertFile) > 0) != (len(config.TLSKeyFile) > 0) {
+		return nil, fmt.Errorf(
+			"%s and %s must both be populated",
+			settingName("thoughtd.rpc_tls_cert_file", ThoughtdTLSCertFileEnv),
+			settingName("thoughtd.rpc_tls_key_file", ThoughtdTLSKeyFileEnv),
+		)
+	}
+
 	return config, nil
 }
 
+// loadRegtestGenesisBlockIdentifier returns the genesis block
+// of the regtest chain with hash. It is only required in online
+// mode, where it is reported by /network/status.
+func loadRegtestGenesisBlockIdentifier(mode Mode, hash string) (*types.BlockIdentifier, error) {
+	if len(hash) == 0 {
+		if mode == Online {
+			return nil, fmt.Errorf("%s must be populated on %s", RegtestGenesisHashEnv, Regtest)
+		}
+
+		return nil, nil
+	}
+
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != thought.TransactionHashLength {
+		return nil, fmt.Errorf("%s is not a valid block hash", hash)
+	}
+
+	return &types.BlockIdentifier{
+		Index: 0,
+		Hash:  hash,
+	}, nil
+}
+
+// loadWebhookConfiguration returns the *WebhookConfiguration
+// in the configuration file and the environment or nil if
+// webhooks are disabled.
+func loadWebhookConfiguration(
+	mode Mode,
+	file *fileWebhookConfiguration,
+) (*WebhookConfiguration, error) {
+	if file == nil {
+		file = &fileWebhookConfiguration{}
+	}
+
+	urlValue := envOrDefault(WebhookURLEnv, file.URL)
+	if len(urlValue) == 0 {
+		return nil, nil
+	}
+
+	if mode != Online {
+		return nil, fmt.Errorf(
+			"%s can only be populated in %s mode",
+			settingName("webhook.url", WebhookURLEnv),
+			Online,
+		)
+	}
+
+	webhookURL, err := url.Parse(urlValue)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") {
+		return nil, fmt.Errorf("%s is not a valid webhook url", urlValue)
+	}
+
+	secret := envOrDefault(WebhookSecretEnv, file.Secret)
+	if len(secret) == 0 {
+		return nil, fmt.Errorf(
+			"%s must be populated to sign webhooks",
+			settingName("webhook.secret", WebhookSecretEnv),
+		)
+	}
+
+	addressValues := file.Addresses
+	if addressesValue := os.Getenv(WebhookAddressesEnv); len(addressesValue) > 0 {
+		addressValues = strings.Split(addressesValue, ",")
+	}
+
+	addresses := []string{}
+	for _, address := range addressValues {
+		if address = strings.TrimSpace(address); len(address) > 0 {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return &WebhookConfiguration{
+		URL:              urlValue,
+		Secret:           secret,
+		Addresses:        addresses,
+		RetryInterval:    webhookRetryInterval,
+		MaxRetryInterval: maxWebhookRetryInterval,
+	}, nil
+}
+
+// settingName returns the name of a setting that can be
+// populated in the configuration file or the environment
+// for use in validation errors.
+func settingName(field string, env string) string {
+	return fmt.Sprintf("%s (%s)", field, env)
+}
+
 // ensurePathsExist directories along
 // a path if they do not exist.
 func ensurePathExists(path string) error {
@@ -16,9 +16,12 @@ package configuration
 
 import (
 	"errors"
+	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/thoughtnetwork/rosetta-thought/thought"
 
@@ -30,9 +33,19 @@ import (
 
 func TestLoadConfiguration(t *testing.T) {
 	tests := map[string]struct {
-		Mode    string
-		Network string
-		Port    string
+		Mode             string
+		Network          string
+		Port             string
+		WebhookURL       string
+		WebhookSecret    string
+		WebhookAddresses string
+		GenesisHash      string
+
+		ThoughtdURL        string
+		ThoughtdUser       string
+		ThoughtdPassword   string
+		ThoughtdCookieFile string
+		ThoughtdTLSCAFile  string
 
 		cfg *Configuration
 		err error
@@ -109,6 +122,235 @@ func TestLoadConfiguration(t *testing.T) {
 				},
 			},
 		},
+		"all set (regtest)": {
+			Mode:        string(Online),
+			Network:     Regtest,
+			Port:        "1000",
+			GenesisHash: "6b9a3f5d2c1e0f4a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    thought.RegtestNetwork,
+					Blockchain: thought.Blockchain,
+				},
+				NetworkChain: Regtest,
+				Params:       thought.RegtestParams,
+				Currency:     thought.RegtestCurrency,
+				GenesisBlockIdentifier: &types.BlockIdentifier{
+					Index: 0,
+					Hash:  "6b9a3f5d2c1e0f4a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a",
+				},
+				Port:       1000,
+				RPCPort:    regtestRPCPort,
+				ConfigPath: regtestConfigPath,
+				Pruning: &PruningConfiguration{
+					Frequency: pruneFrequency,
+					Depth:     pruneDepth,
+					MinHeight: minPruneHeight,
+				},
+				Compressors: []*encoder.CompressorEntry{},
+			},
+		},
+		"regtest without genesis hash": {
+			Mode:    string(Online),
+			Network: Regtest,
+			Port:    "1000",
+			err:     errors.New("REGTEST_GENESIS_HASH must be populated on REGTEST"),
+		},
+		"invalid regtest genesis hash": {
+			Mode:        string(Online),
+			Network:     Regtest,
+			Port:        "1000",
+			GenesisHash: "bad hash",
+			err:         errors.New("bad hash is not a valid block hash"),
+		},
+		"webhook set": {
+			Mode:             string(Online),
+			Network:          Testnet,
+			Port:             "1000",
+			WebhookURL:       "https://example.com/hook",
+			WebhookSecret:    "secret",
+			WebhookAddresses: "addr1, addr2,",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    thought.TestnetNetwork,
+					Blockchain: thought.Blockchain,
+				},
+				NetworkChain:           Testnet,
+				Params:                 thought.TestnetParams,
+				Currency:               thought.TestnetCurrency,
+				GenesisBlockIdentifier: thought.TestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				RPCPort:                testnetRPCPort,
+				ConfigPath:             testnetConfigPath,
+				Pruning: &PruningConfiguration{
+					Frequency: pruneFrequency,
+					Depth:     pruneDepth,
+					MinHeight: minPruneHeight,
+				},
+				Compressors: []*encoder.CompressorEntry{
+					{
+						Namespace:      transactionNamespace,
+						DictionaryPath: testnetTransactionDictionary,
+					},
+				},
+				Webhook: &WebhookConfiguration{
+					URL:              "https://example.com/hook",
+					Secret:           "secret",
+					Addresses:        []string{"addr1", "addr2"},
+					RetryInterval:    webhookRetryInterval,
+					MaxRetryInterval: maxWebhookRetryInterval,
+				},
+			},
+		},
+		"external thoughtd set": {
+			Mode:              string(Online),
+			Network:           Testnet,
+			Port:              "1000",
+			ThoughtdURL:       "https://thoughtd:11617",
+			ThoughtdUser:      "user",
+			ThoughtdPassword:  "password",
+			ThoughtdTLSCAFile: "/certs/ca.pem",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    thought.TestnetNetwork,
+					Blockchain: thought.Blockchain,
+				},
+				NetworkChain:           Testnet,
+				Params:                 thought.TestnetParams,
+				Currency:               thought.TestnetCurrency,
+				GenesisBlockIdentifier: thought.TestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				RPCPort:                testnetRPCPort,
+				ConfigPath:             testnetConfigPath,
+				Compressors: []*encoder.CompressorEntry{
+					{
+						Namespace:      transactionNamespace,
+						DictionaryPath: testnetTransactionDictionary,
+					},
+				},
+				ExternalThoughtd: &ExternalThoughtdConfiguration{
+					URL:       "https://thoughtd:11617",
+					Username:  "user",
+					Password:  "password",
+					TLSCAFile: "/certs/ca.pem",
+				},
+			},
+		},
+		"external thoughtd with cookie file": {
+			Mode:               string(Online),
+			Network:            Testnet,
+			Port:               "1000",
+			ThoughtdURL:        "http://thoughtd:11617",
+			ThoughtdCookieFile: "/thoughtd/.cookie",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    thought.TestnetNetwork,
+					Blockchain: thought.Blockchain,
+				},
+				NetworkChain:           Testnet,
+				Params:                 thought.TestnetParams,
+				Currency:               thought.TestnetCurrency,
+				GenesisBlockIdentifier: thought.TestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				RPCPort:                testnetRPCPort,
+				ConfigPath:             testnetConfigPath,
+				Compressors: []*encoder.CompressorEntry{
+					{
+						Namespace:      transactionNamespace,
+						DictionaryPath: testnetTransactionDictionary,
+					},
+				},
+				ExternalThoughtd: &ExternalThoughtdConfiguration{
+					URL:        "http://thoughtd:11617",
+					CookieFile: "/thoughtd/.cookie",
+				},
+			},
+		},
+		"external thoughtd without credentials": {
+			Mode:        string(Online),
+			Network:     Testnet,
+			Port:        "1000",
+			ThoughtdURL: "http://thoughtd:11617",
+			err: errors.New(
+				"thoughtd.rpc_user (THOUGHTD_RPC_USER) and thoughtd.rpc_password (THOUGHTD_RPC_PASSWORD) or " +
+					"thoughtd.rpc_cookie_file (THOUGHTD_RPC_COOKIE_FILE) must be populated",
+			),
+		},
+		"external thoughtd with credentials and cookie file": {
+			Mode:               string(Online),
+			Network:            Testnet,
+			Port:               "1000",
+			ThoughtdURL:        "http://thoughtd:11617",
+			ThoughtdUser:       "user",
+			ThoughtdPassword:   "password",
+			ThoughtdCookieFile: "/thoughtd/.cookie",
+			err:                errors.New("thoughtd.rpc_cookie_file (THOUGHTD_RPC_COOKIE_FILE) cannot be populated"),
+		},
+		"external thoughtd without password": {
+			Mode:         string(Online),
+			Network:      Testnet,
+			Port:         "1000",
+			ThoughtdURL:  "http://thoughtd:11617",
+			ThoughtdUser: "user",
+			err: errors.New(
+				"thoughtd.rpc_user (THOUGHTD_RPC_USER) and thoughtd.rpc_password (THOUGHTD_RPC_PASSWORD) must both be populated",
+			),
+		},
+		"external thoughtd tls without https": {
+			Mode:              string(Online),
+			Network:           Testnet,
+			Port:              "1000",
+			ThoughtdURL:       "http://thoughtd:11617",
+			ThoughtdUser:      "user",
+			ThoughtdPassword:  "password",
+			ThoughtdTLSCAFile: "/certs/ca.pem",
+			err:               errors.New("thoughtd.rpc_url (THOUGHTD_RPC_URL) must be an https url to use TLS"),
+		},
+		"invalid external thoughtd url": {
+			Mode:             string(Online),
+			Network:          Testnet,
+			Port:             "1000",
+			ThoughtdURL:      "thoughtd:11617",
+			ThoughtdUser:     "user",
+			ThoughtdPassword: "password",
+			err:              errors.New("thoughtd:11617 is not a valid thoughtd url"),
+		},
+		"external thoughtd offline": {
+			Mode:             string(Offline),
+			Network:          Testnet,
+			Port:             "1000",
+			ThoughtdURL:      "http://thoughtd:11617",
+			ThoughtdUser:     "user",
+			ThoughtdPassword: "password",
+			err:              errors.New("thoughtd.rpc_url (THOUGHTD_RPC_URL) can only be populated in ONLINE mode"),
+		},
+		"webhook without secret": {
+			Mode:       string(Online),
+			Network:    Testnet,
+			Port:       "1000",
+			WebhookURL: "https://example.com/hook",
+			err:        errors.New("webhook.secret (WEBHOOK_SECRET) must be populated to sign webhooks"),
+		},
+		"invalid webhook url": {
+			Mode:          string(Online),
+			Network:       Testnet,
+			Port:          "1000",
+			WebhookURL:    "example.com",
+			WebhookSecret: "secret",
+			err:           errors.New("example.com is not a valid webhook url"),
+		},
+		"webhook offline": {
+			Mode:          string(Offline),
+			Network:       Testnet,
+			Port:          "1000",
+			WebhookURL:    "https://example.com/hook",
+			WebhookSecret: "secret",
+			err:           errors.New("webhook.url (WEBHOOK_URL) can only be populated in ONLINE mode"),
+		},
 		"invalid mode": {
 			Mode:    "bad mode",
 			Network: Testnet,
@@ -135,9 +377,19 @@ func TestLoadConfiguration(t *testing.T) {
 			assert.NoError(t, err)
 			defer utils.RemoveTempDir(newDir)
 
+			os.Setenv(ConfigFileEnv, "")
 			os.Setenv(ModeEnv, test.Mode)
 			os.Setenv(NetworkEnv, test.Network)
 			os.Setenv(PortEnv, test.Port)
+			os.Setenv(WebhookURLEnv, test.WebhookURL)
+			os.Setenv(WebhookSecretEnv, test.WebhookSecret)
+			os.Setenv(WebhookAddressesEnv, test.WebhookAddresses)
+			os.Setenv(RegtestGenesisHashEnv, test.GenesisHash)
+			os.Setenv(ThoughtdURLEnv, test.ThoughtdURL)
+			os.Setenv(ThoughtdUserEnv, test.ThoughtdUser)
+			os.Setenv(ThoughtdPasswordEnv, test.ThoughtdPassword)
+			os.Setenv(ThoughtdCookieFileEnv, test.ThoughtdCookieFile)
+			os.Setenv(ThoughtdTLSCAFileEnv, test.ThoughtdTLSCAFile)
 
 			cfg, err := LoadConfiguration(newDir)
 			if test.err != nil {
@@ -146,9 +398,356 @@ func TestLoadConfiguration(t *testing.T) {
 			} else {
 				test.cfg.IndexerPath = path.Join(newDir, "indexer")
 				test.cfg.ThoughtdPath = path.Join(newDir, "thoughtd")
+				test.cfg.Server = DefaultServerConfiguration()
+				test.cfg.Syncer = DefaultSyncerConfiguration()
+				test.cfg.Badger = DefaultBadgerConfiguration()
+				test.cfg.Health = DefaultHealthConfiguration()
 				assert.Equal(t, test.cfg, cfg)
 				assert.NoError(t, err)
 			}
 		})
 	}
 }
+
+func TestLoadConfiguration_File(t *testing.T) {
+	tests := map[string]struct {
+		file             string
+		Mode             string
+		Network          string
+		WebhookSecret    string
+		ThoughtdPassword string
+
+		cfg func(dataDirectory string) *Configuration
+		err error
+	}{
+		"yaml": {
+			file: `
+mode: ONLINE
+network: MAINNET
+port: 8080
+data_directory: $DATA_DIRECTORY
+transaction_dictionary: ""
+pruning:
+  frequency: 10m
+  depth: 500
+server:
+  write_timeout: 1m
+syncer:
+  cache_size: 1000
+  max_concurrency: 16
+badger:
+  index_cache_size: 1048576
+  num_compactors: 4
+health:
+  max_lag: 10
+  max_block_age: 0s
+`,
+			// The environment overrides the file
+			Network: Testnet,
+			cfg: func(dataDirectory string) *Configuration {
+				syncer := DefaultSyncerConfiguration()
+				syncer.CacheSize = 1000
+				syncer.MaxConcurrency = 16
+
+				badger := DefaultBadgerConfiguration()
+				badger.IndexCacheSize = 1048576
+				badger.NumCompactors = 4
+
+				return &Configuration{
+					Mode: Online,
+					Network: &types.NetworkIdentifier{
+						Network:    thought.TestnetNetwork,
+						Blockchain: thought.Blockchain,
+					},
+					NetworkChain:           Testnet,
+					Params:                 thought.TestnetParams,
+					Currency:               thought.TestnetCurrency,
+					GenesisBlockIdentifier: thought.TestnetGenesisBlockIdentifier,
+					Port:                   8080,
+					RPCPort:                testnetRPCPort,
+					ConfigPath:             testnetConfigPath,
+					IndexerPath:            path.Join(dataDirectory, "indexer"),
+					ThoughtdPath:           path.Join(dataDirectory, "thoughtd"),
+					Pruning: &PruningConfiguration{
+						Frequency: 10 * time.Minute,
+						Depth:     500,
+						MinHeight: minPruneHeight,
+					},
+					Compressors: []*encoder.CompressorEntry{},
+					Server: &ServerConfiguration{
+						ReadTimeout:  readTimeout,
+						WriteTimeout: time.Minute,
+						IdleTimeout:  idleTimeout,
+					},
+					Syncer: syncer,
+					Badger: badger,
+					Health: &HealthConfiguration{
+						MaxLag:      10,
+						MaxBlockAge: 0,
+						Timeout:     healthCheckTimeout,
+					},
+				}
+			},
+		},
+		"json": {
+			file: `{
+  "mode": "OFFLINE",
+  "network": "MAINNET",
+  "port": 8081,
+  "data_directory": "$DATA_DIRECTORY",
+  "pruning": {"enabled": false},
+  "transaction_dictionary": "/dictionaries/transaction.zstd"
+}`,
+			cfg: func(dataDirectory string) *Configuration {
+				return &Configuration{
+					Mode: Offline,
+					Network: &types.NetworkIdentifier{
+						Network:    thought.MainnetNetwork,
+						Blockchain: thought.Blockchain,
+					},
+					NetworkChain:           Mainnet,
+					Params:                 thought.MainnetParams,
+					Currency:               thought.MainnetCurrency,
+					GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+					Port:                   8081,
+					RPCPort:                mainnetRPCPort,
+					ConfigPath:             mainnetConfigPath,
+					Compressors: []*encoder.CompressorEntry{
+						{
+							Namespace:      transactionNamespace,
+							DictionaryPath: "/dictionaries/transaction.zstd",
+						},
+					},
+					Server: DefaultServerConfiguration(),
+					Syncer: DefaultSyncerConfiguration(),
+					Badger: DefaultBadgerConfiguration(),
+					Health: DefaultHealthConfiguration(),
+				}
+			},
+		},
+		"regtest offline": {
+			file: "mode: OFFLINE\nnetwork: REGTEST\nport: 8081\ndata_directory: $DATA_DIRECTORY\n",
+			cfg: func(dataDirectory string) *Configuration {
+				return &Configuration{
+					Mode: Offline,
+					Network: &types.NetworkIdentifier{
+						Network:    thought.RegtestNetwork,
+						Blockchain: thought.Blockchain,
+					},
+					NetworkChain: Regtest,
+					Params:       thought.RegtestParams,
+					Currency:     thought.RegtestCurrency,
+					Port:         8081,
+					RPCPort:      regtestRPCPort,
+					ConfigPath:   regtestConfigPath,
+					Pruning: &PruningConfiguration{
+						Frequency: pruneFrequency,
+						Depth:     pruneDepth,
+						MinHeight: minPruneHeight,
+					},
+					Compressors: []*encoder.CompressorEntry{},
+					Server:      DefaultServerConfiguration(),
+					Syncer:      DefaultSyncerConfiguration(),
+					Badger:      DefaultBadgerConfiguration(),
+					Health:      DefaultHealthConfiguration(),
+				}
+			},
+		},
+		"regtest genesis hash": {
+			file: "mode: ONLINE\nnetwork: REGTEST\nport: 8080\ndata_directory: $DATA_DIRECTORY\nregtest_genesis_hash: 6b9a3f5d2c1e0f4a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a\n",
+			cfg: func(dataDirectory string) *Configuration {
+				return &Configuration{
+					Mode: Online,
+					Network: &types.NetworkIdentifier{
+						Network:    thought.RegtestNetwork,
+						Blockchain: thought.Blockchain,
+					},
+					NetworkChain: Regtest,
+					Params:       thought.RegtestParams,
+					Currency:     thought.RegtestCurrency,
+					GenesisBlockIdentifier: &types.BlockIdentifier{
+						Index: 0,
+						Hash:  "6b9a3f5d2c1e0f4a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a",
+					},
+					Port:         8080,
+					RPCPort:      regtestRPCPort,
+					ConfigPath:   regtestConfigPath,
+					IndexerPath:  path.Join(dataDirectory, "indexer"),
+					ThoughtdPath: path.Join(dataDirectory, "thoughtd"),
+					Pruning: &PruningConfiguration{
+						Frequency: pruneFrequency,
+						Depth:     pruneDepth,
+						MinHeight: minPruneHeight,
+					},
+					Compressors: []*encoder.CompressorEntry{},
+					Server:      DefaultServerConfiguration(),
+					Syncer:      DefaultSyncerConfiguration(),
+					Badger:      DefaultBadgerConfiguration(),
+					Health:      DefaultHealthConfiguration(),
+				}
+			},
+		},
+		"webhook and thoughtd": {
+			file: `
+mode: ONLINE
+network: TESTNET
+port: 8080
+data_directory: $DATA_DIRECTORY
+webhook:
+  url: https://example.com/hook
+  secret: secret
+  addresses:
+    - tTEWRXqMvEb2iJWPSSHMuuUNGRA5TgqWMG
+    - " tLJgnR4rKBvEaNZRfJBhNyiz4LdUzmrW4Y "
+thoughtd:
+  rpc_url: https://thoughtd:11617
+  rpc_user: user
+  rpc_password: password
+  rpc_tls_ca_file: /certs/ca.pem
+`,
+			// The environment overrides the file
+			WebhookSecret:    "env secret",
+			ThoughtdPassword: "env password",
+			cfg: func(dataDirectory string) *Configuration {
+				return &Configuration{
+					Mode: Online,
+					Network: &types.NetworkIdentifier{
+						Network:    thought.TestnetNetwork,
+						Blockchain: thought.Blockchain,
+					},
+					NetworkChain:           Testnet,
+					Params:                 thought.TestnetParams,
+					Currency:               thought.TestnetCurrency,
+					GenesisBlockIdentifier: thought.TestnetGenesisBlockIdentifier,
+					Port:                   8080,
+					RPCPort:                testnetRPCPort,
+					ConfigPath:             testnetConfigPath,
+					IndexerPath:            path.Join(dataDirectory, "indexer"),
+					ThoughtdPath:           path.Join(dataDirectory, "thoughtd"),
+					Compressors: []*encoder.CompressorEntry{
+						{
+							Namespace:      transactionNamespace,
+							DictionaryPath: testnetTransactionDictionary,
+						},
+					},
+					Server: DefaultServerConfiguration(),
+					Syncer: DefaultSyncerConfiguration(),
+					Badger: DefaultBadgerConfiguration(),
+					Health: DefaultHealthConfiguration(),
+					Webhook: &WebhookConfiguration{
+						URL:    "https://example.com/hook",
+						Secret: "env secret",
+						Addresses: []string{
+							"tTEWRXqMvEb2iJWPSSHMuuUNGRA5TgqWMG",
+							"tLJgnR4rKBvEaNZRfJBhNyiz4LdUzmrW4Y",
+						},
+						RetryInterval:    webhookRetryInterval,
+						MaxRetryInterval: maxWebhookRetryInterval,
+					},
+					ExternalThoughtd: &ExternalThoughtdConfiguration{
+						URL:       "https://thoughtd:11617",
+						Username:  "user",
+						Password:  "env password",
+						TLSCAFile: "/certs/ca.pem",
+					},
+				}
+			},
+		},
+		"webhook without secret": {
+			file: "mode: ONLINE\nnetwork: TESTNET\nport: 8080\ndata_directory: $DATA_DIRECTORY\nwebhook:\n  url: https://example.com/hook\n",
+			err:  errors.New("webhook.secret (WEBHOOK_SECRET) must be populated to sign webhooks"),
+		},
+		"thoughtd offline": {
+			file: "mode: OFFLINE\nnetwork: TESTNET\nport: 8080\nthoughtd:\n  rpc_url: http://thoughtd:11617\n  rpc_cookie_file: /thoughtd/.cookie\n",
+			err:  errors.New("thoughtd.rpc_url (THOUGHTD_RPC_URL) can only be populated in ONLINE mode"),
+		},
+		"thoughtd tls cert without key": {
+			file: "mode: ONLINE\nnetwork: TESTNET\nport: 8080\ndata_directory: $DATA_DIRECTORY\nthoughtd:\n  rpc_url: https://thoughtd:11617\n  rpc_cookie_file: /thoughtd/.cookie\n  rpc_tls_cert_file: /certs/client.pem\n",
+			err: errors.New(
+				"thoughtd.rpc_tls_cert_file (THOUGHTD_RPC_TLS_CERT_FILE) and thoughtd.rpc_tls_key_file (THOUGHTD_RPC_TLS_KEY_FILE) must both be populated",
+			),
+		},
+		"unknown webhook field": {
+			file: "port: 8080\nwebhook:\n  address: tTEWRXqMvEb2iJWPSSHMuuUNGRA5TgqWMG\n",
+			err:  errors.New("field address not found"),
+		},
+		"unknown field": {
+			file: "mode: ONLINE\nnetwork: MAINNET\nport: 8080\nprune_depth: 500\n",
+			err:  errors.New("field prune_depth not found"),
+		},
+		"invalid type": {
+			file: "mode: ONLINE\nnetwork: MAINNET\nport: eighty\n",
+			err:  errors.New("unable to parse configuration file"),
+		},
+		"invalid duration": {
+			file: "port: 8080\nserver:\n  read_timeout: soon\n",
+			err:  errors.New("unable to parse configuration file"),
+		},
+		"pruning too shallow": {
+			file: "port: 8080\npruning:\n  depth: 100\n",
+			err:  errors.New("pruning.depth must be at least 288"),
+		},
+		"invalid syncer": {
+			file: "port: 8080\nsyncer:\n  cache_size: 0\n",
+			err:  errors.New("syncer.cache_size must be positive"),
+		},
+		"invalid badger": {
+			file: "port: 8080\nbadger:\n  num_memtables: -1\n",
+			err:  errors.New("badger.num_memtables must be positive"),
+		},
+		"invalid server": {
+			file: "port: 8080\nserver:\n  idle_timeout: 0s\n",
+			err:  errors.New("server.idle_timeout must be positive"),
+		},
+		"invalid health": {
+			file: "port: 8080\nhealth:\n  max_lag: -1\n",
+			err:  errors.New("health.max_lag cannot be negative"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			newDir, err := utils.CreateTempDir()
+			assert.NoError(t, err)
+			defer utils.RemoveTempDir(newDir)
+
+			dataDirectory := path.Join(newDir, "data")
+			configFile := path.Join(newDir, "config.yaml")
+			contents := strings.ReplaceAll(test.file, "$DATA_DIRECTORY", dataDirectory)
+			assert.NoError(t, ioutil.WriteFile(configFile, []byte(contents), 0600))
+
+			for _, env := range []string{
+				PortEnv,
+				WebhookURLEnv,
+				WebhookAddressesEnv,
+				RegtestGenesisHashEnv,
+				ThoughtdURLEnv,
+				ThoughtdUserEnv,
+				ThoughtdCookieFileEnv,
+				ThoughtdTLSCAFileEnv,
+				ThoughtdTLSCertFileEnv,
+				ThoughtdTLSKeyFileEnv,
+			} {
+				t.Setenv(env, "")
+			}
+			t.Setenv(ConfigFileEnv, configFile)
+			t.Setenv(ModeEnv, test.Mode)
+			t.Setenv(NetworkEnv, test.Network)
+			t.Setenv(WebhookSecretEnv, test.WebhookSecret)
+			t.Setenv(ThoughtdPasswordEnv, test.ThoughtdPassword)
+
+			cfg, err := LoadConfiguration(newDir)
+			if test.err != nil {
+				assert.Nil(t, cfg)
+				assert.Contains(t, err.Error(), test.err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.cfg(dataDirectory), cfg)
+			}
+		})
+	}
+
+	t.Setenv(ConfigFileEnv, path.Join(t.TempDir(), "missing.yaml"))
+	_, err := LoadConfiguration(t.TempDir())
+	assert.Error(t, err)
+}
@@ -0,0 +1,339 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// minPruneDepth is the minimum number of blocks
+	// thoughtd keeps when pruning.
+	minPruneDepth = int64(288)
+)
+
+// fileConfiguration is the schema of the configuration
+// file. Fields that are not populated keep their default
+// values and unknown fields are rejected.
+type fileConfiguration struct {
+	Mode          string `yaml:"mode"`
+	Network       string `yaml:"network"`
+	Port          int    `yaml:"port"`
+	DataDirectory string `yaml:"data_directory"`
+
+	// RegtestGenesisHash is the hash of the genesis
+	// block of the regtest chain.
+	RegtestGenesisHash string `yaml:"regtest_genesis_hash"`
+
+	// TransactionDictionary is the path of the Zstandard
+	// dictionary used to compress transactions. Compression
+	// is disabled if it is empty.
+	TransactionDictionary *string `yaml:"transaction_dictionary"`
+
+	Pruning  *filePruningConfiguration  `yaml:"pruning"`
+	Server   *fileServerConfiguration   `yaml:"server"`
+	Syncer   *fileSyncerConfiguration   `yaml:"syncer"`
+	Badger   *fileBadgerConfiguration   `yaml:"badger"`
+	Health   *fileHealthConfiguration   `yaml:"health"`
+	Webhook  *fileWebhookConfiguration  `yaml:"webhook"`
+	Thoughtd *fileThoughtdConfiguration `yaml:"thoughtd"`
+}
+
+type filePruningConfiguration struct {
+	Enabled   *bool          `yaml:"enabled"`
+	Frequency *time.Duration `yaml:"frequency"`
+	Depth     *int64         `yaml:"depth"`
+	MinHeight *int64         `yaml:"min_height"`
+}
+
+type fileServerConfiguration struct {
+	ReadTimeout  *time.Duration `yaml:"read_timeout"`
+	WriteTimeout *time.Duration `yaml:"write_timeout"`
+	IdleTimeout  *time.Duration `yaml:"idle_timeout"`
+}
+
+type fileSyncerConfiguration struct {
+	CacheSize      *int     `yaml:"cache_size"`
+	SizeMultiplier *float64 `yaml:"size_multiplier"`
+	PastBlockLimit *int     `yaml:"past_block_limit"`
+	MaxConcurrency *int64   `yaml:"max_concurrency"`
+}
+
+type fileBadgerConfiguration struct {
+	MaxTableSize     *int64 `yaml:"max_table_size"`
+	ValueLogFileSize *int64 `yaml:"value_log_file_size"`
+	IndexCacheSize   *int64 `yaml:"index_cache_size"`
+	NumMemtables     *int   `yaml:"num_memtables"`
+	BlockSize        *int   `yaml:"block_size"`
+	NumCompactors    *int   `yaml:"num_compactors"`
+}
+
+type fileHealthConfiguration struct {
+	MaxLag      *int64         `yaml:"max_lag"`
+	MaxBlockAge *time.Duration `yaml:"max_block_age"`
+	Timeout     *time.Duration `yaml:"timeout"`
+}
+
+// fileWebhookConfiguration is overridden field by
+// field by the WEBHOOK_* environment variables.
+type fileWebhookConfiguration struct {
+	URL       string   `yaml:"url"`
+	Secret    string   `yaml:"secret"`
+	Addresses []string `yaml:"addresses"`
+}
+
+// fileThoughtdConfiguration is overridden field by
+// field by the THOUGHTD_RPC_* environment variables.
+type fileThoughtdConfiguration struct {
+	RPCURL         string `yaml:"rpc_url"`
+	RPCUser        string `yaml:"rpc_user"`
+	RPCPassword    string `yaml:"rpc_password"`
+	RPCCookieFile  string `yaml:"rpc_cookie_file"`
+	RPCTLSCAFile   string `yaml:"rpc_tls_ca_file"`
+	RPCTLSCertFile string `yaml:"rpc_tls_cert_file"`
+	RPCTLSKeyFile  string `yaml:"rpc_tls_key_file"`
+}
+
+// loadConfigurationFile parses the configuration file at
+// path. An empty *fileConfiguration is returned if path
+// is empty.
+func loadConfigurationFile(path string) (*fileConfiguration, error) {
+	file := &fileConfiguration{}
+	if len(path) == 0 {
+		return file, nil
+	}
+
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open configuration file %s", err, path)
+	}
+	defer f.Close()
+
+	// YAML is a superset of JSON, so both
+	// are parsed by the same decoder.
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: unable to parse configuration file %s", err, path)
+	}
+
+	return file, nil
+}
+
+// apply overwrites the defaults in config with the
+// values in the configuration file and validates them.
+func (f *fileConfiguration) apply(config *Configuration) error {
+	if f.Port < 0 {
+		return fmt.Errorf("port %d is invalid", f.Port)
+	}
+
+	if err := f.Pruning.apply(config); err != nil {
+		return err
+	}
+
+	if err := f.Server.apply(config.Server); err != nil {
+		return err
+	}
+
+	if err := f.Syncer.apply(config.Syncer); err != nil {
+		return err
+	}
+
+	if err := f.Badger.apply(config.Badger); err != nil {
+		return err
+	}
+
+	return f.Health.apply(config.Health)
+}
+
+func (f *filePruningConfiguration) apply(config *Configuration) error {
+	if f == nil {
+		return nil
+	}
+
+	if f.Enabled != nil && !*f.Enabled {
+		config.Pruning = nil
+		return nil
+	}
+
+	if f.Frequency != nil {
+		config.Pruning.Frequency = *f.Frequency
+	}
+
+	if f.Depth != nil {
+		config.Pruning.Depth = *f.Depth
+	}
+
+	if f.MinHeight != nil {
+		config.Pruning.MinHeight = *f.MinHeight
+	}
+
+	switch {
+	case config.Pruning.Frequency <= 0:
+		return errors.New("pruning.frequency must be positive")
+	case config.Pruning.Depth < minPruneDepth:
+		return fmt.Errorf("pruning.depth must be at least %d", minPruneDepth)
+	case config.Pruning.MinHeight < 0:
+		return errors.New("pruning.min_height cannot be negative")
+	}
+
+	return nil
+}
+
+func (f *fileServerConfiguration) apply(config *ServerConfiguration) error {
+	if f == nil {
+		return nil
+	}
+
+	if f.ReadTimeout != nil {
+		config.ReadTimeout = *f.ReadTimeout
+	}
+
+	if f.WriteTimeout != nil {
+		config.WriteTimeout = *f.WriteTimeout
+	}
+
+	if f.IdleTimeout != nil {
+		config.IdleTimeout = *f.IdleTimeout
+	}
+
+	switch {
+	case config.ReadTimeout <= 0:
+		return errors.New("server.read_timeout must be positive")
+	case config.WriteTimeout <= 0:
+		return errors.New("server.write_timeout must be positive")
+	case config.IdleTimeout <= 0:
+		return errors.New("server.idle_timeout must be positive")
+	}
+
+	return nil
+}
+
+func (f *fileSyncerConfiguration) apply(config *SyncerConfiguration) error {
+	if f == nil {
+		return nil
+	}
+
+	if f.CacheSize != nil {
+		config.CacheSize = *f.CacheSize
+	}
+
+	if f.SizeMultiplier != nil {
+		config.SizeMultiplier = *f.SizeMultiplier
+	}
+
+	if f.PastBlockLimit != nil {
+		config.PastBlockLimit = *f.PastBlockLimit
+	}
+
+	if f.MaxConcurrency != nil {
+		config.MaxConcurrency = *f.MaxConcurrency
+	}
+
+	switch {
+	case config.CacheSize <= 0:
+		return errors.New("syncer.cache_size must be positive")
+	case config.SizeMultiplier <= 0:
+		return errors.New("syncer.size_multiplier must be positive")
+	case config.PastBlockLimit <= 0:
+		return errors.New("syncer.past_block_limit must be positive")
+	case config.MaxConcurrency <= 0:
+		return errors.New("syncer.max_concurrency must be positive")
+	}
+
+	return nil
+}
+
+func (f *fileBadgerConfiguration) apply(config *BadgerConfiguration) error {
+	if f == nil {
+		return nil
+	}
+
+	if f.MaxTableSize != nil {
+		config.MaxTableSize = *f.MaxTableSize
+	}
+
+	if f.ValueLogFileSize != nil {
+		config.ValueLogFileSize = *f.ValueLogFileSize
+	}
+
+	if f.IndexCacheSize != nil {
+		config.IndexCacheSize = *f.IndexCacheSize
+	}
+
+	if f.NumMemtables != nil {
+		config.NumMemtables = *f.NumMemtables
+	}
+
+	if f.BlockSize != nil {
+		config.BlockSize = *f.BlockSize
+	}
+
+	if f.NumCompactors != nil {
+		config.NumCompactors = *f.NumCompactors
+	}
+
+	switch {
+	case config.MaxTableSize <= 0:
+		return errors.New("badger.max_table_size must be positive")
+	case config.ValueLogFileSize <= 0:
+		return errors.New("badger.value_log_file_size must be positive")
+	case config.IndexCacheSize < 0:
+		return errors.New("badger.index_cache_size cannot be negative")
+	case config.NumMemtables <= 0:
+		return errors.New("badger.num_memtables must be positive")
+	case config.BlockSize <= 0:
+		return errors.New("badger.block_size must be positive")
+	case config.NumCompactors <= 0:
+		return errors.New("badger.num_compactors must be positive")
+	}
+
+	return nil
+}
+
+func (f *fileHealthConfiguration) apply(config *HealthConfiguration) error {
+	if f == nil {
+		return nil
+	}
+
+	if f.MaxLag != nil {
+		config.MaxLag = *f.MaxLag
+	}
+
+	if f.MaxBlockAge != nil {
+		config.MaxBlockAge = *f.MaxBlockAge
+	}
+
+	if f.Timeout != nil {
+		config.Timeout = *f.Timeout
+	}
+
+	switch {
+	case config.MaxLag < 0:
+		return errors.New("health.max_lag cannot be negative")
+	case config.MaxBlockAge < 0:
+		return errors.New("health.max_block_age cannot be negative")
+	case config.Timeout <= 0:
+		return errors.New("health.timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	eventNamespace = "event"
+
+	// eventHeadKey stores the number
+	// of events in the log.
+	eventHeadKey = eventNamespace + "/head"
+)
+
+var _ modules.BlockWorker = (*EventStorage)(nil)
+
+// EventStorage keeps a log of the blocks added and removed
+// by BlockStorage. Each event is stored in the same database
+// transaction as the block it refers to, so the log always
+// matches the blocks in storage.
+type EventStorage struct {
+	db database.Database
+}
+
+// NewEventStorage returns a new *EventStorage.
+func NewEventStorage(db database.Database) *EventStorage {
+	return &EventStorage{db: db}
+}
+
+// eventKey returns the key of the event
+// with sequence.
+func eventKey(sequence int64) []byte {
+	return []byte(fmt.Sprintf("%s/block/%019d", eventNamespace, sequence))
+}
+
+// eventCount returns the number of events in the log.
+func eventCount(ctx context.Context, dbTx database.Transaction) (int64, error) {
+	exists, value, err := dbTx.Get(ctx, []byte(eventHeadKey))
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to get event head", err)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	count, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to parse event head", err)
+	}
+
+	return count, nil
+}
+
+// appendEvent adds an event of eventType for
+// blockIdentifier to the end of the log.
+func (s *EventStorage) appendEvent(
+	ctx context.Context,
+	dbTx database.Transaction,
+	blockIdentifier *types.BlockIdentifier,
+	eventType types.BlockEventType,
+) error {
+	sequence, err := eventCount(ctx, dbTx)
+	if err != nil {
+		return err
+	}
+
+	value, err := s.db.Encoder().Encode(eventNamespace, &types.BlockEvent{
+		Sequence:        sequence,
+		BlockIdentifier: blockIdentifier,
+		Type:            eventType,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode block event", err)
+	}
+
+	if err := dbTx.Set(ctx, eventKey(sequence), value, true); err != nil {
+		return fmt.Errorf("%w: unable to store block event", err)
+	}
+
+	head := []byte(strconv.FormatInt(sequence+1, 10))
+	if err := dbTx.Set(ctx, []byte(eventHeadKey), head, true); err != nil {
+		return fmt.Errorf("%w: unable to update event head", err)
+	}
+
+	return nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (s *EventStorage) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, s.appendEvent(ctx, transaction, block.BlockIdentifier, types.ADDED)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (s *EventStorage) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, s.appendEvent(ctx, transaction, block.BlockIdentifier, types.REMOVED)
+}
+
+// Events returns up to limit events starting at offset and the
+// sequence of the last event in the log (-1 if there are no
+// events). If offset is nil, the last limit events are returned.
+func (s *EventStorage) Events(
+	ctx context.Context,
+	offset *int64,
+	limit int64,
+) ([]*types.BlockEvent, int64, error) {
+	dbTx := s.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	count, err := eventCount(ctx, dbTx)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	start := count - limit
+	if offset != nil {
+		start = *offset
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	events := []*types.BlockEvent{}
+	for sequence := start; sequence < count && sequence < start+limit; sequence++ {
+		exists, value, err := dbTx.Get(ctx, eventKey(sequence))
+		if err != nil {
+			return nil, -1, fmt.Errorf("%w: unable to get block event %d", err, sequence)
+		}
+
+		if !exists {
+			return nil, -1, fmt.Errorf("block event %d not found", sequence)
+		}
+
+		var event types.BlockEvent
+		if err := s.db.Encoder().Decode(eventNamespace, value, &event, true); err != nil {
+			return nil, -1, fmt.Errorf("%w: unable to decode block event %d", err, sequence)
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, count - 1, nil
+}
@@ -0,0 +1,316 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	historyNamespace = "history"
+
+	// historyOldestKey stores the index of the oldest
+	// block in the history. The history of an address
+	// is only complete if it starts at genesis.
+	historyOldestKey = historyNamespace + "/oldest"
+)
+
+var (
+	_ modules.BlockWorker = (*HistoryStorage)(nil)
+
+	// errHistoryScanDone stops a scan of the history once
+	// a page is complete or the block range is exhausted.
+	errHistoryScanDone = errors.New("history page full")
+)
+
+// HistoryStorage indexes the net amount each transaction
+// adds to (or removes from) the balance of every address
+// in its operations, so that the history of an address can
+// be paged through from the most recent block.
+type HistoryStorage struct {
+	db database.Database
+}
+
+// historyEntry is the value of a transaction
+// in the history of an address.
+type historyEntry struct {
+	BlockHash string        `json:"block_hash"`
+	NetAmount *types.Amount `json:"net_amount"`
+}
+
+// NewHistoryStorage returns a new *HistoryStorage.
+func NewHistoryStorage(db database.Database) *HistoryStorage {
+	return &HistoryStorage{db: db}
+}
+
+// historyPrefix returns the prefix of
+// the history of an address.
+func historyPrefix(address string) []byte {
+	return []byte(fmt.Sprintf("%s/%s/", historyNamespace, address))
+}
+
+// historyCursor returns the position of a transaction in
+// the history of an address. The block index is inverted
+// so that the history is scanned from the most recent block.
+func historyCursor(blockIndex int64, transactionHash string) string {
+	return fmt.Sprintf("%019d/%s", math.MaxInt64-blockIndex, transactionHash)
+}
+
+// parseHistoryCursor returns the block index and
+// transaction hash of a history cursor.
+func parseHistoryCursor(cursor string) (int64, string, error) {
+	parts := strings.SplitN(cursor, "/", 2) // nolint:gomnd
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return -1, "", fmt.Errorf("history cursor %s is invalid", cursor)
+	}
+
+	invertedIndex, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || invertedIndex < 0 {
+		return -1, "", fmt.Errorf("history cursor %s is invalid", cursor)
+	}
+
+	return math.MaxInt64 - invertedIndex, parts[1], nil
+}
+
+// historyKey returns the key of a transaction
+// in the history of an address.
+func historyKey(address string, blockIndex int64, transactionHash string) []byte {
+	return append(historyPrefix(address), []byte(historyCursor(blockIndex, transactionHash))...)
+}
+
+// netAmounts returns the net amount each transaction
+// in a block adds to the balance of each address.
+func netAmounts(block *types.Block) (map[string]map[string]*types.Amount, error) {
+	amounts := map[string]map[string]*types.Amount{}
+	for _, transaction := range block.Transactions {
+		sums := map[string]*big.Int{}
+		currencies := map[string]*types.Currency{}
+		for _, op := range transaction.Operations {
+			if op.Account == nil || op.Amount == nil {
+				continue
+			}
+
+			value, err := types.BigInt(op.Amount.Value)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%w: unable to parse amount of transaction %s",
+					err,
+					transaction.TransactionIdentifier.Hash,
+				)
+			}
+
+			address := op.Account.Address
+			if _, ok := sums[address]; !ok {
+				sums[address] = new(big.Int)
+				currencies[address] = op.Amount.Currency
+			}
+			sums[address].Add(sums[address], value)
+		}
+
+		transactionAmounts := map[string]*types.Amount{}
+		for address, sum := range sums {
+			transactionAmounts[address] = &types.Amount{
+				Value:    sum.String(),
+				Currency: currencies[address],
+			}
+		}
+		amounts[transaction.TransactionIdentifier.Hash] = transactionAmounts
+	}
+
+	return amounts, nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (s *HistoryStorage) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	amounts, err := netAmounts(block)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, _, err := transaction.Get(ctx, []byte(historyOldestKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get oldest history block", err)
+	}
+
+	if !exists {
+		oldest := []byte(strconv.FormatInt(block.BlockIdentifier.Index, 10))
+		if err := transaction.Set(ctx, []byte(historyOldestKey), oldest, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store oldest history block", err)
+		}
+	}
+
+	for transactionHash, transactionAmounts := range amounts {
+		for address, amount := range transactionAmounts {
+			value, err := s.db.Encoder().Encode(historyNamespace, &historyEntry{
+				BlockHash: block.BlockIdentifier.Hash,
+				NetAmount: amount,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to encode history entry", err)
+			}
+
+			key := historyKey(address, block.BlockIdentifier.Index, transactionHash)
+			if err := transaction.Set(ctx, key, value, true); err != nil {
+				return nil, fmt.Errorf("%w: unable to store history entry", err)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (s *HistoryStorage) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	amounts, err := netAmounts(block)
+	if err != nil {
+		return nil, err
+	}
+
+	for transactionHash, transactionAmounts := range amounts {
+		for address := range transactionAmounts {
+			key := historyKey(address, block.BlockIdentifier.Index, transactionHash)
+			if err := transaction.Delete(ctx, key); err != nil {
+				return nil, fmt.Errorf("%w: unable to delete history entry", err)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// OldestIndex returns the index of the oldest block in the
+// history, or false if no block has been indexed.
+func (s *HistoryStorage) OldestIndex(
+	ctx context.Context,
+	dbTx database.Transaction,
+) (int64, bool, error) {
+	exists, value, err := dbTx.Get(ctx, []byte(historyOldestKey))
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to get oldest history block", err)
+	}
+
+	if !exists {
+		return -1, false, nil
+	}
+
+	index, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to parse oldest history block", err)
+	}
+
+	return index, true, nil
+}
+
+// Transactions returns up to limit transactions in the history
+// of an address, from the most recent block, that are in blocks
+// between minIndex and maxIndex (if populated). Paging starts at
+// cursor (if populated) and the cursor of the next page is
+// returned if there are more transactions.
+func (s *HistoryStorage) Transactions(
+	ctx context.Context,
+	dbTx database.Transaction,
+	address string,
+	cursor string,
+	minIndex *int64,
+	maxIndex *int64,
+	limit int64,
+) ([]*thought.HistoryTransaction, string, error) {
+	start := historyCursor(math.MaxInt64, "")
+	if maxIndex != nil {
+		start = historyCursor(*maxIndex, "")
+	}
+
+	if len(cursor) > 0 {
+		if _, _, err := parseHistoryCursor(cursor); err != nil {
+			return nil, "", err
+		}
+
+		if cursor > start {
+			start = cursor
+		}
+	}
+
+	prefix := historyPrefix(address)
+	transactions := []*thought.HistoryTransaction{}
+	nextCursor := ""
+	_, err := dbTx.Scan(
+		ctx,
+		prefix,
+		append(historyPrefix(address), []byte(start)...),
+		func(k []byte, v []byte) error {
+			blockIndex, transactionHash, err := parseHistoryCursor(string(k[len(prefix):]))
+			if err != nil {
+				return err
+			}
+
+			if minIndex != nil && blockIndex < *minIndex {
+				return errHistoryScanDone
+			}
+
+			if int64(len(transactions)) == limit {
+				nextCursor = string(k[len(prefix):])
+				return errHistoryScanDone
+			}
+
+			var entry historyEntry
+			if err := s.db.Encoder().Decode(historyNamespace, v, &entry, true); err != nil {
+				return fmt.Errorf("%w: unable to decode history entry", err)
+			}
+
+			transactions = append(transactions, &thought.HistoryTransaction{
+				BlockIdentifier: &types.BlockIdentifier{
+					Index: blockIndex,
+					Hash:  entry.BlockHash,
+				},
+				TransactionIdentifier: &types.TransactionIdentifier{
+					Hash: transactionHash,
+				},
+				NetAmount: entry.NetAmount,
+			})
+
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil && !errors.Is(err, errHistoryScanDone) {
+		return nil, "", fmt.Errorf("%w: unable to scan history", err)
+	}
+
+	return transactions, nextCursor, nil
+}
@@ -19,11 +19,13 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/thoughtnetwork/rosetta-thought/thought"
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/metrics"
 	"github.com/thoughtnetwork/rosetta-thought/services"
 	"github.com/thoughtnetwork/rosetta-thought/utils"
 
@@ -53,11 +55,9 @@ const (
 	nodeWaitSleep           = 3 * time.Second
 	missingTransactionDelay = 200 * time.Millisecond
 
-	// sizeMultiplier is used to multiply the memory
-	// estimate for pre-fetching blocks. In other words,
-	// this is the estimated memory overhead for each
-	// block fetched by the indexer.
-	sizeMultiplier = 5
+	// mempoolSyncFrequency is how often we
+	// fetch the contents of thoughtd's mempool.
+	mempoolSyncFrequency = 5 * time.Second
 
 	// zeroValue is 0 as a string
 	zeroValue = "0"
@@ -71,7 +71,9 @@ const (
 	// semaphoreWeight is the weight of each semaphore request.
 	semaphoreWeight = int64(1)
 
-	blockLeftShift = 20
+	// blockTransactionNamespace is the namespace of
+	// transactions in modules.BlockStorage.
+	blockTransactionNamespace = "transaction"
 )
 
 var (
@@ -88,6 +90,15 @@ type Client interface {
 		*thought.Block,
 		map[string]*types.AccountCoin,
 	) (*types.Block, error)
+	GetRawTransaction(context.Context, string) (*thought.Transaction, error)
+	GetMempoolEntry(context.Context, string) (*thought.MempoolEntry, error)
+	ParseTransaction(
+		context.Context,
+		*thought.Transaction,
+		map[string]*types.AccountCoin,
+	) (*types.Transaction, error)
+	ParseCoins(*thought.Transaction) (map[string]*types.AccountCoin, error)
+	RawMempool(context.Context) ([]string, error)
 }
 
 var _ syncer.Handler = (*Indexer)(nil)
@@ -99,7 +110,10 @@ type Indexer struct {
 	cancel context.CancelFunc
 
 	network       *types.NetworkIdentifier
+	genesisBlock  *types.BlockIdentifier
 	pruningConfig *configuration.PruningConfiguration
+	webhookConfig *configuration.WebhookConfiguration
+	syncerConfig  *configuration.SyncerConfiguration
 
 	client Client
 
@@ -108,10 +122,23 @@ type Indexer struct {
 	blockStorage   *modules.BlockStorage
 	balanceStorage *modules.BalanceStorage
 	coinStorage    *modules.CoinStorage
+	searchStorage  *SearchStorage
+	eventStorage   *EventStorage
+	historyStorage *HistoryStorage
+	statsStorage   *StatisticsStorage
+	webhookStorage *WebhookStorage
 	workers        []modules.BlockWorker
 
 	waiter *waitTable
 
+	// Track coins created and spent by transactions
+	// in the mempool.
+	mempool *mempool
+
+	// Publish block, mempool and address events
+	// to WebSocket subscribers.
+	subscriptions *services.Subscriptions
+
 	// Store coins created in pre-store before persisted
 	// in add block so we can optimistically populate
 	// blocks before committed.
@@ -124,6 +151,11 @@ type Indexer struct {
 	seen      int64
 	seenMutex sync.Mutex
 
+	// removing is true while the syncer is removing
+	// blocks during a reorg, so that each reorg is
+	// only counted once.
+	removing bool
+
 	seenSemaphore *semaphore.Weighted
 }
 
@@ -139,10 +171,12 @@ func (i *Indexer) CloseDatabase(ctx context.Context) {
 	logger.Infow("database closed successfully")
 }
 
-// defaultBadgerOptions returns a set of badger.Options optimized
-// for running a Rosetta implementation.
-func defaultBadgerOptions(
+// badgerOptions returns a set of badger.Options optimized
+// for running a Rosetta implementation, with the sizes in
+// a *configuration.BadgerConfiguration.
+func badgerOptions(
 	dir string,
+	config *configuration.BadgerConfiguration,
 ) badger.Options {
 	opts := badger.DefaultOptions(dir)
 
@@ -155,16 +189,19 @@ func defaultBadgerOptions(
 	opts.ValueLogLoadingMode = options.FileIO
 	
 	// Use an extended table size for larger commits.
-	opts.MaxTableSize = database.DefaultMaxTableSize
+	opts.MaxTableSize = config.MaxTableSize
 
 	// Smaller value log sizes means smaller contiguous memory allocations
 	// and less RAM usage on cleanup.
-	opts.ValueLogFileSize = database.DefaultLogValueSize
+	opts.ValueLogFileSize = config.ValueLogFileSize
+
+	// Limit the memory used by table indices (unlimited if 0).
+	opts.IndexCacheSize = config.IndexCacheSize
 
 	// To allow writes at a faster speed, we create a new memtable as soon as
 	// an existing memtable is filled up. This option determines how many
 	// memtables should be kept in memory.
-	opts.NumMemtables = 1
+	opts.NumMemtables = config.NumMemtables
 
 	// Don't keep multiple memtables in memory. With larger
 	// memtable size, this explodes memory usage.
@@ -185,11 +222,11 @@ func defaultBadgerOptions(
 	opts.LoadBloomsOnOpen = false
 
 	// BlockSize sets the size of any block in SSTable. SSTable is divided into multiple blocks
-	// internally. We set each block to 1MB.
-	opts.BlockSize = 1 << blockLeftShift // 1MB
+	// internally. We set each block to 1MB by default.
+	opts.BlockSize = config.BlockSize
 
 	// NumCompactors sets the number of compaction workers to run concurrently.
-	opts.NumCompactors = 2
+	opts.NumCompactors = config.NumCompactors
 
 	return opts
 }
@@ -201,12 +238,23 @@ func Initialize(
 	config *configuration.Configuration,
 	client Client,
 ) (*Indexer, error) {
+	badgerConfig := config.Badger
+	if badgerConfig == nil {
+		badgerConfig = configuration.DefaultBadgerConfiguration()
+	}
+
+	syncerConfig := config.Syncer
+	if syncerConfig == nil {
+		syncerConfig = configuration.DefaultSyncerConfiguration()
+	}
+
 	localStore, err := database.NewBadgerDatabase(
 		ctx,
 		config.IndexerPath,
 		database.WithCompressorEntries(config.Compressors),
-		database.WithCustomSettings(defaultBadgerOptions(
+		database.WithCustomSettings(badgerOptions(
 			config.IndexerPath,
+			badgerConfig,
 		)),
 	)
 	if err != nil {
@@ -230,11 +278,16 @@ func Initialize(
 	i := &Indexer{
 		cancel:         cancel,
 		network:        config.Network,
+		genesisBlock:   config.GenesisBlockIdentifier,
 		pruningConfig:  config.Pruning,
+		webhookConfig:  config.Webhook,
+		syncerConfig:   syncerConfig,
 		client:         client,
 		database:       localStore,
 		blockStorage:   blockStorage,
 		waiter:         newWaitTable(),
+		mempool:        newMempool(),
+		subscriptions:  services.NewSubscriptions(),
 		asserter:       asserter,
 		coinCache:      map[string]*types.AccountCoin{},
 		coinCacheMutex: new(sdkUtils.PriorityMutex),
@@ -255,7 +308,31 @@ func Initialize(
 	)
 	i.balanceStorage = balanceStorage
 
-	i.workers = []modules.BlockWorker{coinStorage, balanceStorage}
+	searchStorage := NewSearchStorage(localStore)
+	i.searchStorage = searchStorage
+
+	eventStorage := NewEventStorage(localStore)
+	i.eventStorage = eventStorage
+
+	historyStorage := NewHistoryStorage(localStore)
+	i.historyStorage = historyStorage
+
+	statsStorage := NewStatisticsStorage(localStore)
+	i.statsStorage = statsStorage
+
+	i.workers = []modules.BlockWorker{
+		coinStorage,
+		balanceStorage,
+		searchStorage,
+		eventStorage,
+		historyStorage,
+		statsStorage,
+	}
+
+	if config.Webhook != nil {
+		i.webhookStorage = NewWebhookStorage(localStore, config.Webhook.Addresses)
+		i.workers = append(i.workers, i.webhookStorage)
+	}
 
 	return i, nil
 }
@@ -290,21 +367,24 @@ func (i *Indexer) Sync(ctx context.Context) error {
 	head, err := i.blockStorage.GetHeadBlockIdentifier(ctx)
 	if err == nil {
 		startIndex = head.Index + 1
+		metrics.IndexerHeight.Set(float64(head.Index))
 	}
 
 	// Load in previous blocks into syncer cache to handle reorgs.
 	// If previously processed blocks exist in storage, they are fetched.
 	// Otherwise, none are provided to the cache (the syncer will not attempt
 	// a reorg if the cache is empty).
-	pastBlocks := i.blockStorage.CreateBlockCache(ctx, syncer.DefaultPastBlockLimit)
+	pastBlocks := i.blockStorage.CreateBlockCache(ctx, i.syncerConfig.PastBlockLimit)
 
 	syncer := syncer.New(
 		i.network,
 		i,
 		i,
 		i.cancel,
-		syncer.WithCacheSize(syncer.DefaultCacheSize),
-		syncer.WithSizeMultiplier(sizeMultiplier),
+		syncer.WithCacheSize(i.syncerConfig.CacheSize),
+		syncer.WithSizeMultiplier(i.syncerConfig.SizeMultiplier),
+		syncer.WithPastBlockLimit(i.syncerConfig.PastBlockLimit),
+		syncer.WithMaxConcurrency(i.syncerConfig.MaxConcurrency),
 		syncer.WithPastBlocks(pastBlocks),
 	)
 
@@ -354,6 +434,99 @@ func (i *Indexer) Prune(ctx context.Context) error {
 	}
 }
 
+// SyncMempool tracks the coins created and spent by
+// transactions in thoughtd's mempool until stopped.
+func (i *Indexer) SyncMempool(ctx context.Context) error {
+	if err := i.waitForNode(ctx); err != nil {
+		return fmt.Errorf("%w: failed to wait for node", err)
+	}
+
+	logger := utils.ExtractLogger(ctx, "mempool")
+	for {
+		// Inputs cannot be hydrated until at least one
+		// block has been indexed.
+		_, err := i.blockStorage.GetHeadBlockIdentifier(ctx)
+		if err == nil {
+			if err := i.updateMempool(ctx); err != nil {
+				logger.Warnw("unable to update mempool", "error", err)
+			}
+		}
+
+		if err := sdkUtils.ContextSleep(ctx, mempoolSyncFrequency); err != nil {
+			logger.Warnw("exiting mempool sync")
+			return err
+		}
+	}
+}
+
+// updateMempool fetches the transactions in thoughtd's mempool,
+// tracking any new transactions and forgetting any transactions
+// that are no longer in the mempool.
+func (i *Indexer) updateMempool(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "mempool")
+	hashes, err := i.client.RawMempool(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get raw mempool", err)
+	}
+
+	current := make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		current[hash] = struct{}{}
+		if i.mempool.Has(hash) {
+			continue
+		}
+
+		entry, err := i.getMempoolEntry(ctx, hash)
+		if err != nil {
+			// The transaction may have been confirmed or evicted
+			// since we fetched the mempool (or spends a coin we have
+			// not yet indexed), so we try again on the next update.
+			logger.Debugw(
+				"unable to track mempool transaction",
+				"hash", hash,
+				"error", err,
+			)
+			continue
+		}
+
+		i.mempool.Add(hash, entry)
+		i.publishMempoolTransaction(hash, entry)
+	}
+
+	for _, hash := range i.mempool.Hashes() {
+		if _, ok := current[hash]; !ok {
+			i.mempool.Remove(hash)
+		}
+	}
+
+	return nil
+}
+
+// getMempoolEntry returns the coins created and spent by
+// a transaction in thoughtd's mempool.
+func (i *Indexer) getMempoolEntry(ctx context.Context, hash string) (*mempoolEntry, error) {
+	rawTransaction, err := i.client.GetRawTransaction(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get raw transaction", err)
+	}
+
+	spent, err := i.findMempoolCoins(ctx, rawTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to find input transactions", err)
+	}
+
+	created, err := i.client.ParseCoins(rawTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse coins", err)
+	}
+
+	return &mempoolEntry{
+		transaction: rawTransaction,
+		created:     created,
+		spent:       spent,
+	}, nil
+}
+
 // BlockAdded is called by the syncer when a block is added.
 func (i *Indexer) BlockAdded(ctx context.Context, block *types.Block) error {
 	logger := utils.ExtractLogger(ctx, "indexer")
@@ -388,8 +561,14 @@ func (i *Indexer) BlockAdded(ctx context.Context, block *types.Block) error {
 			delete(i.coinCache, op.CoinChange.CoinIdentifier.Identifier)
 		}
 	}
+	metrics.CoinCacheSize.Set(float64(len(i.coinCache)))
 	i.coinCacheMutex.Unlock()
 
+	// Transactions in the block are no longer in the mempool.
+	for _, tx := range block.Transactions {
+		i.mempool.Remove(tx.TransactionIdentifier.Hash)
+	}
+
 	// Look for all remaining waiting transactions associated
 	// with the next block that have not yet been closed. We should
 	// abort these waits as they will never be closed by a new transaction.
@@ -409,6 +588,12 @@ func (i *Indexer) BlockAdded(ctx context.Context, block *types.Block) error {
 	}
 	i.waiter.Unlock()
 
+	i.publishBlockAdded(block)
+
+	i.removing = false
+	metrics.BlocksAdded.Inc()
+	metrics.IndexerHeight.Set(float64(block.BlockIdentifier.Index))
+
 	logger.Debugw(
 		"block added",
 		"hash", block.BlockIdentifier.Hash,
@@ -451,6 +636,7 @@ func (i *Indexer) BlockSeen(ctx context.Context, block *types.Block) error {
 			}
 		}
 	}
+	metrics.CoinCacheSize.Set(float64(len(i.coinCache)))
 	i.coinCacheMutex.Unlock()
 
 	// Update so that lookers know it exists
@@ -526,6 +712,16 @@ func (i *Indexer) BlockRemoved(
 		)
 	}
 
+	i.publishBlockRemoved(blockIdentifier)
+
+	// Consecutive removals are part of the same reorg
+	if !i.removing {
+		metrics.Reorgs.Inc()
+	}
+	i.removing = true
+	metrics.BlocksRemoved.Inc()
+	metrics.IndexerHeight.Set(float64(blockIdentifier.Index - 1))
+
 	return nil
 }
 
@@ -535,7 +731,14 @@ func (i *Indexer) NetworkStatus(
 	ctx context.Context,
 	network *types.NetworkIdentifier,
 ) (*types.NetworkStatusResponse, error) {
-	return i.client.NetworkStatus(ctx)
+	status, err := i.client.NetworkStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.NodeHeight.Set(float64(status.CurrentBlockIdentifier.Index))
+
+	return status, nil
 }
 
 func (i *Indexer) findCoin(
@@ -816,6 +1019,17 @@ func (i *Indexer) GetScriptPubKeys(
 			return nil, fmt.Errorf("%w: unable to parse coin identifier", err)
 		}
 
+		// Coins created in the mempool are not yet in block storage.
+		accountCoin, script, ok := i.mempool.Coin(coinIdentifier.Identifier)
+		if ok {
+			if err := checkCoinAmount(accountCoin.Coin.Amount, coin.Amount); err != nil {
+				return nil, err
+			}
+
+			scripts[j] = script
+			continue
+		}
+
 		_, transaction, err := i.blockStorage.FindTransaction(
 			ctx,
 			&types.TransactionIdentifier{Hash: transactionHash.String()},
@@ -847,24 +1061,8 @@ func (i *Indexer) GetScriptPubKeys(
 				)
 			}
 
-			if types.Hash(op.Amount.Currency) != types.Hash(coin.Amount.Currency) {
-				return nil, fmt.Errorf(
-					"currency expected %s does not match coin %s",
-					types.PrintStruct(coin.Amount.Currency),
-					types.PrintStruct(op.Amount.Currency),
-				)
-			}
-
-			addition, err := types.AddValues(op.Amount.Value, coin.Amount.Value)
-			if err != nil {
-				return nil, fmt.Errorf("%w: unable to add op amount and coin amount", err)
-			}
-
-			if addition != "0" {
-				return nil, fmt.Errorf(
-					"coin amount does not match expected with difference %s",
-					addition,
-				)
+			if err := checkCoinAmount(op.Amount, coin.Amount); err != nil {
+				return nil, err
 			}
 
 			scripts[j] = opMetadata.ScriptPubKey
@@ -879,6 +1077,32 @@ func (i *Indexer) GetScriptPubKeys(
 	return scripts, nil
 }
 
+// checkCoinAmount ensures the (negated) amount provided with a coin
+// matches the amount of the output that created it.
+func checkCoinAmount(output *types.Amount, coin *types.Amount) error {
+	if types.Hash(output.Currency) != types.Hash(coin.Currency) {
+		return fmt.Errorf(
+			"currency expected %s does not match coin %s",
+			types.PrintStruct(coin.Currency),
+			types.PrintStruct(output.Currency),
+		)
+	}
+
+	addition, err := types.AddValues(output.Value, coin.Value)
+	if err != nil {
+		return fmt.Errorf("%w: unable to add op amount and coin amount", err)
+	}
+
+	if addition != zeroValue {
+		return fmt.Errorf(
+			"coin amount does not match expected with difference %s",
+			addition,
+		)
+	}
+
+	return nil
+}
+
 // GetBlockLazy returns a *types.BlockResponse from the indexer's block storage.
 // All transactions in a block must be fetched individually.
 func (i *Indexer) GetBlockLazy(
@@ -902,12 +1126,821 @@ func (i *Indexer) GetBlockTransaction(
 	)
 }
 
+// blockTransaction is a transaction stored
+// by modules.BlockStorage.
+type blockTransaction struct {
+	Transaction *types.Transaction `json:"transaction"`
+	BlockIndex  int64              `json:"block_index"`
+}
+
+// getBlockTransaction returns a *types.Transaction in the provided
+// *types.BlockIdentifier in a database transaction. BlockStorage
+// only looks up transactions in its own database transaction, so
+// they are read with the same key and encoding.
+func (i *Indexer) getBlockTransaction(
+	ctx context.Context,
+	dbTx database.Transaction,
+	blockIdentifier *types.BlockIdentifier,
+	transactionIdentifier *types.TransactionIdentifier,
+) (*types.Transaction, error) {
+	oldestIndex, err := i.blockStorage.GetOldestBlockIndexTransactional(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get oldest block index", err)
+	}
+
+	if blockIdentifier.Index < oldestIndex {
+		return nil, storageErrs.ErrCannotAccessPrunedData
+	}
+
+	key := fmt.Sprintf(
+		"%s/%s/%s",
+		blockTransactionNamespace,
+		transactionIdentifier.Hash,
+		blockIdentifier.Hash,
+	)
+	exists, value, err := dbTx.Get(ctx, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get transaction", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf(
+			"%w %s",
+			storageErrs.ErrTransactionNotFound,
+			transactionIdentifier.Hash,
+		)
+	}
+
+	var transaction blockTransaction
+	err = i.database.Encoder().Decode(blockTransactionNamespace, value, &transaction, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode transaction", err)
+	}
+
+	return transaction.Transaction, nil
+}
+
+// checkSearchIndex returns thought.ErrSearchIndexIncomplete
+// if the search index does not start at genesis, in which
+// case transactions in blocks indexed before the index was
+// introduced cannot be found.
+func (i *Indexer) checkSearchIndex(ctx context.Context, dbTx database.Transaction) error {
+	oldest, ok, err := i.searchStorage.OldestIndex(ctx, dbTx)
+	if err != nil {
+		return err
+	}
+
+	if !ok || oldest > i.genesisBlock.Index {
+		return fmt.Errorf(
+			"%w: oldest indexed block is %d, not genesis",
+			thought.ErrSearchIndexIncomplete,
+			oldest,
+		)
+	}
+
+	return nil
+}
+
+// FindTransaction returns a transaction and the
+// block containing it in the canonical chain. It
+// returns thought.ErrTransactionNotFound if the
+// transaction is not indexed and
+// thought.ErrSearchIndexIncomplete if the search
+// index does not start at genesis.
+func (i *Indexer) FindTransaction(
+	ctx context.Context,
+	transactionIdentifier *types.TransactionIdentifier,
+) (*types.BlockIdentifier, *types.Transaction, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	if err := i.checkSearchIndex(ctx, dbTx); err != nil {
+		return nil, nil, err
+	}
+
+	entries, _, err := i.searchStorage.Entries(
+		ctx,
+		dbTx,
+		searchTransactionKind,
+		transactionIdentifier.Hash,
+		"",
+		1,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, nil, fmt.Errorf(
+			"%w: transaction %s is not indexed",
+			thought.ErrTransactionNotFound,
+			transactionIdentifier.Hash,
+		)
+	}
+
+	// Entries are removed with their block, so the
+	// most recent entry is in the canonical chain.
+	blockIdentifier := entries[0].blockIdentifier
+	transaction, err := i.getBlockTransaction(ctx, dbTx, blockIdentifier, transactionIdentifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blockIdentifier, transaction, nil
+}
+
+// SearchTransactions returns the transactions matching a
+// *types.SearchTransactionsRequest, starting from the most recent
+// block. At least one of the transaction identifier, account
+// identifier, address or coin identifier must be populated. When
+// the OR operator is used, only these filters are supported.
+func (i *Indexer) SearchTransactions(
+	ctx context.Context,
+	request *types.SearchTransactionsRequest,
+	offset int64,
+	limit int64,
+) (*types.SearchTransactionsResponse, error) {
+	keys := [][2]string{}
+	if request.TransactionIdentifier != nil {
+		keys = append(keys, [2]string{searchTransactionKind, request.TransactionIdentifier.Hash})
+	}
+	if request.AccountIdentifier != nil {
+		keys = append(keys, [2]string{searchAccountKind, request.AccountIdentifier.Address})
+	}
+	if request.Address != nil {
+		keys = append(keys, [2]string{searchAccountKind, *request.Address})
+	}
+	if request.CoinIdentifier != nil {
+		keys = append(keys, [2]string{searchCoinKind, request.CoinIdentifier.Identifier})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("search requires a transaction, account or coin identifier")
+	}
+
+	or := request.Operator != nil && *request.Operator == types.OR
+
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	if err := i.checkSearchIndex(ctx, dbTx); err != nil {
+		return nil, err
+	}
+
+	start := ""
+	if request.MaxBlock != nil {
+		start = searchCursor(*request.MaxBlock, "")
+	}
+
+	iterators := make([]*searchIterator, len(keys))
+	for j, key := range keys {
+		iterators[j] = i.searchStorage.Iterator(dbTx, key[0], key[1], start)
+	}
+
+	// Indexes are sorted from the most recent block, so
+	// candidates are merged from their iterators in order.
+	// Candidates must be in every index unless the OR
+	// operator is used.
+	nextCandidate := func() (*searchEntry, error) {
+		if !or {
+			for {
+				entry, err := iterators[0].Next(ctx)
+				if err != nil || entry == nil {
+					return entry, err
+				}
+
+				matches := true
+				for _, key := range keys[1:] {
+					contains, err := i.searchStorage.Contains(ctx, dbTx, key[0], key[1], entry)
+					if err != nil {
+						return nil, err
+					}
+
+					if !contains {
+						matches = false
+						break
+					}
+				}
+
+				if matches {
+					return entry, nil
+				}
+			}
+		}
+
+		var candidate *searchEntry
+		for _, iterator := range iterators {
+			entry, err := iterator.Peek(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if entry != nil && (candidate == nil || entry.cursor() < candidate.cursor()) {
+				candidate = entry
+			}
+		}
+
+		if candidate == nil {
+			return nil, nil
+		}
+
+		for _, iterator := range iterators {
+			entry, err := iterator.Peek(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if entry != nil && entry.cursor() == candidate.cursor() {
+				if _, err := iterator.Next(ctx); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return candidate, nil
+	}
+
+	// Transactions are only fetched for every candidate
+	// if they must be matched against operation filters.
+	filterOperations := !or && (request.Currency != nil ||
+		request.Status != nil ||
+		request.Type != nil ||
+		request.Success != nil)
+
+	results := []*types.BlockTransaction{}
+	count := int64(0)
+	for {
+		candidate, err := nextCandidate()
+		if err != nil {
+			return nil, err
+		}
+
+		if candidate == nil {
+			break
+		}
+
+		inPage := count >= offset && int64(len(results)) < limit
+		if !filterOperations && !inPage {
+			count++
+			continue
+		}
+
+		transaction, err := i.getBlockTransaction(
+			ctx,
+			dbTx,
+			candidate.blockIdentifier,
+			candidate.transactionIdentifier,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to get transaction %s in block %s",
+				err,
+				candidate.transactionIdentifier.Hash,
+				candidate.blockIdentifier.Hash,
+			)
+		}
+
+		if filterOperations && !matchesOperationFilters(request, transaction) {
+			continue
+		}
+
+		if inPage {
+			results = append(results, &types.BlockTransaction{
+				BlockIdentifier: candidate.blockIdentifier,
+				Transaction:     transaction,
+			})
+		}
+		count++
+	}
+
+	response := &types.SearchTransactionsResponse{
+		Transactions: results,
+		TotalCount:   count,
+	}
+	if nextOffset := offset + int64(len(results)); nextOffset < count {
+		response.NextOffset = &nextOffset
+	}
+
+	return response, nil
+}
+
+// matchesOperationFilters returns true if a transaction has
+// operations matching the currency, status and type filters
+// of a search request, and is successful if requested.
+func matchesOperationFilters(
+	request *types.SearchTransactionsRequest,
+	transaction *types.Transaction,
+) bool {
+	currency, status, opType, success := false, false, false, false
+	for _, op := range transaction.Operations {
+		if op.Amount != nil && types.Hash(op.Amount.Currency) == types.Hash(request.Currency) {
+			currency = true
+		}
+
+		if op.Status != nil {
+			if request.Status != nil && *op.Status == *request.Status {
+				status = true
+			}
+
+			for _, opStatus := range thought.OperationStatuses {
+				if opStatus.Successful && opStatus.Status == *op.Status {
+					success = true
+				}
+			}
+		}
+
+		if request.Type != nil && op.Type == *request.Type {
+			opType = true
+		}
+	}
+
+	return (request.Currency == nil || currency) &&
+		(request.Status == nil || status) &&
+		(request.Type == nil || opType) &&
+		(request.Success == nil || *request.Success == success)
+}
+
+// GetBlockEvents returns up to limit block events starting at
+// offset and the sequence of the last block event (-1 if no
+// blocks have been indexed). If offset is nil, the last limit
+// block events are returned.
+func (i *Indexer) GetBlockEvents(
+	ctx context.Context,
+	offset *int64,
+	limit int64,
+) ([]*types.BlockEvent, int64, error) {
+	return i.eventStorage.Events(ctx, offset, limit)
+}
+
+// GetAccountHistory returns up to limit transactions of an
+// account, from the most recent block, in blocks between
+// minIndex and maxIndex (if populated). Each transaction is
+// returned with its block and the net amount it adds to the
+// balance of the account. Paging starts at cursor (if not
+// empty) and the cursor of the next page is returned if there
+// are more transactions.
+func (i *Indexer) GetAccountHistory(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	cursor string,
+	minIndex *int64,
+	maxIndex *int64,
+	limit int64,
+) ([]*thought.HistoryTransaction, string, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	// Transactions in blocks added before the history
+	// was introduced are missing from the history.
+	oldest, ok, err := i.historyStorage.OldestIndex(ctx, dbTx)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok || oldest > i.genesisBlock.Index {
+		return nil, "", fmt.Errorf(
+			"history does not start at genesis (oldest block %d), resync to look up account history",
+			oldest,
+		)
+	}
+
+	return i.historyStorage.Transactions(
+		ctx,
+		dbTx,
+		account.Address,
+		cursor,
+		minIndex,
+		maxIndex,
+		limit,
+	)
+}
+
+// GetStatistics returns the statistics of the unspent coins
+// at the current block, including the limit addresses with
+// the largest balances.
+func (i *Indexer) GetStatistics(
+	ctx context.Context,
+	currency *types.Currency,
+	limit int64,
+) (*thought.Statistics, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	head, err := i.blockStorage.GetHeadBlockIdentifierTransactional(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Coins created before the statistics were
+	// introduced are not accounted for.
+	oldest, ok, err := i.statsStorage.OldestIndex(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || oldest > i.genesisBlock.Index {
+		return nil, fmt.Errorf(
+			"statistics do not start at genesis (oldest block %d), resync to compute statistics",
+			oldest,
+		)
+	}
+
+	stats, err := i.statsStorage.Statistics(ctx, dbTx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	richList := make([]*thought.AccountBalance, len(stats.richAccounts))
+	for j, account := range stats.richAccounts {
+		richList[j] = &thought.AccountBalance{
+			AccountIdentifier: account,
+			Balance: &types.Amount{
+				Value:    stats.richBalances[j].String(),
+				Currency: currency,
+			},
+		}
+	}
+
+	return &thought.Statistics{
+		BlockIdentifier: head,
+		Supply: &types.Amount{
+			Value:    stats.supply.String(),
+			Currency: currency,
+		},
+		CoinCount:    stats.coinCount,
+		AddressCount: stats.addressCount,
+		RichList:     richList,
+	}, nil
+}
+
+// GetMempoolTransaction returns a *types.Transaction for a transaction
+// in thoughtd's mempool. Inputs are hydrated using coin storage or, when
+// they spend the output of another unconfirmed transaction, by fetching
+// that transaction from thoughtd.
+func (i *Indexer) GetMempoolTransaction(
+	ctx context.Context,
+	transactionIdentifier *types.TransactionIdentifier,
+) (*types.Transaction, error) {
+	// thoughtd runs without a transaction index, so checking the mempool
+	// entry first ensures we only ever return unconfirmed transactions.
+	if _, err := i.client.GetMempoolEntry(ctx, transactionIdentifier.Hash); err != nil {
+		return nil, fmt.Errorf("%w: unable to get mempool entry", err)
+	}
+
+	rawTransaction, err := i.client.GetRawTransaction(ctx, transactionIdentifier.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get raw transaction", err)
+	}
+
+	coinMap, err := i.findMempoolCoins(ctx, rawTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to find input transactions", err)
+	}
+
+	transaction, err := i.client.ParseTransaction(ctx, rawTransaction, coinMap)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: unable to parse transaction %s",
+			err,
+			transactionIdentifier.Hash,
+		)
+	}
+
+	if err := i.asserter.Transaction(transaction); err != nil {
+		return nil, fmt.Errorf(
+			"%w: transaction is not valid %s",
+			err,
+			transactionIdentifier.Hash,
+		)
+	}
+
+	return transaction, nil
+}
+
+// findMempoolCoins returns the coins spent by the inputs of
+// a raw transaction that has not yet been included in a block.
+func (i *Indexer) findMempoolCoins(
+	ctx context.Context,
+	rawTransaction *thought.Transaction,
+) (map[string]*types.AccountCoin, error) {
+	databaseTransaction := i.database.ReadTransaction(ctx)
+	defer databaseTransaction.Discard(ctx)
+
+	coinMap := map[string]*types.AccountCoin{}
+	parentCoins := map[string]map[string]*types.AccountCoin{}
+	for _, input := range rawTransaction.Inputs {
+		coinIdentifier := thought.CoinIdentifier(input.TxHash, input.Vout)
+		coin, owner, err := i.coinStorage.GetCoinTransactional(
+			ctx,
+			databaseTransaction,
+			&types.CoinIdentifier{
+				Identifier: coinIdentifier,
+			},
+		)
+		if err == nil {
+			coinMap[coinIdentifier] = &types.AccountCoin{
+				Account: owner,
+				Coin:    coin,
+			}
+			continue
+		}
+
+		if !errors.Is(err, storageErrs.ErrCoinNotFound) {
+			return nil, fmt.Errorf("%w: unable to lookup coin %s", err, coinIdentifier)
+		}
+
+		// Check seen CoinCache
+		i.coinCacheMutex.Lock(false)
+		accCoin, ok := i.coinCache[coinIdentifier]
+		i.coinCacheMutex.Unlock()
+		if ok {
+			coinMap[coinIdentifier] = accCoin
+			continue
+		}
+
+		// Check tracked mempool transactions
+		accCoin, _, ok = i.mempool.Coin(coinIdentifier)
+		if ok {
+			coinMap[coinIdentifier] = accCoin
+			continue
+		}
+
+		// If the coin is not yet indexed, it must have been created
+		// by another transaction in the mempool.
+		coins, ok := parentCoins[input.TxHash]
+		if !ok {
+			parent, err := i.client.GetRawTransaction(ctx, input.TxHash)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%w: unable to get previous transaction %s",
+					err,
+					input.TxHash,
+				)
+			}
+
+			coins, err = i.client.ParseCoins(parent)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%w: unable to parse previous transaction %s",
+					err,
+					input.TxHash,
+				)
+			}
+
+			parentCoins[input.TxHash] = coins
+		}
+
+		accCoin, ok = coins[coinIdentifier]
+		if !ok {
+			return nil, fmt.Errorf("unable to find coin %s", coinIdentifier)
+		}
+
+		coinMap[coinIdentifier] = accCoin
+	}
+
+	return coinMap, nil
+}
+
 // GetCoins returns all unspent coins for a particular *types.AccountIdentifier.
+// If includeMempool is true, coins spent in the mempool are omitted and
+// coins created in the mempool are included. If blockIdentifier is not
+// nil, the coins owned by the account at that block are returned instead.
 func (i *Indexer) GetCoins(
 	ctx context.Context,
 	accountIdentifier *types.AccountIdentifier,
+	includeMempool bool,
+	blockIdentifier *types.PartialBlockIdentifier,
 ) ([]*types.Coin, *types.BlockIdentifier, error) {
-	return i.coinStorage.GetCoins(ctx, accountIdentifier)
+	if blockIdentifier != nil {
+		if includeMempool {
+			return nil, nil, errors.New("mempool coins cannot be included at a past block")
+		}
+
+		dbTx := i.database.ReadTransaction(ctx)
+		defer dbTx.Discard(ctx)
+
+		blockResponse, err := i.blockStorage.GetBlockLazyTransactional(ctx, blockIdentifier, dbTx)
+		if err != nil {
+			return nil, nil, err
+		}
+		block := blockResponse.Block.BlockIdentifier
+
+		coins, err := i.getHistoricalCoins(ctx, dbTx, accountIdentifier, block)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return coins, block, nil
+	}
+
+	coins, block, err := i.coinStorage.GetCoins(ctx, accountIdentifier)
+	if err != nil || !includeMempool {
+		return coins, block, err
+	}
+
+	return i.mempool.Coins(accountIdentifier, coins), block, nil
+}
+
+// getHistoricalCoins reconstructs the coins owned by an account at
+// a particular *types.BlockIdentifier in a database transaction by
+// undoing the coins created and spent after it, according to the
+// transactions in the search index, on the coins owned at the head.
+// Coins are sorted by identifier.
+func (i *Indexer) getHistoricalCoins(
+	ctx context.Context,
+	dbTx database.Transaction,
+	accountIdentifier *types.AccountIdentifier,
+	blockIdentifier *types.BlockIdentifier,
+) ([]*types.Coin, error) {
+	headCoins, head, err := i.coinStorage.GetCoinsTransactional(ctx, dbTx, accountIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	coins := map[string]*types.Coin{}
+	for _, coin := range headCoins {
+		coins[coin.CoinIdentifier.Identifier] = coin
+	}
+
+	// Only blocks after blockIdentifier must be
+	// covered by the search index.
+	if head.Index > blockIdentifier.Index {
+		oldest, ok, err := i.searchStorage.OldestIndex(ctx, dbTx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok || oldest > blockIdentifier.Index+1 {
+			return nil, fmt.Errorf(
+				"%w: oldest indexed block is %d, not %d",
+				thought.ErrSearchIndexIncomplete,
+				oldest,
+				blockIdentifier.Index+1,
+			)
+		}
+	}
+
+	// Coins created after the block are removed and coins
+	// spent after the block are restored (unless they were
+	// also created after it).
+	created := map[string]struct{}{}
+	accountHash := types.Hash(accountIdentifier)
+	iterator := i.searchStorage.Iterator(dbTx, searchAccountKind, accountIdentifier.Address, "")
+	for {
+		entry, err := iterator.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry == nil || entry.blockIdentifier.Index <= blockIdentifier.Index {
+			break
+		}
+
+		transaction, err := i.getBlockTransaction(
+			ctx,
+			dbTx,
+			entry.blockIdentifier,
+			entry.transactionIdentifier,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, op := range transaction.Operations {
+			if op.CoinChange == nil || op.Account == nil || types.Hash(op.Account) != accountHash {
+				continue
+			}
+
+			identifier := op.CoinChange.CoinIdentifier.Identifier
+			switch op.CoinChange.CoinAction {
+			case types.CoinCreated:
+				created[identifier] = struct{}{}
+			case types.CoinSpent:
+				value, err := types.NegateValue(op.Amount.Value)
+				if err != nil {
+					return nil, fmt.Errorf("%w: unable to parse amount of coin %s", err, identifier)
+				}
+
+				coins[identifier] = &types.Coin{
+					CoinIdentifier: op.CoinChange.CoinIdentifier,
+					Amount: &types.Amount{
+						Value:    value,
+						Currency: op.Amount.Currency,
+					},
+				}
+			}
+		}
+	}
+
+	unspent := []*types.Coin{}
+	for identifier, coin := range coins {
+		if _, ok := created[identifier]; !ok {
+			unspent = append(unspent, coin)
+		}
+	}
+
+	sort.Slice(unspent, func(a, b int) bool {
+		return unspent[a].CoinIdentifier.Identifier < unspent[b].CoinIdentifier.Identifier
+	})
+
+	return unspent, nil
+}
+
+// GetAccounts returns the balances and/or coins of many accounts
+// at a particular *types.PartialBlockIdentifier in a single
+// database transaction. Balances and coins are in the order of
+// accountIdentifiers and are nil if they are not included. Coins
+// at a past block are reconstructed from the search index (see
+// getHistoricalCoins).
+func (i *Indexer) GetAccounts(
+	ctx context.Context,
+	accountIdentifiers []*types.AccountIdentifier,
+	currency *types.Currency,
+	includeBalances bool,
+	includeCoins bool,
+	blockIdentifier *types.PartialBlockIdentifier,
+) ([]*types.Amount, [][]*types.Coin, *types.BlockIdentifier, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	blockResponse, err := i.blockStorage.GetBlockLazyTransactional(
+		ctx,
+		blockIdentifier,
+		dbTx,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	block := blockResponse.Block.BlockIdentifier
+
+	historical := false
+	if includeCoins && blockIdentifier != nil {
+		head, err := i.blockStorage.GetHeadBlockIdentifierTransactional(ctx, dbTx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		historical = types.Hash(head) != types.Hash(block)
+	}
+
+	var balances []*types.Amount
+	if includeBalances {
+		balances = make([]*types.Amount, len(accountIdentifiers))
+		for j, accountIdentifier := range accountIdentifiers {
+			amount, err := i.balanceStorage.GetBalanceTransactional(
+				ctx,
+				dbTx,
+				accountIdentifier,
+				currency,
+				block.Index,
+			)
+			if errors.Is(err, storageErrs.ErrAccountMissing) {
+				amount = &types.Amount{
+					Value:    zeroValue,
+					Currency: currency,
+				}
+			} else if err != nil {
+				return nil, nil, nil, err
+			}
+
+			balances[j] = amount
+		}
+	}
+
+	var coins [][]*types.Coin
+	if includeCoins {
+		coins = make([][]*types.Coin, len(accountIdentifiers))
+		for j, accountIdentifier := range accountIdentifiers {
+			var accountCoins []*types.Coin
+			if historical {
+				accountCoins, err = i.getHistoricalCoins(ctx, dbTx, accountIdentifier, block)
+			} else {
+				accountCoins, _, err = i.coinStorage.GetCoinsTransactional(ctx, dbTx, accountIdentifier)
+			}
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			coins[j] = accountCoins
+		}
+	}
+
+	return balances, coins, block, nil
+}
+
+// GetMempoolBalance returns the net change to the balance of
+// an account once all transactions in the mempool are confirmed.
+func (i *Indexer) GetMempoolBalance(
+	ctx context.Context,
+	accountIdentifier *types.AccountIdentifier,
+	currency *types.Currency,
+) (*types.Amount, error) {
+	change, err := i.mempool.BalanceChange(accountIdentifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to calculate mempool balance", err)
+	}
+
+	return &types.Amount{
+		Value:    change.String(),
+		Currency: currency,
+	}, nil
 }
 
 // GetBalance returns the balance of an account
@@ -20,15 +20,19 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/thoughtnetwork/rosetta-thought/thought"
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/metrics"
 	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/indexer"
+	"github.com/thoughtnetwork/rosetta-thought/services"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -833,3 +837,1410 @@ func TestIndexer_HeaderReorg(t *testing.T) {
 	assert.Len(t, i.waiter.table, 0)
 	mockClient.AssertExpectations(t)
 }
+
+func TestIndexer_GetMempoolTransaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	parentHash := fmt.Sprintf("%x", sha256.Sum256([]byte("parent")))
+	childHash := fmt.Sprintf("%x", sha256.Sum256([]byte("child")))
+	parent := &thought.Transaction{Hash: parentHash}
+	child := &thought.Transaction{
+		Hash: childHash,
+		Inputs: []*thought.Input{
+			{
+				TxHash: parentHash,
+				Vout:   1,
+			},
+		},
+	}
+	parentCoin := &types.AccountCoin{
+		Account: &types.AccountIdentifier{Address: "addr1"},
+		Coin: &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: thought.CoinIdentifier(parentHash, 1),
+			},
+			Amount: &types.Amount{
+				Value:    "100",
+				Currency: thought.MainnetCurrency,
+			},
+		},
+	}
+	transaction := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: childHash},
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index:        0,
+					NetworkIndex: &index0,
+				},
+				Status:  types.String(thought.SuccessStatus),
+				Type:    thought.InputOpType,
+				Account: parentCoin.Account,
+				Amount: &types.Amount{
+					Value:    "-100",
+					Currency: thought.MainnetCurrency,
+				},
+				CoinChange: &types.CoinChange{
+					CoinAction:     types.CoinSpent,
+					CoinIdentifier: parentCoin.Coin.CoinIdentifier,
+				},
+			},
+		},
+	}
+
+	// The input is not in coin storage, so the parent must
+	// be fetched from the mempool.
+	mockClient.On("GetMempoolEntry", ctx, childHash).Return(&thought.MempoolEntry{
+		Depends: []string{parentHash},
+	}, nil).Once()
+	mockClient.On("GetRawTransaction", ctx, childHash).Return(child, nil).Once()
+	mockClient.On("GetRawTransaction", ctx, parentHash).Return(parent, nil).Once()
+	mockClient.On("ParseCoins", parent).Return(map[string]*types.AccountCoin{
+		parentCoin.Coin.CoinIdentifier.Identifier: parentCoin,
+	}, nil).Once()
+	mockClient.On("ParseTransaction", ctx, child, map[string]*types.AccountCoin{
+		parentCoin.Coin.CoinIdentifier.Identifier: parentCoin,
+	}).Return(transaction, nil).Once()
+
+	tx, err := i.GetMempoolTransaction(ctx, &types.TransactionIdentifier{Hash: childHash})
+	assert.NoError(t, err)
+	assert.Equal(t, transaction, tx)
+
+	// Transactions that are not in the mempool are not returned.
+	mockClient.On("GetMempoolEntry", ctx, parentHash).Return(
+		nil,
+		thought.ErrTransactionNotFound,
+	).Once()
+	tx, err = i.GetMempoolTransaction(ctx, &types.TransactionIdentifier{Hash: parentHash})
+	assert.Nil(t, tx)
+	assert.True(t, errors.Is(err, thought.ErrTransactionNotFound))
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_Mempool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	account1 := &types.AccountIdentifier{Address: "addr1"}
+	account2 := &types.AccountIdentifier{Address: "addr2"}
+	newCoin := func(hash string, index int64, value string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: thought.CoinIdentifier(hash, index),
+			},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: thought.MainnetCurrency,
+			},
+		}
+	}
+
+	// Index a block that creates a coin for account1.
+	confirmedHash := fmt.Sprintf("%x", sha256.Sum256([]byte("confirmed")))
+	confirmedCoin := newCoin(confirmedHash, 0, "100")
+	err = i.BlockAdded(ctx, &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(0),
+			Index: 0,
+		},
+		ParentBlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(0),
+			Index: 0,
+		},
+		Timestamp: 1599002115110,
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: confirmedHash},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index:        0,
+							NetworkIndex: &index0,
+						},
+						Status:  types.String(thought.SuccessStatus),
+						Type:    thought.OutputOpType,
+						Account: account1,
+						Amount:  confirmedCoin.Amount,
+						CoinChange: &types.CoinChange{
+							CoinAction:     types.CoinCreated,
+							CoinIdentifier: confirmedCoin.CoinIdentifier,
+						},
+					},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// Track a mempool transaction that spends the coin.
+	mempoolHash := fmt.Sprintf("%x", sha256.Sum256([]byte("mempool")))
+	change := newCoin(mempoolHash, 1, "39")
+	payment := newCoin(mempoolHash, 0, "60")
+	paymentScript := &thought.ScriptPubKey{Hex: "payment"}
+	rawTransaction := &thought.Transaction{
+		Hash: mempoolHash,
+		Inputs: []*thought.Input{
+			{
+				TxHash: confirmedHash,
+				Vout:   0,
+			},
+		},
+		Outputs: []*thought.Output{
+			{Index: 0, ScriptPubKey: paymentScript},
+			{Index: 1, ScriptPubKey: &thought.ScriptPubKey{Hex: "change"}},
+		},
+	}
+	mockClient.On("RawMempool", ctx).Return([]string{mempoolHash}, nil).Once()
+	mockClient.On("GetRawTransaction", ctx, mempoolHash).Return(rawTransaction, nil).Once()
+	mockClient.On("ParseCoins", rawTransaction).Return(map[string]*types.AccountCoin{
+		payment.CoinIdentifier.Identifier: {Account: account2, Coin: payment},
+		change.CoinIdentifier.Identifier:  {Account: account1, Coin: change},
+	}, nil).Once()
+	assert.NoError(t, i.updateMempool(ctx))
+
+	coins, _, err := i.GetCoins(ctx, account1, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Coin{confirmedCoin}, coins)
+
+	coins, _, err = i.GetCoins(ctx, account1, true, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Coin{change}, coins)
+
+	coins, _, err = i.GetCoins(ctx, account2, true, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Coin{payment}, coins)
+
+	amount, err := i.GetMempoolBalance(ctx, account1, thought.MainnetCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, "-61", amount.Value)
+
+	amount, err = i.GetMempoolBalance(ctx, account2, thought.MainnetCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, "60", amount.Value)
+
+	// Unconfirmed coins can be spent in construction.
+	scripts, err := i.GetScriptPubKeys(ctx, []*types.Coin{
+		{
+			CoinIdentifier: payment.CoinIdentifier,
+			Amount: &types.Amount{
+				Value:    "-60",
+				Currency: thought.MainnetCurrency,
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*thought.ScriptPubKey{paymentScript}, scripts)
+
+	// Once the transaction leaves the mempool, it is
+	// no longer tracked.
+	mockClient.On("RawMempool", ctx).Return([]string{}, nil).Once()
+	assert.NoError(t, i.updateMempool(ctx))
+
+	coins, _, err = i.GetCoins(ctx, account1, true, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Coin{confirmedCoin}, coins)
+
+	amount, err = i.GetMempoolBalance(ctx, account1, thought.MainnetCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, "0", amount.Value)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_SearchTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	account1 := &types.AccountIdentifier{Address: "addr1"}
+	account2 := &types.AccountIdentifier{Address: "addr2"}
+	hashes := make([]string, 3)
+	for j := range hashes {
+		hashes[j] = fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("tx%d", j))))
+	}
+	coin := func(hash string, index int64) *types.CoinIdentifier {
+		return &types.CoinIdentifier{Identifier: thought.CoinIdentifier(hash, index)}
+	}
+	op := func(
+		index int64,
+		opType string,
+		status string,
+		account *types.AccountIdentifier,
+		value string,
+		coinAction types.CoinAction,
+		coinIdentifier *types.CoinIdentifier,
+	) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        index,
+				NetworkIndex: &index,
+			},
+			Status:  types.String(status),
+			Type:    opType,
+			Account: account,
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: thought.MainnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinAction:     coinAction,
+				CoinIdentifier: coinIdentifier,
+			},
+		}
+	}
+
+	// tx0 creates a coin for account1, tx1 spends it to account2
+	// (with change to account1) and tx2 is skipped.
+	transactions := []*types.Transaction{
+		{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hashes[0]},
+			Operations: []*types.Operation{
+				op(0, thought.OutputOpType, thought.SuccessStatus, account1, "100", types.CoinCreated, coin(hashes[0], 0)),
+			},
+		},
+		{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hashes[1]},
+			Operations: []*types.Operation{
+				op(0, thought.InputOpType, thought.SuccessStatus, account1, "-100", types.CoinSpent, coin(hashes[0], 0)),
+				op(1, thought.OutputOpType, thought.SuccessStatus, account2, "60", types.CoinCreated, coin(hashes[1], 0)),
+				op(2, thought.OutputOpType, thought.SuccessStatus, account1, "40", types.CoinCreated, coin(hashes[1], 1)),
+			},
+		},
+		{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hashes[2]},
+			Operations: []*types.Operation{
+				op(0, thought.OutputOpType, thought.SkippedStatus, account2, "50", types.CoinCreated, coin(hashes[2], 0)),
+			},
+		},
+	}
+	blocks := make([]*types.BlockIdentifier, len(transactions))
+	for j, transaction := range transactions {
+		index := int64(j)
+		blocks[j] = &types.BlockIdentifier{Hash: getBlockHash(index), Index: index}
+		parent := blocks[j]
+		if j > 0 {
+			parent = blocks[j-1]
+		}
+
+		block := &types.Block{
+			BlockIdentifier:       blocks[j],
+			ParentBlockIdentifier: parent,
+			Timestamp:             1599002115110 + index,
+			Transactions:          []*types.Transaction{transaction},
+		}
+		assert.NoError(t, i.BlockSeen(ctx, block))
+		assert.NoError(t, i.BlockAdded(ctx, block))
+	}
+
+	result := func(j int) *types.BlockTransaction {
+		return &types.BlockTransaction{
+			BlockIdentifier: blocks[j],
+			Transaction:     transactions[j],
+		}
+	}
+	nextOffset := int64(1)
+	tests := map[string]struct {
+		request  *types.SearchTransactionsRequest
+		limit    int64
+		expected *types.SearchTransactionsResponse
+	}{
+		"address": {
+			request: &types.SearchTransactionsRequest{Address: types.String("addr1")},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(1), result(0)},
+				TotalCount:   2,
+			},
+		},
+		"account with limit": {
+			request: &types.SearchTransactionsRequest{AccountIdentifier: account2},
+			limit:   1,
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(2)},
+				TotalCount:   2,
+				NextOffset:   &nextOffset,
+			},
+		},
+		"account with offset": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: account2,
+				Offset:            &nextOffset,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(1)},
+				TotalCount:   2,
+			},
+		},
+		"successful": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: account2,
+				Success:           types.Bool(true),
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(1)},
+				TotalCount:   1,
+			},
+		},
+		"max block": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: account2,
+				MaxBlock:          types.Int64(1),
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(1)},
+				TotalCount:   1,
+			},
+		},
+		"coin": {
+			request: &types.SearchTransactionsRequest{CoinIdentifier: coin(hashes[0], 0)},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(1), result(0)},
+				TotalCount:   2,
+			},
+		},
+		"and": {
+			request: &types.SearchTransactionsRequest{
+				Operator:       types.OperatorP(types.AND),
+				Address:        types.String("addr1"),
+				CoinIdentifier: coin(hashes[1], 0),
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(1)},
+				TotalCount:   1,
+			},
+		},
+		"or": {
+			request: &types.SearchTransactionsRequest{
+				Operator:              types.OperatorP(types.OR),
+				TransactionIdentifier: transactions[2].TransactionIdentifier,
+				CoinIdentifier:        coin(hashes[0], 0),
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(2), result(1), result(0)},
+				TotalCount:   3,
+			},
+		},
+		"type": {
+			request: &types.SearchTransactionsRequest{
+				Address: types.String("addr1"),
+				Type:    types.String(thought.InputOpType),
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{result(1)},
+				TotalCount:   1,
+			},
+		},
+		"no results": {
+			request: &types.SearchTransactionsRequest{Address: types.String("addr3")},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{},
+				TotalCount:   0,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			offset := int64(0)
+			if test.request.Offset != nil {
+				offset = *test.request.Offset
+			}
+
+			limit := test.limit
+			if limit == 0 {
+				limit = 10
+			}
+
+			response, err := i.SearchTransactions(ctx, test.request, offset, limit)
+			assert.NoError(t, err)
+			assert.Equal(t, types.PrettyPrintStruct(test.expected), types.PrettyPrintStruct(response))
+		})
+	}
+
+	// At least one indexed filter is required.
+	_, err = i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		Type: types.String(thought.InputOpType),
+	}, 0, 10)
+	assert.Error(t, err)
+
+	// Transactions can be found by hash alone.
+	blockIdentifier, transaction, err := i.FindTransaction(ctx, transactions[1].TransactionIdentifier)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[1], blockIdentifier)
+	assert.Equal(t, types.PrettyPrintStruct(transactions[1]), types.PrettyPrintStruct(transaction))
+
+	// Removed blocks are no longer returned.
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2]))
+	response, err := i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		AccountIdentifier: account2,
+	}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.BlockTransaction{result(1)}, response.Transactions)
+
+	_, _, err = i.FindTransaction(ctx, transactions[2].TransactionIdentifier)
+	assert.True(t, errors.Is(err, thought.ErrTransactionNotFound))
+
+	// Searches fail if the index does not start at genesis
+	// (blocks were indexed before the index was introduced).
+	dbTx := i.database.Transaction(ctx)
+	assert.NoError(t, dbTx.Set(ctx, []byte(searchOldestKey), []byte("1"), true))
+	assert.NoError(t, dbTx.Commit(ctx))
+	_, err = i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		AccountIdentifier: account2,
+	}, 0, 10)
+	assert.True(t, errors.Is(err, thought.ErrSearchIndexIncomplete))
+
+	// Transactions are not reported as missing
+	// if the index does not start at genesis.
+	_, _, err = i.FindTransaction(ctx, transactions[2].TransactionIdentifier)
+	assert.True(t, errors.Is(err, thought.ErrSearchIndexIncomplete))
+	assert.False(t, errors.Is(err, thought.ErrTransactionNotFound))
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_SearchTransactionsPages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	// Nothing can be searched before genesis is indexed
+	_, err = i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		Address: types.String("addr1"),
+	}, 0, 10)
+	assert.True(t, errors.Is(err, thought.ErrSearchIndexIncomplete))
+
+	// Every transaction pays addr1 and either addr2 (even)
+	// or addr3 (odd), so the indexes span several pages.
+	count := 2*searchPageSize + 5
+	hashes := make([]string, count)
+	transactions := make([]*types.Transaction, count)
+	for j := range transactions {
+		hashes[j] = fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("tx%d", j))))
+		other := "addr3"
+		if j%2 == 0 {
+			other = "addr2"
+		}
+
+		operations := []*types.Operation{}
+		for k, address := range []string{"addr1", other} {
+			operations = append(operations, &types.Operation{
+				OperationIdentifier: &types.OperationIdentifier{Index: int64(k)},
+				Status:              types.String(thought.SuccessStatus),
+				Type:                thought.OutputOpType,
+				Account:             &types.AccountIdentifier{Address: address},
+				Amount: &types.Amount{
+					Value:    "1",
+					Currency: thought.MainnetCurrency,
+				},
+			})
+		}
+
+		transactions[j] = &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hashes[j]},
+			Operations:            operations,
+		}
+	}
+
+	block := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0},
+		ParentBlockIdentifier: &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0},
+		Timestamp:             1599002115110,
+		Transactions:          transactions,
+	}
+	assert.NoError(t, i.BlockSeen(ctx, block))
+	assert.NoError(t, i.BlockAdded(ctx, block))
+
+	// Transactions in the same block are sorted by hash
+	sorted := func(filter func(j int) bool) []string {
+		matches := []string{}
+		for j, hash := range hashes {
+			if filter(j) {
+				matches = append(matches, hash)
+			}
+		}
+		sort.Strings(matches)
+		return matches
+	}
+	all := sorted(func(j int) bool { return true })
+	even := sorted(func(j int) bool { return j%2 == 0 })
+
+	tests := map[string]struct {
+		request  *types.SearchTransactionsRequest
+		offset   int64
+		expected []string
+		total    int64
+	}{
+		"last page": {
+			request:  &types.SearchTransactionsRequest{Address: types.String("addr1")},
+			offset:   int64(count - 3),
+			expected: all[count-3:],
+			total:    int64(count),
+		},
+		"across pages": {
+			request:  &types.SearchTransactionsRequest{Address: types.String("addr1")},
+			offset:   searchPageSize - 5,
+			expected: all[searchPageSize-5 : searchPageSize+5],
+			total:    int64(count),
+		},
+		"and": {
+			request: &types.SearchTransactionsRequest{
+				Address:           types.String("addr1"),
+				AccountIdentifier: &types.AccountIdentifier{Address: "addr2"},
+			},
+			offset:   searchPageSize / 2,
+			expected: even[searchPageSize/2 : searchPageSize/2+10],
+			total:    int64(len(even)),
+		},
+		"or": {
+			request: &types.SearchTransactionsRequest{
+				Operator:          types.OperatorP(types.OR),
+				Address:           types.String("addr3"),
+				AccountIdentifier: &types.AccountIdentifier{Address: "addr2"},
+			},
+			offset:   searchPageSize + 10,
+			expected: all[searchPageSize+10 : searchPageSize+20],
+			total:    int64(count),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, err := i.SearchTransactions(ctx, test.request, test.offset, 10)
+			assert.NoError(t, err)
+			assert.Equal(t, test.total, response.TotalCount)
+
+			results := make([]string, len(response.Transactions))
+			for j, result := range response.Transactions {
+				results[j] = result.Transaction.TransactionIdentifier.Hash
+			}
+			assert.Equal(t, test.expected, results)
+		})
+	}
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_GetBlockEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	// No events before any block is indexed
+	events, maxSequence, err := i.GetBlockEvents(ctx, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.BlockEvent{}, events)
+	assert.Equal(t, int64(-1), maxSequence)
+
+	blocks := make([]*types.BlockIdentifier, 3)
+	for j := range blocks {
+		index := int64(j)
+		blocks[j] = &types.BlockIdentifier{Hash: getBlockHash(index), Index: index}
+		parent := blocks[j]
+		if j > 0 {
+			parent = blocks[j-1]
+		}
+
+		block := &types.Block{
+			BlockIdentifier:       blocks[j],
+			ParentBlockIdentifier: parent,
+			Timestamp:             1599002115110 + index,
+		}
+		assert.NoError(t, i.BlockSeen(ctx, block))
+		assert.NoError(t, i.BlockAdded(ctx, block))
+	}
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2]))
+
+	expected := []*types.BlockEvent{
+		{Sequence: 0, BlockIdentifier: blocks[0], Type: types.ADDED},
+		{Sequence: 1, BlockIdentifier: blocks[1], Type: types.ADDED},
+		{Sequence: 2, BlockIdentifier: blocks[2], Type: types.ADDED},
+		{Sequence: 3, BlockIdentifier: blocks[2], Type: types.REMOVED},
+	}
+
+	// From the beginning
+	offset := int64(0)
+	events, maxSequence, err = i.GetBlockEvents(ctx, &offset, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, events)
+	assert.Equal(t, int64(3), maxSequence)
+
+	// From an offset with a limit
+	offset = 1
+	events, maxSequence, err = i.GetBlockEvents(ctx, &offset, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, expected[1:3], events)
+	assert.Equal(t, int64(3), maxSequence)
+
+	// Backwards from the tip
+	events, maxSequence, err = i.GetBlockEvents(ctx, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, expected[2:], events)
+	assert.Equal(t, int64(3), maxSequence)
+
+	// Beyond the tip
+	offset = 4
+	events, maxSequence, err = i.GetBlockEvents(ctx, &offset, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.BlockEvent{}, events)
+	assert.Equal(t, int64(3), maxSequence)
+
+	mockClient.AssertExpectations(t)
+}
+
+// addCoinBlocks indexes 3 blocks in which tx0 creates coin0 for
+// addr1, tx1 spends it to create coin1 for addr1 and coin2 for
+// addr2, and tx2 creates coin3 for addr1.
+func addCoinBlocks(
+	ctx context.Context,
+	t *testing.T,
+	i *Indexer,
+) ([]*types.BlockIdentifier, []*types.Coin) {
+	account1 := &types.AccountIdentifier{Address: "addr1"}
+	account2 := &types.AccountIdentifier{Address: "addr2"}
+	hashes := make([]string, 3)
+	for j := range hashes {
+		hashes[j] = fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("tx%d", j))))
+	}
+	coin := func(hash string, index int64, value string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: thought.CoinIdentifier(hash, index)},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: thought.MainnetCurrency,
+			},
+		}
+	}
+	op := func(
+		index int64,
+		opType string,
+		account *types.AccountIdentifier,
+		coinAction types.CoinAction,
+		c *types.Coin,
+	) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        index,
+				NetworkIndex: &index,
+			},
+			Status:  types.String(thought.SuccessStatus),
+			Type:    opType,
+			Account: account,
+			Amount:  c.Amount,
+			CoinChange: &types.CoinChange{
+				CoinAction:     coinAction,
+				CoinIdentifier: c.CoinIdentifier,
+			},
+		}
+	}
+
+	coin0 := coin(hashes[0], 0, "100")
+	coin1 := coin(hashes[1], 0, "40")
+	coin2 := coin(hashes[1], 1, "60")
+	coin3 := coin(hashes[2], 0, "10")
+	transactions := []*types.Transaction{
+		{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hashes[0]},
+			Operations: []*types.Operation{
+				op(0, thought.OutputOpType, account1, types.CoinCreated, coin0),
+			},
+		},
+		{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hashes[1]},
+			Operations: []*types.Operation{
+				op(0, thought.InputOpType, account1, types.CoinSpent, &types.Coin{
+					CoinIdentifier: coin0.CoinIdentifier,
+					Amount: &types.Amount{
+						Value:    "-100",
+						Currency: thought.MainnetCurrency,
+					},
+				}),
+				op(1, thought.OutputOpType, account1, types.CoinCreated, coin1),
+				op(2, thought.OutputOpType, account2, types.CoinCreated, coin2),
+			},
+		},
+		{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hashes[2]},
+			Operations: []*types.Operation{
+				op(0, thought.OutputOpType, account1, types.CoinCreated, coin3),
+			},
+		},
+	}
+	blocks := make([]*types.BlockIdentifier, len(transactions))
+	for j, transaction := range transactions {
+		index := int64(j)
+		blocks[j] = &types.BlockIdentifier{Hash: getBlockHash(index), Index: index}
+		parent := blocks[j]
+		if j > 0 {
+			parent = blocks[j-1]
+		}
+
+		block := &types.Block{
+			BlockIdentifier:       blocks[j],
+			ParentBlockIdentifier: parent,
+			Timestamp:             1599002115110 + index,
+			Transactions:          []*types.Transaction{transaction},
+		}
+		assert.NoError(t, i.BlockSeen(ctx, block))
+		assert.NoError(t, i.BlockAdded(ctx, block))
+	}
+
+	return blocks, []*types.Coin{coin0, coin1, coin2, coin3}
+}
+
+func TestIndexer_GetHistoricalCoins(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	account1 := &types.AccountIdentifier{Address: "addr1"}
+	account2 := &types.AccountIdentifier{Address: "addr2"}
+	blocks, coins := addCoinBlocks(ctx, t, i)
+	coin0, coin1, coin2, coin3 := coins[0], coins[1], coins[2], coins[3]
+
+	tests := map[string]struct {
+		account  *types.AccountIdentifier
+		block    *types.PartialBlockIdentifier
+		expected []*types.Coin
+		head     *types.BlockIdentifier
+	}{
+		"account1 at block 0": {
+			account:  account1,
+			block:    &types.PartialBlockIdentifier{Index: types.Int64(0)},
+			expected: []*types.Coin{coin0},
+			head:     blocks[0],
+		},
+		"account1 at block 1": {
+			account:  account1,
+			block:    &types.PartialBlockIdentifier{Hash: types.String(blocks[1].Hash)},
+			expected: []*types.Coin{coin1},
+			head:     blocks[1],
+		},
+		"account1 at block 2": {
+			account:  account1,
+			block:    &types.PartialBlockIdentifier{Index: types.Int64(2)},
+			expected: []*types.Coin{coin3, coin1}, // sorted by identifier
+			head:     blocks[2],
+		},
+		"account2 at block 0": {
+			account:  account2,
+			block:    &types.PartialBlockIdentifier{Index: types.Int64(0)},
+			expected: []*types.Coin{},
+			head:     blocks[0],
+		},
+		"account2 at block 1": {
+			account:  account2,
+			block:    &types.PartialBlockIdentifier{Index: types.Int64(1)},
+			expected: []*types.Coin{coin2},
+			head:     blocks[1],
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			coins, head, err := i.GetCoins(ctx, test.account, false, test.block)
+			assert.NoError(t, err)
+			assert.Equal(t, types.PrettyPrintStruct(test.expected), types.PrettyPrintStruct(coins))
+			assert.Equal(t, test.head, head)
+		})
+	}
+
+	// Mempool coins cannot be included at a past block
+	_, _, err = i.GetCoins(ctx, account1, true, &types.PartialBlockIdentifier{Index: types.Int64(1)})
+	assert.Error(t, err)
+
+	// Unknown blocks
+	_, _, err = i.GetCoins(ctx, account1, false, &types.PartialBlockIdentifier{Index: types.Int64(10)})
+	assert.Error(t, err)
+
+	// Only blocks after the requested block must be in the
+	// search index (blocks were indexed before the index was
+	// introduced).
+	dbTx := i.database.Transaction(ctx)
+	assert.NoError(t, dbTx.Set(ctx, []byte(searchOldestKey), []byte("2"), true))
+	assert.NoError(t, dbTx.Commit(ctx))
+	coins, _, err = i.GetCoins(ctx, account1, false, &types.PartialBlockIdentifier{Index: types.Int64(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Coin{coin1}, coins)
+
+	_, _, err = i.GetCoins(ctx, account1, false, &types.PartialBlockIdentifier{Index: types.Int64(0)})
+	assert.True(t, errors.Is(err, thought.ErrSearchIndexIncomplete))
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_GetAccounts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	accounts := []*types.AccountIdentifier{
+		{Address: "addr1"},
+		{Address: "addr2"},
+		{Address: "addr3"},
+	}
+	blocks, coins := addCoinBlocks(ctx, t, i)
+	amount := func(value string) *types.Amount {
+		return &types.Amount{
+			Value:    value,
+			Currency: thought.MainnetCurrency,
+		}
+	}
+
+	// Balances and coins at the current block
+	balances, accountCoins, block, err := i.GetAccounts(
+		ctx,
+		accounts,
+		thought.MainnetCurrency,
+		true,
+		true,
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[2], block)
+	assert.Equal(t, []*types.Amount{amount("50"), amount("60"), amount("0")}, balances)
+	assert.Len(t, accountCoins, 3)
+	assert.ElementsMatch(t, []*types.Coin{coins[1], coins[3]}, accountCoins[0])
+	assert.Equal(t, []*types.Coin{coins[2]}, accountCoins[1])
+	assert.Equal(t, []*types.Coin{}, accountCoins[2])
+
+	// Balances at a past block
+	balances, accountCoins, block, err = i.GetAccounts(
+		ctx,
+		accounts,
+		thought.MainnetCurrency,
+		true,
+		false,
+		&types.PartialBlockIdentifier{Index: types.Int64(0)},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[0], block)
+	assert.Equal(t, []*types.Amount{amount("100"), amount("0"), amount("0")}, balances)
+	assert.Nil(t, accountCoins)
+
+	// Coins at a past block are reconstructed
+	balances, accountCoins, block, err = i.GetAccounts(
+		ctx,
+		accounts,
+		thought.MainnetCurrency,
+		true,
+		true,
+		&types.PartialBlockIdentifier{Index: types.Int64(1)},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[1], block)
+	assert.Equal(t, []*types.Amount{amount("40"), amount("60"), amount("0")}, balances)
+	assert.Len(t, accountCoins, 3)
+	assert.Equal(t, []*types.Coin{coins[1]}, accountCoins[0])
+	assert.Equal(t, []*types.Coin{coins[2]}, accountCoins[1])
+	assert.Equal(t, []*types.Coin{}, accountCoins[2])
+
+	// A block added between two account reads in the same
+	// database transaction is not visible to the second read.
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	balance, err := i.balanceStorage.GetBalanceTransactional(
+		ctx,
+		dbTx,
+		accounts[1],
+		thought.MainnetCurrency,
+		blocks[2].Index,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, amount("60"), balance)
+
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte("tx3")))
+	coin4 := &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: thought.CoinIdentifier(hash, 0)},
+		Amount:         amount("55"),
+	}
+	transaction := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 0},
+				Status:              types.String(thought.SuccessStatus),
+				Type:                thought.InputOpType,
+				Account:             accounts[1],
+				Amount:              amount("-60"),
+				CoinChange: &types.CoinChange{
+					CoinAction:     types.CoinSpent,
+					CoinIdentifier: coins[2].CoinIdentifier,
+				},
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 1},
+				Status:              types.String(thought.SuccessStatus),
+				Type:                thought.OutputOpType,
+				Account:             accounts[1],
+				Amount:              coin4.Amount,
+				CoinChange: &types.CoinChange{
+					CoinAction:     types.CoinCreated,
+					CoinIdentifier: coin4.CoinIdentifier,
+				},
+			},
+		},
+	}
+	block3 := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(3), Index: 3},
+		ParentBlockIdentifier: blocks[2],
+		Timestamp:             1599002115113,
+		Transactions:          []*types.Transaction{transaction},
+	}
+	assert.NoError(t, i.BlockSeen(ctx, block3))
+	assert.NoError(t, i.BlockAdded(ctx, block3))
+
+	historicalCoins, err := i.getHistoricalCoins(ctx, dbTx, accounts[1], blocks[1])
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Coin{coins[2]}, historicalCoins)
+
+	headCoins, head, err := i.coinStorage.GetCoinsTransactional(ctx, dbTx, accounts[1])
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[2], head)
+	assert.Equal(t, []*types.Coin{coins[2]}, headCoins)
+
+	_, err = i.getBlockTransaction(ctx, dbTx, block3.BlockIdentifier, transaction.TransactionIdentifier)
+	assert.Error(t, err)
+
+	// The block is visible to later reads
+	balances, accountCoins, block, err = i.GetAccounts(
+		ctx,
+		accounts[1:2],
+		thought.MainnetCurrency,
+		true,
+		true,
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, block3.BlockIdentifier, block)
+	assert.Equal(t, []*types.Amount{amount("55")}, balances)
+	assert.Equal(t, [][]*types.Coin{{coin4}}, accountCoins)
+
+	// Coins at a past block undo the block
+	_, accountCoins, _, err = i.GetAccounts(
+		ctx,
+		accounts[1:2],
+		thought.MainnetCurrency,
+		false,
+		true,
+		&types.PartialBlockIdentifier{Index: types.Int64(2)},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]*types.Coin{{coins[2]}}, accountCoins)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_Subscriptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	subscription := i.Subscriptions().Subscribe()
+	defer i.Subscriptions().Unsubscribe(subscription)
+	assert.NoError(t, i.Subscriptions().Update(subscription, &services.SubscriptionRequest{
+		Method: services.SubscribeMethod,
+		Topics: []string{
+			services.BlockAddedTopic,
+			services.BlockRemovedTopic,
+			services.AddressTopic,
+		},
+		Addresses: []string{"addr2"},
+	}))
+
+	blocks, _ := addCoinBlocks(ctx, t, i)
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2]))
+
+	tx1 := &types.TransactionIdentifier{
+		Hash: fmt.Sprintf("%x", sha256.Sum256([]byte("tx1"))),
+	}
+	expected := []*services.StreamEvent{
+		{Topic: services.BlockAddedTopic, BlockIdentifier: blocks[0]},
+		{Topic: services.BlockAddedTopic, BlockIdentifier: blocks[1]},
+		{
+			Topic:                 services.AddressTopic,
+			BlockIdentifier:       blocks[1],
+			TransactionIdentifier: tx1,
+			Address:               "addr2",
+		},
+		{Topic: services.BlockAddedTopic, BlockIdentifier: blocks[2]},
+		{Topic: services.BlockRemovedTopic, BlockIdentifier: blocks[2]},
+	}
+	assert.Len(t, subscription.Events(), len(expected))
+	for _, event := range expected {
+		assert.Equal(t, event, <-subscription.Events())
+	}
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_GetAccountHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	account1 := &types.AccountIdentifier{Address: "addr1"}
+	account2 := &types.AccountIdentifier{Address: "addr2"}
+
+	// No history before any block is indexed
+	_, _, err = i.GetAccountHistory(ctx, account1, "", nil, nil, 10)
+	assert.Error(t, err)
+
+	blocks, _ := addCoinBlocks(ctx, t, i)
+	history := func(j int, value string) *thought.HistoryTransaction {
+		return &thought.HistoryTransaction{
+			BlockIdentifier: blocks[j],
+			TransactionIdentifier: &types.TransactionIdentifier{
+				Hash: fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("tx%d", j)))),
+			},
+			NetAmount: &types.Amount{Value: value, Currency: thought.MainnetCurrency},
+		}
+	}
+
+	// First page, from the most recent block
+	transactions, cursor, err := i.GetAccountHistory(ctx, account1, "", nil, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*thought.HistoryTransaction{history(2, "10"), history(1, "-60")}, transactions)
+	assert.NotEmpty(t, cursor)
+
+	// Last page
+	transactions, cursor, err = i.GetAccountHistory(ctx, account1, cursor, nil, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*thought.HistoryTransaction{history(0, "100")}, transactions)
+	assert.Empty(t, cursor)
+
+	// Height range
+	minIndex := int64(1)
+	maxIndex := int64(1)
+	transactions, cursor, err = i.GetAccountHistory(
+		ctx,
+		account1,
+		"",
+		&minIndex,
+		&maxIndex,
+		10,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []*thought.HistoryTransaction{history(1, "-60")}, transactions)
+	assert.Empty(t, cursor)
+
+	// Other account
+	transactions, _, err = i.GetAccountHistory(ctx, account2, "", nil, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*thought.HistoryTransaction{history(1, "60")}, transactions)
+
+	// Unknown account
+	transactions, cursor, err = i.GetAccountHistory(
+		ctx,
+		&types.AccountIdentifier{Address: "addr3"},
+		"",
+		nil,
+		nil,
+		10,
+	)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+	assert.Empty(t, cursor)
+
+	// Invalid cursor
+	_, _, err = i.GetAccountHistory(ctx, account1, "invalid", nil, nil, 10)
+	assert.Error(t, err)
+
+	// Removed blocks are rolled back
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2]))
+	transactions, _, err = i.GetAccountHistory(ctx, account1, "", nil, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*thought.HistoryTransaction{history(1, "-60"), history(0, "100")}, transactions)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_GetStatistics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	// No statistics before any block is indexed
+	_, err = i.GetStatistics(ctx, thought.MainnetCurrency, 10)
+	assert.Error(t, err)
+
+	amount := func(value string) *types.Amount {
+		return &types.Amount{Value: value, Currency: thought.MainnetCurrency}
+	}
+	account1 := &types.AccountIdentifier{Address: "addr1"}
+	account2 := &types.AccountIdentifier{Address: "addr2"}
+
+	blocks, _ := addCoinBlocks(ctx, t, i)
+	stats, err := i.GetStatistics(ctx, thought.MainnetCurrency, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, &thought.Statistics{
+		BlockIdentifier: blocks[2],
+		Supply:          amount("110"),
+		CoinCount:       3,
+		AddressCount:    2,
+		RichList: []*thought.AccountBalance{
+			{AccountIdentifier: account2, Balance: amount("60")},
+			{AccountIdentifier: account1, Balance: amount("50")},
+		},
+	}, stats)
+
+	// Rich list limit
+	stats, err = i.GetStatistics(ctx, thought.MainnetCurrency, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*thought.AccountBalance{
+		{AccountIdentifier: account2, Balance: amount("60")},
+	}, stats.RichList)
+
+	// Removed blocks are reverted
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2]))
+	stats, err = i.GetStatistics(ctx, thought.MainnetCurrency, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, &thought.Statistics{
+		BlockIdentifier: blocks[1],
+		Supply:          amount("100"),
+		CoinCount:       2,
+		AddressCount:    2,
+		RichList: []*thought.AccountBalance{
+			{AccountIdentifier: account2, Balance: amount("60")},
+			{AccountIdentifier: account1, Balance: amount("40")},
+		},
+	}, stats)
+
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[1]))
+	stats, err = i.GetStatistics(ctx, thought.MainnetCurrency, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, &thought.Statistics{
+		BlockIdentifier: blocks[0],
+		Supply:          amount("100"),
+		CoinCount:       1,
+		AddressCount:    1,
+		RichList: []*thought.AccountBalance{
+			{AccountIdentifier: account1, Balance: amount("100")},
+		},
+	}, stats)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestIndexer_Metrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	// Metrics are global, so only their
+	// changes are checked.
+	added := testutil.ToFloat64(metrics.BlocksAdded)
+	removed := testutil.ToFloat64(metrics.BlocksRemoved)
+	reorgs := testutil.ToFloat64(metrics.Reorgs)
+
+	blocks, _ := addCoinBlocks(ctx, t, i)
+	assert.Equal(t, added+3, testutil.ToFloat64(metrics.BlocksAdded))
+	assert.Equal(t, float64(blocks[2].Index), testutil.ToFloat64(metrics.IndexerHeight))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.CoinCacheSize))
+
+	// Consecutive removals are counted as one reorg
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2]))
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[1]))
+	assert.Equal(t, removed+2, testutil.ToFloat64(metrics.BlocksRemoved))
+	assert.Equal(t, reorgs+1, testutil.ToFloat64(metrics.Reorgs))
+	assert.Equal(t, float64(blocks[0].Index), testutil.ToFloat64(metrics.IndexerHeight))
+
+	mockClient.On("NetworkStatus", ctx).Return(&types.NetworkStatusResponse{
+		CurrentBlockIdentifier: &types.BlockIdentifier{Index: 100, Hash: getBlockHash(100)},
+	}, nil).Once()
+	_, err = i.NetworkStatus(ctx, cfg.Network)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(100), testutil.ToFloat64(metrics.NodeHeight))
+
+	mockClient.AssertExpectations(t)
+}
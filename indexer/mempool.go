@@ -0,0 +1,209 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// mempoolEntry is the coins created and spent
+// by a transaction in the mempool.
+type mempoolEntry struct {
+	transaction *thought.Transaction
+	created     map[string]*types.AccountCoin
+	spent       map[string]*types.AccountCoin
+}
+
+// mempool tracks the coins created and spent by
+// transactions that have not yet been included in
+// a block.
+type mempool struct {
+	entries map[string]*mempoolEntry
+
+	// spent maps the identifier of each coin spent
+	// in the mempool to the hash of the spending
+	// transaction.
+	spent map[string]string
+
+	lock sync.RWMutex
+}
+
+func newMempool() *mempool {
+	return &mempool{
+		entries: map[string]*mempoolEntry{},
+		spent:   map[string]string{},
+	}
+}
+
+func (m *mempool) Has(hash string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	_, ok := m.entries[hash]
+	return ok
+}
+
+func (m *mempool) Hashes() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	hashes := make([]string, 0, len(m.entries))
+	for hash := range m.entries {
+		hashes = append(hashes, hash)
+	}
+
+	return hashes
+}
+
+func (m *mempool) Add(hash string, entry *mempoolEntry) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.entries[hash] = entry
+	for coinIdentifier := range entry.spent {
+		m.spent[coinIdentifier] = hash
+	}
+}
+
+func (m *mempool) Remove(hash string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	entry, ok := m.entries[hash]
+	if !ok {
+		return
+	}
+
+	for coinIdentifier := range entry.spent {
+		if m.spent[coinIdentifier] == hash {
+			delete(m.spent, coinIdentifier)
+		}
+	}
+	delete(m.entries, hash)
+}
+
+// Coins applies the mempool to a collection of confirmed
+// coins owned by an account. Coins spent in the mempool are
+// removed and coins created in the mempool are added.
+func (m *mempool) Coins(
+	account *types.AccountIdentifier,
+	confirmed []*types.Coin,
+) []*types.Coin {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	seen := map[string]struct{}{}
+	coins := []*types.Coin{}
+	for _, coin := range confirmed {
+		seen[coin.CoinIdentifier.Identifier] = struct{}{}
+		if _, ok := m.spent[coin.CoinIdentifier.Identifier]; ok {
+			continue
+		}
+
+		coins = append(coins, coin)
+	}
+
+	accountHash := types.Hash(account)
+	for _, entry := range m.entries {
+		for coinIdentifier, accountCoin := range entry.created {
+			if types.Hash(accountCoin.Account) != accountHash {
+				continue
+			}
+
+			if _, ok := seen[coinIdentifier]; ok {
+				continue
+			}
+
+			if _, ok := m.spent[coinIdentifier]; ok {
+				continue
+			}
+
+			coins = append(coins, accountCoin.Coin)
+		}
+	}
+
+	return coins
+}
+
+// BalanceChange returns the net change to the balance of
+// an account once all transactions in the mempool are confirmed.
+func (m *mempool) BalanceChange(account *types.AccountIdentifier) (*big.Int, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	accountHash := types.Hash(account)
+	change := new(big.Int)
+	for _, entry := range m.entries {
+		for _, accountCoin := range entry.created {
+			if types.Hash(accountCoin.Account) != accountHash {
+				continue
+			}
+
+			value, err := types.BigInt(accountCoin.Coin.Amount.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			change.Add(change, value)
+		}
+
+		for _, accountCoin := range entry.spent {
+			if types.Hash(accountCoin.Account) != accountHash {
+				continue
+			}
+
+			value, err := types.BigInt(accountCoin.Coin.Amount.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			change.Sub(change, value)
+		}
+	}
+
+	return change, nil
+}
+
+// Coin returns a coin created in the mempool and the
+// ScriptPubKey that locks it.
+func (m *mempool) Coin(
+	coinIdentifier string,
+) (*types.AccountCoin, *thought.ScriptPubKey, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	entry, ok := m.entries[thought.TransactionHash(coinIdentifier)]
+	if !ok {
+		return nil, nil, false
+	}
+
+	accountCoin, ok := entry.created[coinIdentifier]
+	if !ok {
+		return nil, nil, false
+	}
+
+	for _, output := range entry.transaction.Outputs {
+		if thought.CoinIdentifier(entry.transaction.Hash, output.Index) == coinIdentifier {
+			return accountCoin, output.ScriptPubKey, true
+		}
+	}
+
+	return nil, nil, false
+}
@@ -0,0 +1,373 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	searchNamespace = "search"
+
+	// searchOldestKey stores the index of the oldest
+	// block in the index. Blocks indexed before the
+	// index was introduced are not searchable.
+	searchOldestKey = searchNamespace + "/oldest"
+
+	// searchTransactionKind indexes transactions
+	// by their hash.
+	searchTransactionKind = "tx"
+
+	// searchAccountKind indexes transactions by the
+	// address of the accounts in their operations.
+	searchAccountKind = "account"
+
+	// searchCoinKind indexes transactions by the
+	// coins they create and spend.
+	searchCoinKind = "coin"
+
+	// searchPageSize is the number of entries read
+	// at a time when iterating through an index.
+	searchPageSize = 100
+)
+
+var (
+	_ modules.BlockWorker = (*SearchStorage)(nil)
+
+	// errSearchScanDone stops a scan of
+	// an index once a page is complete.
+	errSearchScanDone = errors.New("search page full")
+)
+
+// SearchStorage indexes the transactions in each block by
+// hash, account address and coin identifier so that they
+// can be found without scanning blocks.
+type SearchStorage struct {
+	db database.Database
+}
+
+// searchEntry is a transaction found in
+// a SearchStorage index.
+type searchEntry struct {
+	blockIdentifier       *types.BlockIdentifier
+	transactionIdentifier *types.TransactionIdentifier
+}
+
+// cursor returns the position of
+// the entry in its index.
+func (e *searchEntry) cursor() string {
+	return searchCursor(e.blockIdentifier.Index, e.transactionIdentifier.Hash)
+}
+
+// NewSearchStorage returns a new *SearchStorage.
+func NewSearchStorage(db database.Database) *SearchStorage {
+	return &SearchStorage{db: db}
+}
+
+// searchPrefix returns the prefix of all entries
+// of kind for key (ex: an address).
+func searchPrefix(kind string, key string) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s/", searchNamespace, kind, key))
+}
+
+// searchCursor returns the position of a transaction in an
+// index. The block index is inverted so that entries are
+// scanned from the most recent block.
+func searchCursor(blockIndex int64, transactionHash string) string {
+	return fmt.Sprintf("%019d/%s", math.MaxInt64-blockIndex, transactionHash)
+}
+
+// parseSearchCursor returns the block index and
+// transaction hash of a search cursor.
+func parseSearchCursor(cursor string) (int64, string, error) {
+	parts := strings.SplitN(cursor, "/", 2) // nolint:gomnd
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return -1, "", fmt.Errorf("search cursor %s is invalid", cursor)
+	}
+
+	invertedIndex, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || invertedIndex < 0 {
+		return -1, "", fmt.Errorf("search cursor %s is invalid", cursor)
+	}
+
+	return math.MaxInt64 - invertedIndex, parts[1], nil
+}
+
+// searchKey returns the key of a transaction
+// in the index of kind for key.
+func searchKey(
+	kind string,
+	key string,
+	blockIndex int64,
+	transactionHash string,
+) []byte {
+	return append(searchPrefix(kind, key), []byte(searchCursor(blockIndex, transactionHash))...)
+}
+
+// blockKeys returns the index keys of all
+// transactions in a block.
+func blockKeys(block *types.Block) [][]byte {
+	keys := [][]byte{}
+	seen := map[string]struct{}{}
+	add := func(kind string, key string, transactionHash string) {
+		k := searchKey(kind, key, block.BlockIdentifier.Index, transactionHash)
+		if _, ok := seen[string(k)]; ok {
+			return
+		}
+
+		seen[string(k)] = struct{}{}
+		keys = append(keys, k)
+	}
+
+	for _, transaction := range block.Transactions {
+		hash := transaction.TransactionIdentifier.Hash
+		add(searchTransactionKind, hash, hash)
+
+		for _, op := range transaction.Operations {
+			if op.Account != nil {
+				add(searchAccountKind, op.Account.Address, hash)
+			}
+
+			if op.CoinChange != nil {
+				add(searchCoinKind, op.CoinChange.CoinIdentifier.Identifier, hash)
+			}
+		}
+	}
+
+	return keys
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (s *SearchStorage) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	exists, _, err := transaction.Get(ctx, []byte(searchOldestKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get oldest search index block", err)
+	}
+
+	if !exists {
+		oldest := []byte(strconv.FormatInt(block.BlockIdentifier.Index, 10))
+		if err := transaction.Set(ctx, []byte(searchOldestKey), oldest, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store oldest search index block", err)
+		}
+	}
+
+	value := []byte(block.BlockIdentifier.Hash)
+	for _, key := range blockKeys(block) {
+		if err := transaction.Set(ctx, key, value, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store search index entry", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (s *SearchStorage) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	for _, key := range blockKeys(block) {
+		if err := transaction.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("%w: unable to delete search index entry", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// OldestIndex returns the index of the oldest block in
+// the index, or false if no block has been indexed.
+func (s *SearchStorage) OldestIndex(
+	ctx context.Context,
+	dbTx database.Transaction,
+) (int64, bool, error) {
+	exists, value, err := dbTx.Get(ctx, []byte(searchOldestKey))
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to get oldest search index block", err)
+	}
+
+	if !exists {
+		return -1, false, nil
+	}
+
+	index, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to parse oldest search index block", err)
+	}
+
+	return index, true, nil
+}
+
+// Contains returns true if a transaction in a block
+// is in the index of kind for key.
+func (s *SearchStorage) Contains(
+	ctx context.Context,
+	dbTx database.Transaction,
+	kind string,
+	key string,
+	entry *searchEntry,
+) (bool, error) {
+	exists, _, err := dbTx.Get(ctx, searchKey(
+		kind,
+		key,
+		entry.blockIdentifier.Index,
+		entry.transactionIdentifier.Hash,
+	))
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to get search index entry", err)
+	}
+
+	return exists, nil
+}
+
+// Entries returns up to limit transactions in the index of
+// kind for key, from the most recent block. Paging starts at
+// cursor (if populated) and the cursor of the next page is
+// returned if there are more transactions.
+func (s *SearchStorage) Entries(
+	ctx context.Context,
+	dbTx database.Transaction,
+	kind string,
+	key string,
+	cursor string,
+	limit int64,
+) ([]*searchEntry, string, error) {
+	prefix := searchPrefix(kind, key)
+	entries := []*searchEntry{}
+	nextCursor := ""
+	_, err := dbTx.Scan(
+		ctx,
+		prefix,
+		append(searchPrefix(kind, key), []byte(cursor)...),
+		func(k []byte, v []byte) error {
+			if int64(len(entries)) == limit {
+				nextCursor = string(k[len(prefix):])
+				return errSearchScanDone
+			}
+
+			blockIndex, transactionHash, err := parseSearchCursor(string(k[len(prefix):]))
+			if err != nil {
+				return fmt.Errorf("%w: search index key %s is invalid", err, string(k))
+			}
+
+			entries = append(entries, &searchEntry{
+				blockIdentifier: &types.BlockIdentifier{
+					Index: blockIndex,
+					Hash:  string(v),
+				},
+				transactionIdentifier: &types.TransactionIdentifier{
+					Hash: transactionHash,
+				},
+			})
+
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil && !errors.Is(err, errSearchScanDone) {
+		return nil, "", fmt.Errorf("%w: unable to scan search index", err)
+	}
+
+	return entries, nextCursor, nil
+}
+
+// searchIterator pages through the index of kind for
+// key, from the most recent block, so that indexes can
+// be merged without loading them into memory.
+type searchIterator struct {
+	storage *SearchStorage
+	dbTx    database.Transaction
+	kind    string
+	key     string
+
+	entries []*searchEntry
+	cursor  string
+	done    bool
+}
+
+// Iterator returns a *searchIterator over the index of
+// kind for key that starts at cursor (if populated).
+func (s *SearchStorage) Iterator(
+	dbTx database.Transaction,
+	kind string,
+	key string,
+	cursor string,
+) *searchIterator {
+	return &searchIterator{
+		storage: s,
+		dbTx:    dbTx,
+		kind:    kind,
+		key:     key,
+		cursor:  cursor,
+	}
+}
+
+// Peek returns the next entry of the index without
+// advancing the iterator, or nil if there are none.
+func (it *searchIterator) Peek(ctx context.Context) (*searchEntry, error) {
+	if len(it.entries) == 0 && !it.done {
+		entries, nextCursor, err := it.storage.Entries(
+			ctx,
+			it.dbTx,
+			it.kind,
+			it.key,
+			it.cursor,
+			searchPageSize,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		it.entries = entries
+		it.cursor = nextCursor
+		it.done = len(nextCursor) == 0
+	}
+
+	if len(it.entries) == 0 {
+		return nil, nil
+	}
+
+	return it.entries[0], nil
+}
+
+// Next returns the next entry of the index,
+// or nil if there are none.
+func (it *searchIterator) Next(ctx context.Context) (*searchEntry, error) {
+	entry, err := it.Peek(ctx)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+
+	it.entries = it.entries[1:]
+	return entry, nil
+}
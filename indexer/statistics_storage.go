@@ -0,0 +1,377 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	statisticsNamespace = "stats"
+
+	// statisticsOldestKey stores the index of the oldest
+	// block in the statistics. Statistics are only accurate
+	// if they start at genesis.
+	statisticsOldestKey = statisticsNamespace + "/oldest"
+
+	// statisticsSupplyKey stores the sum
+	// of the value of all unspent coins.
+	statisticsSupplyKey = statisticsNamespace + "/supply"
+
+	// statisticsCoinsKey stores the
+	// number of unspent coins.
+	statisticsCoinsKey = statisticsNamespace + "/coins"
+
+	// statisticsAddressesKey stores the number
+	// of addresses with a positive balance.
+	statisticsAddressesKey = statisticsNamespace + "/addresses"
+
+	// statisticsBalancePrefix prefixes the
+	// balance of each funded address.
+	statisticsBalancePrefix = statisticsNamespace + "/balance/"
+
+	// statisticsRichPrefix prefixes the funded addresses
+	// ordered by descending balance.
+	statisticsRichPrefix = statisticsNamespace + "/rich/"
+)
+
+var (
+	_ modules.BlockWorker = (*StatisticsStorage)(nil)
+
+	// errStatisticsScanDone stops a scan of the
+	// rich list once enough addresses are found.
+	errStatisticsScanDone = errors.New("statistics scan done")
+)
+
+// StatisticsStorage maintains aggregates of the unspent coins
+// in CoinStorage (the supply, the number of coins and of funded
+// addresses, and the addresses ordered by balance) as blocks
+// are added and removed.
+type StatisticsStorage struct {
+	db database.Database
+}
+
+// statistics are the aggregates of
+// the unspent coins at a block.
+type statistics struct {
+	supply       *big.Int
+	coinCount    int64
+	addressCount int64
+
+	// richAccounts are the funded addresses with the
+	// largest balances, in descending order of balance.
+	richAccounts []*types.AccountIdentifier
+	richBalances []*big.Int
+}
+
+// NewStatisticsStorage returns a new *StatisticsStorage.
+func NewStatisticsStorage(db database.Database) *StatisticsStorage {
+	return &StatisticsStorage{db: db}
+}
+
+// richKey returns the key of an address in the rich list. The
+// balance is inverted so that the largest balances are first.
+func richKey(address string, balance *big.Int) []byte {
+	return []byte(fmt.Sprintf(
+		"%s%019d/%s",
+		statisticsRichPrefix,
+		math.MaxInt64-balance.Int64(),
+		address,
+	))
+}
+
+// getStatistic returns the value stored at
+// key (0 if it does not exist).
+func getStatistic(ctx context.Context, dbTx database.Transaction, key string) (*big.Int, error) {
+	exists, value, err := dbTx.Get(ctx, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get %s", err, key)
+	}
+
+	if !exists {
+		return new(big.Int), nil
+	}
+
+	statistic, ok := new(big.Int).SetString(string(value), 10)
+	if !ok {
+		return nil, fmt.Errorf("unable to parse %s", key)
+	}
+
+	return statistic, nil
+}
+
+// addStatistic adds delta to the value stored at key, deleting
+// the key if the result is 0, and returns the previous and
+// new values.
+func addStatistic(
+	ctx context.Context,
+	dbTx database.Transaction,
+	key string,
+	delta *big.Int,
+) (*big.Int, *big.Int, error) {
+	previous, err := getStatistic(ctx, dbTx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated := new(big.Int).Add(previous, delta)
+	if updated.Sign() == 0 {
+		if err := dbTx.Delete(ctx, []byte(key)); err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to delete %s", err, key)
+		}
+
+		return previous, updated, nil
+	}
+
+	if err := dbTx.Set(ctx, []byte(key), []byte(updated.String()), true); err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to set %s", err, key)
+	}
+
+	return previous, updated, nil
+}
+
+// updateStatistics applies the coins created and spent by a
+// block to the statistics, or reverts them if removing.
+func (s *StatisticsStorage) updateStatistics(
+	ctx context.Context,
+	dbTx database.Transaction,
+	block *types.Block,
+	removing bool,
+) error {
+	supply := new(big.Int)
+	coins := int64(0)
+	balances := map[string]*big.Int{}
+	for _, transaction := range block.Transactions {
+		for _, op := range transaction.Operations {
+			if op.CoinChange == nil || op.Amount == nil {
+				continue
+			}
+
+			// The amount of a spent coin is negative.
+			value, err := types.BigInt(op.Amount.Value)
+			if err != nil {
+				return fmt.Errorf(
+					"%w: unable to parse amount of coin %s",
+					err,
+					op.CoinChange.CoinIdentifier.Identifier,
+				)
+			}
+
+			if removing {
+				value.Neg(value)
+			}
+
+			supply.Add(supply, value)
+			if (op.CoinChange.CoinAction == types.CoinCreated) != removing {
+				coins++
+			} else {
+				coins--
+			}
+
+			if op.Account == nil {
+				continue
+			}
+
+			if _, ok := balances[op.Account.Address]; !ok {
+				balances[op.Account.Address] = new(big.Int)
+			}
+			balances[op.Account.Address].Add(balances[op.Account.Address], value)
+		}
+	}
+
+	if _, _, err := addStatistic(ctx, dbTx, statisticsSupplyKey, supply); err != nil {
+		return err
+	}
+
+	if _, _, err := addStatistic(ctx, dbTx, statisticsCoinsKey, big.NewInt(coins)); err != nil {
+		return err
+	}
+
+	addresses := int64(0)
+	for address, delta := range balances {
+		if delta.Sign() == 0 {
+			continue
+		}
+
+		previous, updated, err := addStatistic(ctx, dbTx, statisticsBalancePrefix+address, delta)
+		if err != nil {
+			return err
+		}
+
+		if !updated.IsInt64() {
+			return fmt.Errorf("balance of %s is out of range", address)
+		}
+
+		if previous.Sign() > 0 {
+			if err := dbTx.Delete(ctx, richKey(address, previous)); err != nil {
+				return fmt.Errorf("%w: unable to delete rich list entry of %s", err, address)
+			}
+		}
+
+		if updated.Sign() > 0 {
+			if err := dbTx.Set(ctx, richKey(address, updated), []byte{}, true); err != nil {
+				return fmt.Errorf("%w: unable to store rich list entry of %s", err, address)
+			}
+		}
+
+		switch {
+		case previous.Sign() <= 0 && updated.Sign() > 0:
+			addresses++
+		case previous.Sign() > 0 && updated.Sign() <= 0:
+			addresses--
+		}
+	}
+
+	_, _, err := addStatistic(ctx, dbTx, statisticsAddressesKey, big.NewInt(addresses))
+	return err
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (s *StatisticsStorage) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	exists, _, err := transaction.Get(ctx, []byte(statisticsOldestKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get oldest statistics block", err)
+	}
+
+	if !exists {
+		oldest := []byte(strconv.FormatInt(block.BlockIdentifier.Index, 10))
+		if err := transaction.Set(ctx, []byte(statisticsOldestKey), oldest, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store oldest statistics block", err)
+		}
+	}
+
+	return nil, s.updateStatistics(ctx, transaction, block, false)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (s *StatisticsStorage) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, s.updateStatistics(ctx, transaction, block, true)
+}
+
+// OldestIndex returns the index of the oldest block in the
+// statistics, or false if no block has been indexed.
+func (s *StatisticsStorage) OldestIndex(
+	ctx context.Context,
+	dbTx database.Transaction,
+) (int64, bool, error) {
+	exists, value, err := dbTx.Get(ctx, []byte(statisticsOldestKey))
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to get oldest statistics block", err)
+	}
+
+	if !exists {
+		return -1, false, nil
+	}
+
+	index, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to parse oldest statistics block", err)
+	}
+
+	return index, true, nil
+}
+
+// Statistics returns the statistics and the limit
+// addresses with the largest balances.
+func (s *StatisticsStorage) Statistics(
+	ctx context.Context,
+	dbTx database.Transaction,
+	limit int64,
+) (*statistics, error) {
+	supply, err := getStatistic(ctx, dbTx, statisticsSupplyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	coins, err := getStatistic(ctx, dbTx, statisticsCoinsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := getStatistic(ctx, dbTx, statisticsAddressesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &statistics{
+		supply:       supply,
+		coinCount:    coins.Int64(),
+		addressCount: addresses.Int64(),
+		richAccounts: []*types.AccountIdentifier{},
+		richBalances: []*big.Int{},
+	}
+	if limit == 0 {
+		return stats, nil
+	}
+
+	prefix := []byte(statisticsRichPrefix)
+	_, err = dbTx.Scan(
+		ctx,
+		prefix,
+		prefix,
+		func(k []byte, v []byte) error {
+			parts := strings.SplitN(string(k[len(prefix):]), "/", 2) // nolint:gomnd
+			if len(parts) != 2 {
+				return fmt.Errorf("rich list key %s is invalid", string(k))
+			}
+
+			invertedBalance, err := strconv.ParseInt(parts[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: rich list key %s is invalid", err, string(k))
+			}
+
+			stats.richAccounts = append(stats.richAccounts, &types.AccountIdentifier{
+				Address: parts[1],
+			})
+			stats.richBalances = append(
+				stats.richBalances,
+				big.NewInt(math.MaxInt64-invertedBalance),
+			)
+			if int64(len(stats.richAccounts)) == limit {
+				return errStatisticsScanDone
+			}
+
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil && !errors.Is(err, errStatisticsScanDone) {
+		return nil, fmt.Errorf("%w: unable to scan rich list", err)
+	}
+
+	return stats, nil
+}
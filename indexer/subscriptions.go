@@ -0,0 +1,123 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"sort"
+
+	"github.com/thoughtnetwork/rosetta-thought/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Subscriptions returns the *services.Subscriptions
+// that the indexer publishes events to.
+func (i *Indexer) Subscriptions() *services.Subscriptions {
+	return i.subscriptions
+}
+
+// addressEvents returns an AddressTopic event
+// for each address in a transaction.
+func addressEvents(
+	blockIdentifier *types.BlockIdentifier,
+	transactionIdentifier *types.TransactionIdentifier,
+	addresses map[string]struct{},
+) []*services.StreamEvent {
+	sorted := make([]string, 0, len(addresses))
+	for address := range addresses {
+		sorted = append(sorted, address)
+	}
+	sort.Strings(sorted)
+
+	events := make([]*services.StreamEvent, len(sorted))
+	for j, address := range sorted {
+		events[j] = &services.StreamEvent{
+			Topic:                 services.AddressTopic,
+			BlockIdentifier:       blockIdentifier,
+			TransactionIdentifier: transactionIdentifier,
+			Address:               address,
+		}
+	}
+
+	return events
+}
+
+// publishBlockAdded publishes the BlockAddedTopic event
+// of a block and the AddressTopic events of its
+// transactions.
+func (i *Indexer) publishBlockAdded(block *types.Block) {
+	if !i.subscriptions.Active() {
+		return
+	}
+
+	events := []*services.StreamEvent{
+		{
+			Topic:           services.BlockAddedTopic,
+			BlockIdentifier: block.BlockIdentifier,
+		},
+	}
+	for _, transaction := range block.Transactions {
+		addresses := map[string]struct{}{}
+		for _, op := range transaction.Operations {
+			if op.Account != nil {
+				addresses[op.Account.Address] = struct{}{}
+			}
+		}
+
+		events = append(events, addressEvents(
+			block.BlockIdentifier,
+			transaction.TransactionIdentifier,
+			addresses,
+		)...)
+	}
+
+	i.subscriptions.Publish(events...)
+}
+
+// publishBlockRemoved publishes the
+// BlockRemovedTopic event of a block.
+func (i *Indexer) publishBlockRemoved(blockIdentifier *types.BlockIdentifier) {
+	i.subscriptions.Publish(&services.StreamEvent{
+		Topic:           services.BlockRemovedTopic,
+		BlockIdentifier: blockIdentifier,
+	})
+}
+
+// publishMempoolTransaction publishes the MempoolTopic
+// event of a transaction added to the mempool and the
+// AddressTopic events of the coins it creates and spends.
+func (i *Indexer) publishMempoolTransaction(hash string, entry *mempoolEntry) {
+	if !i.subscriptions.Active() {
+		return
+	}
+
+	transactionIdentifier := &types.TransactionIdentifier{Hash: hash}
+	addresses := map[string]struct{}{}
+	for _, coins := range []map[string]*types.AccountCoin{entry.created, entry.spent} {
+		for _, accountCoin := range coins {
+			addresses[accountCoin.Account.Address] = struct{}{}
+		}
+	}
+
+	events := []*services.StreamEvent{
+		{
+			Topic:                 services.MempoolTopic,
+			TransactionIdentifier: transactionIdentifier,
+		},
+	}
+	events = append(events, addressEvents(nil, transactionIdentifier, addresses)...)
+
+	i.subscriptions.Publish(events...)
+}
@@ -0,0 +1,418 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/utils"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	sdkUtils "github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	webhookNamespace = "webhook"
+
+	// webhookHeadKey stores the sequence of the
+	// next notification added to the queue.
+	webhookHeadKey = webhookNamespace + "/head"
+
+	// webhookTailKey stores the sequence of the
+	// oldest notification not yet delivered.
+	webhookTailKey = webhookNamespace + "/tail"
+
+	// webhookTransactionIdentifier is the identifier
+	// of the write transactions of the webhook queue.
+	webhookTransactionIdentifier = "webhook"
+
+	// WebhookBlockAdded is sent when a block is added.
+	WebhookBlockAdded = "block_added"
+
+	// WebhookBlockRemoved is sent when a block is
+	// removed in a reorg.
+	WebhookBlockRemoved = "block_removed"
+
+	// WebhookAddressActivity is sent when a block
+	// with transactions of a watched address is added.
+	WebhookAddressActivity = "address_activity"
+
+	// WebhookAddressActivityRemoved is sent when a block
+	// with transactions of a watched address is removed.
+	WebhookAddressActivityRemoved = "address_activity_removed"
+
+	// WebhookSignatureHeader is the header containing the
+	// hex-encoded HMAC-SHA256 of the body of a notification
+	// keyed with the webhook secret.
+	WebhookSignatureHeader = "X-Rosetta-Signature"
+
+	// webhookTimeout is the maximum duration
+	// of a notification delivery.
+	webhookTimeout = 10 * time.Second
+
+	// webhookBatchSize is the maximum number of
+	// notifications read from the queue at once.
+	webhookBatchSize = 100
+)
+
+// WebhookNotification is the JSON payload
+// posted to the webhook URL.
+type WebhookNotification struct {
+	// Sequence increases by 1 with each notification
+	// so receivers can detect duplicate deliveries.
+	Sequence               int64                          `json:"sequence"`
+	Type                   string                         `json:"type"`
+	BlockIdentifier        *types.BlockIdentifier         `json:"block_identifier"`
+	Address                string                         `json:"address,omitempty"`
+	TransactionIdentifiers []*types.TransactionIdentifier `json:"transaction_identifiers,omitempty"`
+}
+
+var _ modules.BlockWorker = (*WebhookStorage)(nil)
+
+// WebhookStorage queues a notification for each block added
+// and removed by BlockStorage, and for the activity of watched
+// addresses in those blocks. Notifications are queued in the
+// same database transaction as the block they refer to and are
+// removed from the queue once delivered.
+type WebhookStorage struct {
+	db        database.Database
+	addresses map[string]struct{}
+}
+
+// NewWebhookStorage returns a new *WebhookStorage
+// watching addresses.
+func NewWebhookStorage(db database.Database, addresses []string) *WebhookStorage {
+	watched := map[string]struct{}{}
+	for _, address := range addresses {
+		watched[address] = struct{}{}
+	}
+
+	return &WebhookStorage{
+		db:        db,
+		addresses: watched,
+	}
+}
+
+// webhookKey returns the key of the
+// notification with sequence.
+func webhookKey(sequence int64) []byte {
+	return []byte(fmt.Sprintf("%s/queue/%019d", webhookNamespace, sequence))
+}
+
+// getSequence returns the sequence stored at key
+// or 0 if it is not populated.
+func getSequence(ctx context.Context, dbTx database.Transaction, key string) (int64, error) {
+	exists, value, err := dbTx.Get(ctx, []byte(key))
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to get %s", err, key)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	sequence, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to parse %s", err, key)
+	}
+
+	return sequence, nil
+}
+
+// setSequence stores sequence at key.
+func setSequence(ctx context.Context, dbTx database.Transaction, key string, sequence int64) error {
+	if err := dbTx.Set(ctx, []byte(key), []byte(strconv.FormatInt(sequence, 10)), true); err != nil {
+		return fmt.Errorf("%w: unable to set %s", err, key)
+	}
+
+	return nil
+}
+
+// blockNotifications returns the notifications of adding or
+// removing a block in the order they are delivered.
+func (w *WebhookStorage) blockNotifications(
+	block *types.Block,
+	adding bool,
+) []*WebhookNotification {
+	blockType, activityType := WebhookBlockAdded, WebhookAddressActivity
+	if !adding {
+		blockType, activityType = WebhookBlockRemoved, WebhookAddressActivityRemoved
+	}
+
+	notifications := []*WebhookNotification{
+		{
+			Type:            blockType,
+			BlockIdentifier: block.BlockIdentifier,
+		},
+	}
+
+	// Activity is collected in transaction order so
+	// notifications are deterministic.
+	activity := map[string]*WebhookNotification{}
+	for _, transaction := range block.Transactions {
+		for _, op := range transaction.Operations {
+			if op.Account == nil {
+				continue
+			}
+
+			address := op.Account.Address
+			if _, ok := w.addresses[address]; !ok {
+				continue
+			}
+
+			notification, ok := activity[address]
+			if !ok {
+				notification = &WebhookNotification{
+					Type:            activityType,
+					BlockIdentifier: block.BlockIdentifier,
+					Address:         address,
+				}
+				activity[address] = notification
+				notifications = append(notifications, notification)
+			}
+
+			identifiers := notification.TransactionIdentifiers
+			if len(identifiers) == 0 ||
+				identifiers[len(identifiers)-1].Hash != transaction.TransactionIdentifier.Hash {
+				notification.TransactionIdentifiers = append(
+					identifiers,
+					transaction.TransactionIdentifier,
+				)
+			}
+		}
+	}
+
+	return notifications
+}
+
+// enqueue adds the notifications of adding or
+// removing a block to the end of the queue.
+func (w *WebhookStorage) enqueue(
+	ctx context.Context,
+	dbTx database.Transaction,
+	block *types.Block,
+	adding bool,
+) error {
+	head, err := getSequence(ctx, dbTx, webhookHeadKey)
+	if err != nil {
+		return err
+	}
+
+	for _, notification := range w.blockNotifications(block, adding) {
+		notification.Sequence = head
+		value, err := w.db.Encoder().Encode(webhookNamespace, notification)
+		if err != nil {
+			return fmt.Errorf("%w: unable to encode webhook notification", err)
+		}
+
+		if err := dbTx.Set(ctx, webhookKey(head), value, true); err != nil {
+			return fmt.Errorf("%w: unable to store webhook notification", err)
+		}
+
+		head++
+	}
+
+	return setSequence(ctx, dbTx, webhookHeadKey, head)
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *WebhookStorage) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.enqueue(ctx, transaction, block, true)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *WebhookStorage) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.enqueue(ctx, transaction, block, false)
+}
+
+// Pending returns up to limit notifications that
+// have not been delivered, oldest first.
+func (w *WebhookStorage) Pending(
+	ctx context.Context,
+	limit int64,
+) ([]*WebhookNotification, error) {
+	dbTx := w.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	tail, err := getSequence(ctx, dbTx, webhookTailKey)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := getSequence(ctx, dbTx, webhookHeadKey)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := []*WebhookNotification{}
+	for sequence := tail; sequence < head && sequence < tail+limit; sequence++ {
+		exists, value, err := dbTx.Get(ctx, webhookKey(sequence))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get webhook notification %d", err, sequence)
+		}
+
+		if !exists {
+			return nil, fmt.Errorf("webhook notification %d not found", sequence)
+		}
+
+		var notification WebhookNotification
+		if err := w.db.Encoder().Decode(webhookNamespace, value, &notification, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to decode webhook notification %d", err, sequence)
+		}
+
+		notifications = append(notifications, &notification)
+	}
+
+	return notifications, nil
+}
+
+// Delivered removes the notification with
+// sequence from the front of the queue.
+func (w *WebhookStorage) Delivered(ctx context.Context, sequence int64) error {
+	dbTx := w.db.WriteTransaction(ctx, webhookTransactionIdentifier, true)
+	defer dbTx.Discard(ctx)
+
+	tail, err := getSequence(ctx, dbTx, webhookTailKey)
+	if err != nil {
+		return err
+	}
+
+	if sequence != tail {
+		return fmt.Errorf("webhook notification %d is not at the front of the queue (%d)", sequence, tail)
+	}
+
+	if err := dbTx.Delete(ctx, webhookKey(sequence)); err != nil {
+		return fmt.Errorf("%w: unable to delete webhook notification %d", err, sequence)
+	}
+
+	if err := setSequence(ctx, dbTx, webhookTailKey, sequence+1); err != nil {
+		return err
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+// deliverWebhook posts a notification to the webhook URL,
+// signed with the webhook secret.
+func deliverWebhook(
+	ctx context.Context,
+	client *http.Client,
+	config *configuration.WebhookConfiguration,
+	notification *WebhookNotification,
+) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal webhook notification", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(config.Secret))
+	_, _ = mac.Write(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: unable to create webhook request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: unable to post webhook notification", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook returned status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// DispatchWebhooks delivers queued webhook notifications in order
+// until stopped. Failed deliveries are retried with exponential
+// backoff so that notifications are never skipped.
+func (i *Indexer) DispatchWebhooks(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "webhooks")
+	client := &http.Client{Timeout: webhookTimeout}
+
+	retryInterval := i.webhookConfig.RetryInterval
+	for {
+		notifications, err := i.webhookStorage.Pending(ctx, webhookBatchSize)
+		if err != nil {
+			logger.Warnw("unable to get pending webhook notifications", "error", err)
+		}
+
+		failed := false
+		for _, notification := range notifications {
+			if err := deliverWebhook(ctx, client, i.webhookConfig, notification); err != nil {
+				logger.Warnw(
+					"unable to deliver webhook notification",
+					"sequence", notification.Sequence,
+					"retry interval", retryInterval,
+					"error", err,
+				)
+				failed = true
+				break
+			}
+
+			if err := i.webhookStorage.Delivered(ctx, notification.Sequence); err != nil {
+				logger.Warnw("unable to remove delivered webhook notification", "error", err)
+				failed = true
+				break
+			}
+		}
+
+		// Back off while deliveries fail and poll the
+		// queue at the retry interval otherwise.
+		sleep := i.webhookConfig.RetryInterval
+		if failed {
+			sleep = retryInterval
+			retryInterval *= 2
+			if retryInterval > i.webhookConfig.MaxRetryInterval {
+				retryInterval = i.webhookConfig.MaxRetryInterval
+			}
+		} else {
+			retryInterval = i.webhookConfig.RetryInterval
+		}
+
+		if err := sdkUtils.ContextSleep(ctx, sleep); err != nil {
+			logger.Warnw("exiting webhook dispatcher")
+			return err
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/indexer"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexer_Webhooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	// The receiver rejects the first delivery
+	// to exercise retries.
+	var mutex sync.Mutex
+	attempts := 0
+	received := []*WebhookNotification{}
+	done := make(chan struct{})
+	expectedCount := 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		_, _ = mac.Write(body)
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), r.Header.Get(WebhookSignatureHeader))
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var notification WebhookNotification
+		assert.NoError(t, json.Unmarshal(body, &notification))
+		received = append(received, &notification)
+		if len(received) == expectedCount {
+			close(done)
+		}
+	}))
+	defer server.Close()
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    thought.MainnetNetwork,
+			Blockchain: thought.Blockchain,
+		},
+		GenesisBlockIdentifier: thought.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+		Webhook: &configuration.WebhookConfiguration{
+			URL:              server.URL,
+			Secret:           "secret",
+			Addresses:        []string{"addr2"},
+			RetryInterval:    10 * time.Millisecond,
+			MaxRetryInterval: 50 * time.Millisecond,
+		},
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	blocks, _ := addCoinBlocks(ctx, t, i)
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2]))
+
+	dispatchCtx, dispatchCancel := context.WithCancel(ctx)
+	dispatched := make(chan error)
+	go func() {
+		dispatched <- i.DispatchWebhooks(dispatchCtx)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "timed out waiting for webhook notifications")
+	}
+
+	// Delivered notifications are removed from the queue
+	assert.Eventually(t, func() bool {
+		pending, err := i.webhookStorage.Pending(ctx, webhookBatchSize)
+		return err == nil && len(pending) == 0
+	}, 10*time.Second, 10*time.Millisecond)
+	dispatchCancel()
+	assert.Error(t, <-dispatched)
+
+	tx1 := &types.TransactionIdentifier{
+		Hash: fmt.Sprintf("%x", sha256.Sum256([]byte("tx1"))),
+	}
+	assert.Equal(t, []*WebhookNotification{
+		{Sequence: 0, Type: WebhookBlockAdded, BlockIdentifier: blocks[0]},
+		{Sequence: 1, Type: WebhookBlockAdded, BlockIdentifier: blocks[1]},
+		{
+			Sequence:               2,
+			Type:                   WebhookAddressActivity,
+			BlockIdentifier:        blocks[1],
+			Address:                "addr2",
+			TransactionIdentifiers: []*types.TransactionIdentifier{tx1},
+		},
+		{Sequence: 3, Type: WebhookBlockAdded, BlockIdentifier: blocks[2]},
+		{Sequence: 4, Type: WebhookBlockRemoved, BlockIdentifier: blocks[2]},
+	}, received)
+	assert.Equal(t, expectedCount+1, attempts)
+
+	mockClient.AssertExpectations(t)
+}
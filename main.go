@@ -21,8 +21,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
 	"github.com/thoughtnetwork/rosetta-thought/thought"
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
@@ -38,21 +38,6 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-const (
-	// readTimeout is the maximum duration for reading the entire
-	// request, including the body.
-	readTimeout = 5 * time.Second
-
-	// writeTimeout is the maximum duration before timing out
-	// writes of the response. It is reset whenever a new
-	// request's header is read.
-	writeTimeout = 15 * time.Second
-
-	// idleTimeout is the maximum amount of time to wait for the
-	// next request when keep-alives are enabled.
-	idleTimeout = 30 * time.Second
-)
-
 var (
 	signalReceived = false
 )
@@ -74,21 +59,64 @@ func handleSignals(ctx context.Context, listeners []context.CancelFunc) {
 	}()
 }
 
+// newExternalClient returns a *thought.Client
+// connected to an external thoughtd.
+func newExternalClient(cfg *configuration.Configuration) (*thought.Client, error) {
+	external := cfg.ExternalThoughtd
+	options := []thought.ClientOption{}
+	if len(external.CookieFile) > 0 {
+		options = append(options, thought.WithCookieFile(external.CookieFile))
+	} else {
+		options = append(options, thought.WithCredentials(external.Username, external.Password))
+	}
+
+	if strings.HasPrefix(external.URL, "https://") {
+		tlsConfig, err := thought.LoadTLSConfig(
+			external.TLSCAFile,
+			external.TLSCertFile,
+			external.TLSKeyFile,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, thought.WithTLSConfig(tlsConfig))
+	}
+
+	return thought.NewClient(
+		external.URL,
+		cfg.GenesisBlockIdentifier,
+		cfg.Currency,
+		options...,
+	), nil
+}
+
 func startOnlineDependencies(
 	ctx context.Context,
 	cancel context.CancelFunc,
 	cfg *configuration.Configuration,
 	g *errgroup.Group,
-) (*thought.Client, *indexer.Indexer, error) {
-	client := thought.NewClient(
-		thought.LocalhostURL(cfg.RPCPort),
-		cfg.GenesisBlockIdentifier,
-		cfg.Currency,
-	)
-
-	g.Go(func() error {
-		return thought.StartThoughtd(ctx, cfg.ConfigPath, g)
-	})
+) (*thought.Client, *thought.Process, *indexer.Indexer, error) {
+	var client *thought.Client
+	var process *thought.Process
+	if cfg.ExternalThoughtd != nil {
+		var err error
+		client, err = newExternalClient(cfg)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: unable to initialize thoughtd client", err)
+		}
+	} else {
+		client = thought.NewClient(
+			thought.LocalhostURL(cfg.RPCPort),
+			cfg.GenesisBlockIdentifier,
+			cfg.Currency,
+		)
+
+		process = &thought.Process{}
+		g.Go(func() error {
+			return thought.StartThoughtd(ctx, cfg.ConfigPath, g, process)
+		})
+	}
 
 	i, err := indexer.Initialize(
 		ctx,
@@ -97,18 +125,30 @@ func startOnlineDependencies(
 		client,
 	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: unable to initialize indexer", err)
+		return nil, nil, nil, fmt.Errorf("%w: unable to initialize indexer", err)
 	}
 
 	g.Go(func() error {
 		return i.Sync(ctx)
 	})
 
+	if cfg.Pruning != nil {
+		g.Go(func() error {
+			return i.Prune(ctx)
+		})
+	}
+
 	g.Go(func() error {
-		return i.Prune(ctx)
+		return i.SyncMempool(ctx)
 	})
 
-	return client, i, nil
+	if cfg.Webhook != nil {
+		g.Go(func() error {
+			return i.DispatchWebhooks(ctx)
+		})
+	}
+
+	return client, process, i, nil
 }
 
 func main() {
@@ -143,8 +183,9 @@ func main() {
 
 	var i *indexer.Indexer
 	var client *thought.Client
+	var process *thought.Process
 	if cfg.Mode == configuration.Online {
-		client, i, err = startOnlineDependencies(ctx, cancel, cfg, g)
+		client, process, i, err = startOnlineDependencies(ctx, cancel, cfg, g)
 		if err != nil {
 			logger.Fatalw("unable to start online dependencies", "error", err)
 		}
@@ -156,7 +197,7 @@ func main() {
 		thought.OperationTypes,
 		services.HistoricalBalanceLookup,
 		[]*types.NetworkIdentifier{cfg.Network},
-		nil,
+		services.CallMethods,
 		services.MempoolCoins,
 		"",
 	)
@@ -164,15 +205,28 @@ func main() {
 		logger.Fatalw("unable to create new server asserter", "error", err)
 	}
 
-	router := services.NewBlockchainRouter(cfg, client, i, asserter)
-	loggedRouter := services.LoggerMiddleware(loggerRaw, router)
+	var subscriptions *services.Subscriptions
+	if i != nil {
+		subscriptions = i.Subscriptions()
+	}
+
+	router := services.NewBlockchainRouter(
+		cfg,
+		client,
+		i,
+		subscriptions,
+		process,
+		asserter,
+	)
+	metricsRouter := services.MetricsMiddleware(router)
+	loggedRouter := services.LoggerMiddleware(loggerRaw, metricsRouter)
 	corsRouter := server.CorsMiddleware(loggedRouter)
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
 		Handler:      corsRouter,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	g.Go(func() error {
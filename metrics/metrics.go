@@ -0,0 +1,177 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines the Prometheus metrics
+// exported by rosetta-thought at /metrics.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// namespace prefixes the name of every metric.
+	namespace = "rosetta_thought"
+
+	// Path is the path metrics are served at.
+	Path = "/metrics"
+)
+
+var (
+	// registry contains all metrics. A dedicated registry is used
+	// instead of the global one so that only the metrics defined
+	// here (and the Go and process collectors) are exported.
+	registry = prometheus.NewRegistry()
+
+	// IndexerHeight is the index of the
+	// last block added by the indexer.
+	IndexerHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "head_height",
+		Help:      "Index of the last block added by the indexer.",
+	})
+
+	// NodeHeight is the index of the current
+	// block of thoughtd.
+	NodeHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "node",
+		Name:      "head_height",
+		Help:      "Index of the current block of thoughtd.",
+	})
+
+	// BlocksAdded is the number of blocks added by the
+	// indexer. Its rate is the number of blocks indexed
+	// per second.
+	BlocksAdded = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "blocks_added_total",
+		Help:      "Number of blocks added by the indexer.",
+	})
+
+	// BlocksRemoved is the number of blocks
+	// removed by the indexer during reorgs.
+	BlocksRemoved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "blocks_removed_total",
+		Help:      "Number of blocks removed by the indexer during reorgs.",
+	})
+
+	// Reorgs is the number of reorgs handled by the
+	// indexer, regardless of how many blocks they
+	// removed.
+	Reorgs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "reorgs_total",
+		Help:      "Number of reorgs handled by the indexer.",
+	})
+
+	// WaitTableSize is the number of transactions the
+	// indexer is waiting to see while populating blocks.
+	WaitTableSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "wait_table_size",
+		Help:      "Number of transactions the indexer is waiting to see.",
+	})
+
+	// CoinCacheSize is the number of coins created in seen
+	// blocks that are not yet stored by the indexer.
+	CoinCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "coin_cache_size",
+		Help:      "Number of coins created in seen blocks that are not yet stored.",
+	})
+
+	// RPCDuration is the latency of thoughtd
+	// RPC requests by method.
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of thoughtd RPC requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// RPCErrors is the number of failed
+	// thoughtd RPC requests by method.
+	RPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Number of failed thoughtd RPC requests.",
+	}, []string{"method"})
+
+	// APIDuration is the latency of Rosetta
+	// API requests by endpoint.
+	APIDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of Rosetta API requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	// APIRequests is the number of Rosetta API
+	// requests by endpoint and status code.
+	APIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "Number of Rosetta API requests.",
+	}, []string{"endpoint", "code"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		IndexerHeight,
+		NodeHeight,
+		BlocksAdded,
+		BlocksRemoved,
+		Reorgs,
+		WaitTableSize,
+		CoinCacheSize,
+		RPCDuration,
+		RPCErrors,
+		APIDuration,
+		APIRequests,
+	)
+}
+
+// ObserveRPC records the latency of a thoughtd RPC
+// request that started at start and whether it failed.
+func ObserveRPC(method string, start time.Time, err error) {
+	RPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// Handler returns the http.Handler
+// that serves the metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
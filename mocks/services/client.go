@@ -5,6 +5,8 @@ package services
 import (
 	context "context"
 
+	thought "github.com/thoughtnetwork/rosetta-thought/thought"
+
 	mock "github.com/stretchr/testify/mock"
 
 	types "github.com/coinbase/rosetta-sdk-go/types"
@@ -15,6 +17,29 @@ type Client struct {
 	mock.Mock
 }
 
+// GetBlockchainInfo provides a mock function with given fields: _a0
+func (_m *Client) GetBlockchainInfo(_a0 context.Context) (*thought.BlockchainInfo, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *thought.BlockchainInfo
+	if rf, ok := ret.Get(0).(func(context.Context) *thought.BlockchainInfo); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*thought.BlockchainInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPeers provides a mock function with given fields: _a0
 func (_m *Client) GetPeers(_a0 context.Context) ([]*types.Peer, error) {
 	ret := _m.Called(_a0)
@@ -17,6 +17,109 @@ type Indexer struct {
 	mock.Mock
 }
 
+// FindTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) FindTransaction(_a0 context.Context, _a1 *types.TransactionIdentifier) (*types.BlockIdentifier, *types.Transaction, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.BlockIdentifier
+	if rf, ok := ret.Get(0).(func(context.Context, *types.TransactionIdentifier) *types.BlockIdentifier); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.BlockIdentifier)
+		}
+	}
+
+	var r1 *types.Transaction
+	if rf, ok := ret.Get(1).(func(context.Context, *types.TransactionIdentifier) *types.Transaction); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*types.Transaction)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *types.TransactionIdentifier) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetAccountHistory provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4, _a5
+func (_m *Indexer) GetAccountHistory(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 string, _a3 *int64, _a4 *int64, _a5 int64) ([]*thought.HistoryTransaction, string, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4, _a5)
+
+	var r0 []*thought.HistoryTransaction
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, string, *int64, *int64, int64) []*thought.HistoryTransaction); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*thought.HistoryTransaction)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, string, *int64, *int64, int64) string); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *types.AccountIdentifier, string, *int64, *int64, int64) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetAccounts provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4, _a5
+func (_m *Indexer) GetAccounts(_a0 context.Context, _a1 []*types.AccountIdentifier, _a2 *types.Currency, _a3 bool, _a4 bool, _a5 *types.PartialBlockIdentifier) ([]*types.Amount, [][]*types.Coin, *types.BlockIdentifier, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4, _a5)
+
+	var r0 []*types.Amount
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.AccountIdentifier, *types.Currency, bool, bool, *types.PartialBlockIdentifier) []*types.Amount); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Amount)
+		}
+	}
+
+	var r1 [][]*types.Coin
+	if rf, ok := ret.Get(1).(func(context.Context, []*types.AccountIdentifier, *types.Currency, bool, bool, *types.PartialBlockIdentifier) [][]*types.Coin); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([][]*types.Coin)
+		}
+	}
+
+	var r2 *types.BlockIdentifier
+	if rf, ok := ret.Get(2).(func(context.Context, []*types.AccountIdentifier, *types.Currency, bool, bool, *types.PartialBlockIdentifier) *types.BlockIdentifier); ok {
+		r2 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*types.BlockIdentifier)
+		}
+	}
+
+	var r3 error
+	if rf, ok := ret.Get(3).(func(context.Context, []*types.AccountIdentifier, *types.Currency, bool, bool, *types.PartialBlockIdentifier) error); ok {
+		r3 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
 // GetBalance provides a mock function with given fields: _a0, _a1, _a2, _a3
 func (_m *Indexer) GetBalance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.Currency, _a3 *types.PartialBlockIdentifier) (*types.Amount, *types.BlockIdentifier, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3)
@@ -49,6 +152,36 @@ func (_m *Indexer) GetBalance(_a0 context.Context, _a1 *types.AccountIdentifier,
 	return r0, r1, r2
 }
 
+// GetBlockEvents provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Indexer) GetBlockEvents(_a0 context.Context, _a1 *int64, _a2 int64) ([]*types.BlockEvent, int64, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*types.BlockEvent
+	if rf, ok := ret.Get(0).(func(context.Context, *int64, int64) []*types.BlockEvent); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.BlockEvent)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, *int64, int64) int64); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *int64, int64) error); ok {
+		r2 = rf(_a0, _a1, _a2)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBlockLazy provides a mock function with given fields: _a0, _a1
 func (_m *Indexer) GetBlockLazy(_a0 context.Context, _a1 *types.PartialBlockIdentifier) (*types.BlockResponse, error) {
 	ret := _m.Called(_a0, _a1)
@@ -95,13 +228,13 @@ func (_m *Indexer) GetBlockTransaction(_a0 context.Context, _a1 *types.BlockIden
 	return r0, r1
 }
 
-// GetCoins provides a mock function with given fields: _a0, _a1
-func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier) ([]*types.Coin, *types.BlockIdentifier, error) {
-	ret := _m.Called(_a0, _a1)
+// GetCoins provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 bool, _a3 *types.PartialBlockIdentifier) ([]*types.Coin, *types.BlockIdentifier, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	var r0 []*types.Coin
-	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier) []*types.Coin); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, bool, *types.PartialBlockIdentifier) []*types.Coin); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*types.Coin)
@@ -109,8 +242,8 @@ func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier) (
 	}
 
 	var r1 *types.BlockIdentifier
-	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier) *types.BlockIdentifier); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, bool, *types.PartialBlockIdentifier) *types.BlockIdentifier); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*types.BlockIdentifier)
@@ -118,8 +251,8 @@ func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier) (
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, *types.AccountIdentifier) error); ok {
-		r2 = rf(_a0, _a1)
+	if rf, ok := ret.Get(2).(func(context.Context, *types.AccountIdentifier, bool, *types.PartialBlockIdentifier) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -127,6 +260,52 @@ func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier) (
 	return r0, r1, r2
 }
 
+// GetMempoolBalance provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Indexer) GetMempoolBalance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.Currency) (*types.Amount, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *types.Amount
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, *types.Currency) *types.Amount); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Amount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, *types.Currency) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMempoolTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetMempoolTransaction(_a0 context.Context, _a1 *types.TransactionIdentifier) (*types.Transaction, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, *types.TransactionIdentifier) *types.Transaction); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.TransactionIdentifier) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetScriptPubKeys provides a mock function with given fields: _a0, _a1
 func (_m *Indexer) GetScriptPubKeys(_a0 context.Context, _a1 []*types.Coin) ([]*thought.ScriptPubKey, error) {
 	ret := _m.Called(_a0, _a1)
@@ -149,3 +328,49 @@ func (_m *Indexer) GetScriptPubKeys(_a0 context.Context, _a1 []*types.Coin) ([]*
 
 	return r0, r1
 }
+
+// GetStatistics provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Indexer) GetStatistics(_a0 context.Context, _a1 *types.Currency, _a2 int64) (*thought.Statistics, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *thought.Statistics
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Currency, int64) *thought.Statistics); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*thought.Statistics)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.Currency, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchTransactions provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Indexer) SearchTransactions(_a0 context.Context, _a1 *types.SearchTransactionsRequest, _a2 int64, _a3 int64) (*types.SearchTransactionsResponse, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 *types.SearchTransactionsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *types.SearchTransactionsRequest, int64, int64) *types.SearchTransactionsResponse); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.SearchTransactionsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.SearchTransactionsRequest, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -63,12 +63,44 @@ func (s *AccountAPIService) AccountBalance(
 		return nil, wrapErr(ErrUnableToGetBalance, err)
 	}
 
-	return &types.AccountBalanceResponse{
+	response := &types.AccountBalanceResponse{
 		BlockIdentifier: block,
 		Balances: []*types.Amount{
 			amount,
 		},
-	}, nil
+	}
+
+	// The mempool only applies to the current balance.
+	if request.BlockIdentifier != nil {
+		return response, nil
+	}
+
+	mempoolAmount, err := s.i.GetMempoolBalance(
+		ctx,
+		request.AccountIdentifier,
+		s.config.Currency,
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetBalance, err)
+	}
+
+	pendingValue, err := types.AddValues(amount.Value, mempoolAmount.Value)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetBalance, err)
+	}
+
+	metadata, err := types.MarshalMap(&AccountBalanceMetadata{
+		PendingBalance: &types.Amount{
+			Value:    pendingValue,
+			Currency: s.config.Currency,
+		},
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetBalance, err)
+	}
+	response.Metadata = metadata
+
+	return response, nil
 }
 
 // AccountCoins implements /account/coins.
@@ -82,11 +114,12 @@ func (s *AccountAPIService) AccountCoins(
 
 	// TODO: filter coins by request currencies
 
-	// TODO: support include_mempool query
-	// https://github.com/coinbase/rosetta-bitcoin/issues/36#issuecomment-724992022
-	// Once mempoolcoins are supported also change the bool service/types.go:MempoolCoins to true
-
-	coins, block, err := s.i.GetCoins(ctx, request.AccountIdentifier)
+	coins, block, err := s.i.GetCoins(
+		ctx,
+		request.AccountIdentifier,
+		request.IncludeMempool,
+		nil,
+	)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToGetCoins, err)
 	}
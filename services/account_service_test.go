@@ -72,6 +72,15 @@ func TestAccountBalance_Online_Current(t *testing.T) {
 		thought.MainnetCurrency,
 		(*types.PartialBlockIdentifier)(nil),
 	).Return(amount, block, nil).Once()
+	mockIndexer.On(
+		"GetMempoolBalance",
+		ctx,
+		account,
+		thought.MainnetCurrency,
+	).Return(&types.Amount{
+		Value:    "-10",
+		Currency: thought.MainnetCurrency,
+	}, nil).Once()
 	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
 		AccountIdentifier: account,
 	})
@@ -81,6 +90,12 @@ func TestAccountBalance_Online_Current(t *testing.T) {
 		Balances: []*types.Amount{
 			amount,
 		},
+		Metadata: forceMarshalMap(t, &AccountBalanceMetadata{
+			PendingBalance: &types.Amount{
+				Value:    "15",
+				Currency: thought.MainnetCurrency,
+			},
+		}),
 	}, bal)
 
 	mockIndexer.AssertExpectations(t)
@@ -174,7 +189,7 @@ func TestAccountCoins_Online(t *testing.T) {
 		Index: 1000,
 		Hash:  "block 1000",
 	}
-	mockIndexer.On("GetCoins", ctx, account).Return(coins, block, nil).Once()
+	mockIndexer.On("GetCoins", ctx, account, false, (*types.PartialBlockIdentifier)(nil)).Return(coins, block, nil).Once()
 
 	bal, err := servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
 		AccountIdentifier: account,
@@ -186,5 +201,26 @@ func TestAccountCoins_Online(t *testing.T) {
 		Coins:           coins,
 	}, bal)
 
+	mempoolCoins := append(coins[1:], &types.Coin{
+		Amount: &types.Amount{
+			Value: "5",
+		},
+		CoinIdentifier: &types.CoinIdentifier{
+			Identifier: "coin 4",
+		},
+	})
+	mockIndexer.On("GetCoins", ctx, account, true, (*types.PartialBlockIdentifier)(nil)).Return(mempoolCoins, block, nil).Once()
+
+	bal, err = servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
+		AccountIdentifier: account,
+		IncludeMempool:    true,
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           mempoolCoins,
+	}, bal)
+
 	mockIndexer.AssertExpectations(t)
 }
@@ -15,9 +15,16 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
 
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -101,3 +108,99 @@ func (s *BlockAPIService) BlockTransaction(
 		Transaction: transaction,
 	}, nil
 }
+
+// blockTransactionPath is the path
+// of the /block/transaction endpoint.
+const blockTransactionPath = "/block/transaction"
+
+// blockTransactionLookupHandler populates the block identifier of
+// /block/transaction requests that only provide a transaction
+// identifier (and optionally a block index), which the request
+// asserter would otherwise reject.
+type blockTransactionLookupHandler struct {
+	config *configuration.Configuration
+	i      Indexer
+	next   http.Handler
+}
+
+// wrapFindTransactionErr returns ErrTransactionNotFound if a
+// transaction is not indexed, ErrSearchIndexIncomplete if the
+// index does not start at genesis and ErrUnableToSearchTransactions
+// if the index could not be read.
+func wrapFindTransactionErr(err error) *types.Error {
+	if errors.Is(err, thought.ErrTransactionNotFound) {
+		return wrapErr(ErrTransactionNotFound, err)
+	}
+
+	if errors.Is(err, thought.ErrSearchIndexIncomplete) {
+		return wrapErr(ErrSearchIndexIncomplete, err)
+	}
+
+	return wrapErr(ErrUnableToSearchTransactions, err)
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *blockTransactionLookupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.config.Mode != configuration.Online || r.URL.Path != blockTransactionPath {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		server.EncodeJSONResponse(
+			wrapErr(ErrUnableToParseIntermediateResult, err),
+			http.StatusInternalServerError,
+			w,
+		)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Malformed requests are left for the
+	// controller to reject.
+	var request types.BlockTransactionRequest
+	if err := json.Unmarshal(body, &request); err != nil ||
+		request.TransactionIdentifier == nil ||
+		(request.BlockIdentifier != nil && len(request.BlockIdentifier.Hash) > 0) {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	blockIdentifier, _, err := h.i.FindTransaction(r.Context(), request.TransactionIdentifier)
+	if err != nil {
+		server.EncodeJSONResponse(wrapFindTransactionErr(err), http.StatusInternalServerError, w)
+		return
+	}
+
+	// A block index provided without a hash must
+	// match the block containing the transaction.
+	if request.BlockIdentifier != nil && request.BlockIdentifier.Index != blockIdentifier.Index {
+		server.EncodeJSONResponse(
+			wrapErr(ErrTransactionNotFound, fmt.Errorf(
+				"transaction %s is in block %d, not %d",
+				request.TransactionIdentifier.Hash,
+				blockIdentifier.Index,
+				request.BlockIdentifier.Index,
+			)),
+			http.StatusInternalServerError,
+			w,
+		)
+		return
+	}
+
+	request.BlockIdentifier = blockIdentifier
+	body, err = json.Marshal(request)
+	if err != nil {
+		server.EncodeJSONResponse(
+			wrapErr(ErrUnableToParseIntermediateResult, err),
+			http.StatusInternalServerError,
+			w,
+		)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	h.next.ServeHTTP(w, r)
+}
@@ -16,14 +16,21 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
 	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/services"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestBlockService_Offline(t *testing.T) {
@@ -211,3 +218,126 @@ func TestBlockService_Online_External(t *testing.T) {
 
 	mockIndexer.AssertExpectations(t)
 }
+
+func TestBlockService_TransactionLookup(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+
+	var forwarded string
+	handler := &blockTransactionLookupHandler{
+		config: cfg,
+		i:      mockIndexer,
+		next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			forwarded = string(body)
+		}),
+	}
+
+	serve := func(path string, body string) *httptest.ResponseRecorder {
+		forwarded = ""
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return w
+	}
+
+	// Requests with a block identifier are forwarded unchanged
+	body := `{"block_identifier":{"index":10,"hash":"block 10"},"transaction_identifier":{"hash":"tx 1"}}`
+	serve(blockTransactionPath, body)
+	assert.Equal(t, body, forwarded)
+
+	// Other endpoints are forwarded unchanged
+	body = `{"transaction_identifier":{"hash":"tx 1"}}`
+	serve("/block", body)
+	assert.Equal(t, body, forwarded)
+
+	// The block identifier is populated
+	transactionIdentifier := &types.TransactionIdentifier{
+		Hash: "tx 1",
+	}
+	mockIndexer.On(
+		"FindTransaction",
+		mock.Anything,
+		transactionIdentifier,
+	).Return(
+		&types.BlockIdentifier{
+			Index: 10,
+			Hash:  "block 10",
+		},
+		&types.Transaction{
+			TransactionIdentifier: transactionIdentifier,
+		},
+		nil,
+	).Times(3)
+	expected := `{"network_identifier":null,"block_identifier":{"index":10,"hash":"block 10"},"transaction_identifier":{"hash":"tx 1"}}`
+	serve(blockTransactionPath, body)
+	assert.JSONEq(t, expected, forwarded)
+
+	// A matching block index is completed with the hash
+	serve(blockTransactionPath, `{"block_identifier":{"index":10},"transaction_identifier":{"hash":"tx 1"}}`)
+	assert.JSONEq(t, expected, forwarded)
+
+	// A mismatched block index is rejected
+	w := serve(blockTransactionPath, `{"block_identifier":{"index":11},"transaction_identifier":{"hash":"tx 1"}}`)
+	assert.Equal(t, "", forwarded)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), ErrTransactionNotFound.Message)
+	assert.Contains(t, w.Body.String(), "transaction tx 1 is in block 10, not 11")
+
+	// Unknown transactions are not forwarded
+	mockIndexer.On(
+		"FindTransaction",
+		mock.Anything,
+		&types.TransactionIdentifier{
+			Hash: "tx 2",
+		},
+	).Return(
+		nil,
+		nil,
+		fmt.Errorf("%w: transaction tx 2 is not indexed", thought.ErrTransactionNotFound),
+	).Once()
+	w = serve(blockTransactionPath, `{"transaction_identifier":{"hash":"tx 2"}}`)
+	assert.Equal(t, "", forwarded)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), ErrTransactionNotFound.Message)
+
+	// Transactions are not reported as not found
+	// if the index does not start at genesis
+	mockIndexer.On(
+		"FindTransaction",
+		mock.Anything,
+		&types.TransactionIdentifier{
+			Hash: "tx 4",
+		},
+	).Return(
+		nil,
+		nil,
+		fmt.Errorf("%w: oldest indexed block is 5, not genesis", thought.ErrSearchIndexIncomplete),
+	).Once()
+	w = serve(blockTransactionPath, `{"transaction_identifier":{"hash":"tx 4"}}`)
+	assert.Equal(t, "", forwarded)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), ErrSearchIndexIncomplete.Message)
+	assert.NotContains(t, w.Body.String(), ErrTransactionNotFound.Message)
+
+	// Storage errors are not reported as not found
+	mockIndexer.On(
+		"FindTransaction",
+		mock.Anything,
+		&types.TransactionIdentifier{
+			Hash: "tx 3",
+		},
+	).Return(
+		nil,
+		nil,
+		errors.New("unable to scan search index"),
+	).Once()
+	w = serve(blockTransactionPath, `{"transaction_identifier":{"hash":"tx 3"}}`)
+	assert.Equal(t, "", forwarded)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), ErrUnableToSearchTransactions.Message)
+
+	mockIndexer.AssertExpectations(t)
+}
@@ -0,0 +1,544 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/util"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// CallAPIService implements the server.CallAPIServicer interface.
+type CallAPIService struct {
+	config *configuration.Configuration
+	i      Indexer
+
+	// construction is used to estimate
+	// transaction sizes and fee rates.
+	construction *ConstructionAPIService
+}
+
+// NewCallAPIService creates a new instance of a CallAPIService.
+func NewCallAPIService(
+	config *configuration.Configuration,
+	client Client,
+	i Indexer,
+) server.CallAPIServicer {
+	return &CallAPIService{
+		config: config,
+		i:      i,
+		construction: &ConstructionAPIService{
+			config: config,
+			client: client,
+			i:      i,
+		},
+	}
+}
+
+// Call implements the /call endpoint.
+func (s *CallAPIService) Call(
+	ctx context.Context,
+	request *types.CallRequest,
+) (*types.CallResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	var result interface{}
+	var rosettaErr *types.Error
+	switch request.Method {
+	case SelectCoinsMethod:
+		var parameters SelectCoinsParameters
+		if err := types.UnmarshalMap(request.Parameters, &parameters); err != nil {
+			return nil, wrapErr(ErrCallParametersInvalid, err)
+		}
+
+		result, rosettaErr = s.selectCoins(ctx, &parameters)
+	case FindTransactionMethod:
+		var parameters FindTransactionParameters
+		if err := types.UnmarshalMap(request.Parameters, &parameters); err != nil {
+			return nil, wrapErr(ErrCallParametersInvalid, err)
+		}
+
+		result, rosettaErr = s.findTransaction(ctx, &parameters)
+	case AccountCoinsMethod:
+		var parameters AccountCoinsParameters
+		if err := types.UnmarshalMap(request.Parameters, &parameters); err != nil {
+			return nil, wrapErr(ErrCallParametersInvalid, err)
+		}
+
+		result, rosettaErr = s.accountCoins(ctx, &parameters)
+	case BatchAccountsMethod:
+		var parameters BatchAccountsParameters
+		if err := types.UnmarshalMap(request.Parameters, &parameters); err != nil {
+			return nil, wrapErr(ErrCallParametersInvalid, err)
+		}
+
+		result, rosettaErr = s.batchAccounts(ctx, &parameters)
+	case ScanXpubMethod:
+		var parameters ScanXpubParameters
+		if err := types.UnmarshalMap(request.Parameters, &parameters); err != nil {
+			return nil, wrapErr(ErrCallParametersInvalid, err)
+		}
+
+		result, rosettaErr = s.scanXpub(ctx, &parameters)
+	case AccountHistoryMethod:
+		var parameters AccountHistoryParameters
+		if err := types.UnmarshalMap(request.Parameters, &parameters); err != nil {
+			return nil, wrapErr(ErrCallParametersInvalid, err)
+		}
+
+		result, rosettaErr = s.accountHistory(ctx, &parameters)
+	case StatisticsMethod:
+		var parameters StatisticsParameters
+		if err := types.UnmarshalMap(request.Parameters, &parameters); err != nil {
+			return nil, wrapErr(ErrCallParametersInvalid, err)
+		}
+
+		result, rosettaErr = s.statistics(ctx, &parameters)
+	default:
+		return nil, wrapErr(
+			ErrCallMethodInvalid,
+			fmt.Errorf("%s is not a supported method", request.Method),
+		)
+	}
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	resultMap, err := types.MarshalMap(result)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	// Results depend on the blocks and coins indexed
+	// so they may change between calls.
+	return &types.CallResponse{
+		Result:     resultMap,
+		Idempotent: false,
+	}, nil
+}
+
+// findTransaction returns a transaction in
+// the canonical chain and the block containing it.
+func (s *CallAPIService) findTransaction(
+	ctx context.Context,
+	parameters *FindTransactionParameters,
+) (*types.BlockTransaction, *types.Error) {
+	if parameters.TransactionIdentifier == nil ||
+		len(parameters.TransactionIdentifier.Hash) == 0 {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			errors.New("transaction identifier cannot be empty"),
+		)
+	}
+
+	blockIdentifier, transaction, err := s.i.FindTransaction(
+		ctx,
+		parameters.TransactionIdentifier,
+	)
+	if err != nil {
+		return nil, wrapFindTransactionErr(err)
+	}
+
+	return &types.BlockTransaction{
+		BlockIdentifier: blockIdentifier,
+		Transaction:     transaction,
+	}, nil
+}
+
+// accountCoins returns the coins owned by
+// an account at a particular block.
+func (s *CallAPIService) accountCoins(
+	ctx context.Context,
+	parameters *AccountCoinsParameters,
+) (*types.AccountCoinsResponse, *types.Error) {
+	if parameters.AccountIdentifier == nil {
+		return nil, wrapErr(ErrCallParametersInvalid, errors.New("account identifier cannot be nil"))
+	}
+
+	coins, block, err := s.i.GetCoins(
+		ctx,
+		parameters.AccountIdentifier,
+		false,
+		parameters.BlockIdentifier,
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	return &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           coins,
+	}, nil
+}
+
+// accountHistory returns a page of the
+// transactions of an account.
+func (s *CallAPIService) accountHistory(
+	ctx context.Context,
+	parameters *AccountHistoryParameters,
+) (*AccountHistoryResult, *types.Error) {
+	if parameters.AccountIdentifier == nil {
+		return nil, wrapErr(ErrCallParametersInvalid, errors.New("account identifier cannot be nil"))
+	}
+
+	limit := defaultHistoryLimit
+	if parameters.Limit > 0 {
+		limit = parameters.Limit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	if parameters.MinIndex != nil && parameters.MaxIndex != nil &&
+		*parameters.MinIndex > *parameters.MaxIndex {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			errors.New("min index cannot be greater than max index"),
+		)
+	}
+
+	transactions, nextCursor, err := s.i.GetAccountHistory(
+		ctx,
+		parameters.AccountIdentifier,
+		parameters.Cursor,
+		parameters.MinIndex,
+		parameters.MaxIndex,
+		limit,
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetHistory, err)
+	}
+
+	result := &AccountHistoryResult{
+		Transactions: make([]*AccountTransaction, len(transactions)),
+		NextCursor:   nextCursor,
+	}
+	for j, transaction := range transactions {
+		result.Transactions[j] = &AccountTransaction{
+			BlockIdentifier:       transaction.BlockIdentifier,
+			TransactionIdentifier: transaction.TransactionIdentifier,
+			NetAmount:             transaction.NetAmount,
+		}
+	}
+
+	return result, nil
+}
+
+// statistics returns the statistics of the unspent
+// coins and the addresses with the largest balances.
+func (s *CallAPIService) statistics(
+	ctx context.Context,
+	parameters *StatisticsParameters,
+) (*StatisticsResult, *types.Error) {
+	limit := defaultRichListLimit
+	if parameters.Limit != nil {
+		limit = *parameters.Limit
+	}
+	if limit < 0 {
+		return nil, wrapErr(ErrCallParametersInvalid, errors.New("limit cannot be negative"))
+	}
+	if limit > maxRichListLimit {
+		limit = maxRichListLimit
+	}
+
+	stats, err := s.i.GetStatistics(ctx, s.config.Currency, limit)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetStatistics, err)
+	}
+
+	richList := make([]*AccountState, len(stats.RichList))
+	for j, account := range stats.RichList {
+		richList[j] = &AccountState{
+			AccountIdentifier: account.AccountIdentifier,
+			Balance:           account.Balance,
+		}
+	}
+
+	return &StatisticsResult{
+		BlockIdentifier:    stats.BlockIdentifier,
+		Supply:             stats.Supply,
+		CoinCount:          stats.CoinCount,
+		FundedAddressCount: stats.AddressCount,
+		RichList:           richList,
+	}, nil
+}
+
+// batchAccounts returns the balances and/or
+// coins of many accounts at a single block.
+func (s *CallAPIService) batchAccounts(
+	ctx context.Context,
+	parameters *BatchAccountsParameters,
+) (*BatchAccountsResult, *types.Error) {
+	if len(parameters.AccountIdentifiers) == 0 {
+		return nil, wrapErr(ErrCallParametersInvalid, errors.New("account identifiers cannot be empty"))
+	}
+
+	if len(parameters.AccountIdentifiers) > maxBatchAccounts {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			fmt.Errorf("at most %d account identifiers can be provided", maxBatchAccounts),
+		)
+	}
+
+	for i, accountIdentifier := range parameters.AccountIdentifiers {
+		if accountIdentifier == nil {
+			return nil, wrapErr(ErrCallParametersInvalid, fmt.Errorf("account identifier %d is nil", i))
+		}
+	}
+
+	if !parameters.IncludeBalances && !parameters.IncludeCoins {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			errors.New("include_balances or include_coins must be set"),
+		)
+	}
+
+	balances, coins, block, err := s.i.GetAccounts(
+		ctx,
+		parameters.AccountIdentifiers,
+		s.config.Currency,
+		parameters.IncludeBalances,
+		parameters.IncludeCoins,
+		parameters.BlockIdentifier,
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetBalance, err)
+	}
+
+	accounts := make([]*AccountState, len(parameters.AccountIdentifiers))
+	for i, accountIdentifier := range parameters.AccountIdentifiers {
+		accounts[i] = &AccountState{
+			AccountIdentifier: accountIdentifier,
+		}
+
+		if parameters.IncludeBalances {
+			accounts[i].Balance = balances[i]
+		}
+
+		if parameters.IncludeCoins {
+			accounts[i].Coins = coins[i]
+		}
+	}
+
+	return &BatchAccountsResult{
+		BlockIdentifier: block,
+		Accounts:        accounts,
+	}, nil
+}
+
+// selectCoins selects coins owned by an account to fund
+// a set of outputs at the suggested fee rate and returns
+// the operations of the resulting transaction.
+func (s *CallAPIService) selectCoins(
+	ctx context.Context,
+	parameters *SelectCoinsParameters,
+) (*SelectCoinsResult, *types.Error) {
+	if parameters.Account == nil {
+		return nil, wrapErr(ErrCallParametersInvalid, errors.New("account cannot be nil"))
+	}
+
+	// Fees are estimated with the size of P2PKH inputs and the
+	// returned INPUT operations have no redeem script, so coins
+	// can only be selected for P2PKH accounts.
+	addr, err := util.DecodeAddress(parameters.Account.Address, s.config.Params)
+	if err != nil {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			fmt.Errorf("%w unable to decode account %s", err, parameters.Account.Address),
+		)
+	}
+
+	if _, ok := addr.(*util.AddressPubKeyHash); !ok || !addr.IsForNet(s.config.Params) {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			fmt.Errorf("account %s is not a P2PKH address", parameters.Account.Address),
+		)
+	}
+
+	if len(parameters.Outputs) == 0 {
+		return nil, wrapErr(ErrCallParametersInvalid, errors.New("outputs cannot be empty"))
+	}
+
+	strategy := parameters.Strategy
+	if len(strategy) == 0 {
+		strategy = LargestFirstStrategy
+	}
+
+	selector, ok := coinSelectors[strategy]
+	if !ok {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			fmt.Errorf("%s is not a supported strategy", strategy),
+		)
+	}
+
+	outputsValue := int64(0)
+	for i, output := range parameters.Outputs {
+		if output.Type != thought.OutputOpType || output.Amount == nil {
+			return nil, wrapErr(
+				ErrCallParametersInvalid,
+				fmt.Errorf("output %d must be an %s operation with an amount", i, thought.OutputOpType),
+			)
+		}
+
+		if _, rosettaErr := s.construction.outputScript(output); rosettaErr != nil {
+			return nil, rosettaErr
+		}
+
+		value, err := strconv.ParseInt(output.Amount.Value, 10, 64)
+		if err != nil || value < 0 {
+			return nil, wrapErr(
+				ErrCallParametersInvalid,
+				fmt.Errorf("output %d amount %s is invalid", i, output.Amount.Value),
+			)
+		}
+
+		outputsValue += value
+	}
+
+	changeAddress := parameters.ChangeAddress
+	if len(changeAddress) == 0 {
+		changeAddress = parameters.Account.Address
+	}
+
+	change := &types.Operation{
+		Type: thought.OutputOpType,
+		Account: &types.AccountIdentifier{
+			Address: changeAddress,
+		},
+	}
+	if _, rosettaErr := s.construction.outputScript(change); rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	notionsPerB, rosettaErr := s.construction.feeRate(ctx, parameters.FeeMultiplier)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	// Round fees up so the transaction pays at least the fee
+	// suggested by /construction/metadata. The rate is converted
+	// to Notions per KB to avoid floating point rounding errors.
+	notionsPerKB := int64(math.Round(notionsPerB * bytesInKb))
+	fee := func(size float64) int64 {
+		return (int64(size)*notionsPerKB + int64(bytesInKb) - 1) / int64(bytesInKb)
+	}
+	inputFee := fee(float64(thought.InputSize))
+	changeFee := fee(s.construction.estimateSize([]*types.Operation{change}) -
+		thought.TransactionOverhead)
+	target := &selectionTarget{
+		value:        outputsValue + fee(s.construction.estimateSize(parameters.Outputs)),
+		costOfChange: changeFee + inputFee,
+	}
+
+	// Coins spent by transactions in the mempool cannot be
+	// selected again, while coins they create can be.
+	coins, _, err := s.i.GetCoins(ctx, parameters.Account, true, nil)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	candidates := make([]*selectionCoin, len(coins))
+	for i, coin := range coins {
+		value, err := strconv.ParseInt(coin.Amount.Value, 10, 64)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToGetCoins, err)
+		}
+
+		candidates[i] = &selectionCoin{
+			coin:           coin,
+			value:          value,
+			effectiveValue: value - inputFee,
+		}
+	}
+
+	selected := selector(candidates, target)
+	if selected == nil {
+		return nil, wrapErr(
+			ErrInsufficientFunds,
+			fmt.Errorf("coins of %s cannot fund %d", parameters.Account.Address, target.value),
+		)
+	}
+
+	operations := []*types.Operation{}
+	inputsValue := int64(0)
+	effectiveValue := int64(0)
+	for _, coin := range selected {
+		inputsValue += coin.value
+		effectiveValue += coin.effectiveValue
+		operations = append(operations, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: int64(len(operations)),
+			},
+			Type:    thought.InputOpType,
+			Account: parameters.Account,
+			Amount: &types.Amount{
+				Value:    strconv.FormatInt(-coin.value, 10),
+				Currency: s.config.Currency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coin.coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		})
+	}
+
+	for _, output := range parameters.Outputs {
+		operations = append(operations, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: int64(len(operations)),
+			},
+			Type:     output.Type,
+			Account:  output.Account,
+			Amount:   output.Amount,
+			Metadata: output.Metadata,
+		})
+	}
+
+	// Change below the dust threshold is added to the fee
+	// because thoughtd will not relay a transaction with it.
+	changeValue := effectiveValue - target.value - changeFee
+	if changeValue >= thought.DustThreshold {
+		change.OperationIdentifier = &types.OperationIdentifier{
+			Index: int64(len(operations)),
+		}
+		change.Amount = &types.Amount{
+			Value:    strconv.FormatInt(changeValue, 10),
+			Currency: s.config.Currency,
+		}
+		operations = append(operations, change)
+	} else {
+		changeValue = 0
+	}
+
+	return &SelectCoinsResult{
+		Operations: operations,
+		Fee: &types.Amount{
+			Value:    strconv.FormatInt(inputsValue-outputsValue-changeValue, 10),
+			Currency: s.config.Currency,
+		},
+	}, nil
+}
@@ -0,0 +1,1086 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/services"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCall_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Offline,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	resp, err := servicer.Call(ctx, &types.CallRequest{Method: SelectCoinsMethod})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCall_InvalidMethod(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	resp, err := servicer.Call(ctx, &types.CallRequest{Method: "blah"})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallMethodInvalid.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCall_SelectCoins(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+	ctx := context.Background()
+	account := &types.AccountIdentifier{
+		Address: "kyw8MaocLYCniZ3NnJqNST3qtZNygLSiCC",
+	}
+	output := &types.Operation{
+		Type: thought.OutputOpType,
+		Account: &types.AccountIdentifier{
+			Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU",
+		},
+		Amount: &types.Amount{
+			Value:    "100000",
+			Currency: thought.TestnetCurrency,
+		},
+	}
+
+	coin := func(identifier string, value string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: identifier,
+			},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: thought.TestnetCurrency,
+			},
+		}
+	}
+	coins := []*types.Coin{
+		coin("coin1:0", "50000"),
+		coin("coin2:0", "80000"),
+		coin("coin3:0", "30000"),
+		coin("coin4:0", "20340"),
+	}
+
+	input := func(index int64, c *types.Coin) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: index,
+			},
+			Type:    thought.InputOpType,
+			Account: account,
+			Amount: &types.Amount{
+				Value:    "-" + c.Amount.Value,
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: c.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		}
+	}
+	outputAt := func(index int64) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: index,
+			},
+			Type:    output.Type,
+			Account: output.Account,
+			Amount:  output.Amount,
+		}
+	}
+
+	// At the minimum fee rate of 1 Notion per byte, the outputs
+	// and overhead cost 44, each input 148 and change 34.
+	tests := map[string]struct {
+		parameters *SelectCoinsParameters
+
+		// spendable are the coins that are not spent in
+		// the mempool (all coins if not populated).
+		spendable []*types.Coin
+
+		result *SelectCoinsResult
+		err    *types.Error
+	}{
+		"largest first": {
+			parameters: &SelectCoinsParameters{
+				Account: account,
+				Outputs: []*types.Operation{output},
+			},
+			result: &SelectCoinsResult{
+				Operations: []*types.Operation{
+					input(0, coins[1]),
+					input(1, coins[0]),
+					outputAt(2),
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index: 3,
+						},
+						Type:    thought.OutputOpType,
+						Account: account,
+						Amount: &types.Amount{
+							Value:    "29626",
+							Currency: thought.TestnetCurrency,
+						},
+					},
+				},
+				Fee: &types.Amount{
+					Value:    "374",
+					Currency: thought.TestnetCurrency,
+				},
+			},
+		},
+		"branch and bound": {
+			parameters: &SelectCoinsParameters{
+				Account:  account,
+				Outputs:  []*types.Operation{output},
+				Strategy: BranchAndBoundStrategy,
+			},
+			result: &SelectCoinsResult{
+				Operations: []*types.Operation{
+					input(0, coins[1]),
+					input(1, coins[3]),
+					outputAt(2),
+				},
+				Fee: &types.Amount{
+					Value:    "340",
+					Currency: thought.TestnetCurrency,
+				},
+			},
+		},
+		"coin spent in mempool": {
+			parameters: &SelectCoinsParameters{
+				Account: account,
+				Outputs: []*types.Operation{output},
+			},
+			spendable: []*types.Coin{coins[1], coins[2], coins[3]},
+			result: &SelectCoinsResult{
+				Operations: []*types.Operation{
+					input(0, coins[1]),
+					input(1, coins[2]),
+					outputAt(2),
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index: 3,
+						},
+						Type:    thought.OutputOpType,
+						Account: account,
+						Amount: &types.Amount{
+							Value:    "9626",
+							Currency: thought.TestnetCurrency,
+						},
+					},
+				},
+				Fee: &types.Amount{
+					Value:    "374",
+					Currency: thought.TestnetCurrency,
+				},
+			},
+		},
+		"insufficient funds": {
+			parameters: &SelectCoinsParameters{
+				Account: account,
+				Outputs: []*types.Operation{
+					{
+						Type:    thought.OutputOpType,
+						Account: output.Account,
+						Amount: &types.Amount{
+							Value:    "180000",
+							Currency: thought.TestnetCurrency,
+						},
+					},
+				},
+			},
+			err: ErrInsufficientFunds,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockIndexer := &mocks.Indexer{}
+			mockClient := &mocks.Client{}
+			servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+
+			mockClient.On(
+				"SuggestedFeeRate",
+				ctx,
+				defaultConfirmationTarget,
+			).Return(
+				thought.MinFeeRate,
+				nil,
+			).Once()
+			spendable := coins
+			if test.spendable != nil {
+				spendable = test.spendable
+			}
+			mockIndexer.On(
+				"GetCoins",
+				ctx,
+				account,
+				true,
+				(*types.PartialBlockIdentifier)(nil),
+			).Return(
+				spendable,
+				&types.BlockIdentifier{Index: 1, Hash: "block 1"},
+				nil,
+			).Once()
+
+			resp, err := servicer.Call(ctx, &types.CallRequest{
+				Method:     SelectCoinsMethod,
+				Parameters: forceMarshalMap(t, test.parameters),
+			})
+			if test.err != nil {
+				assert.Nil(t, resp)
+				assert.Equal(t, test.err.Code, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, forceMarshalMap(t, test.result), resp.Result)
+			}
+
+			mockClient.AssertExpectations(t)
+			mockIndexer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCall_SelectCoinsInvalidParameters(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "kyw8MaocLYCniZ3NnJqNST3qtZNygLSiCC",
+	}
+	output := &types.Operation{
+		Type: thought.OutputOpType,
+		Account: &types.AccountIdentifier{
+			Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU",
+		},
+		Amount: &types.Amount{
+			Value:    "100000",
+			Currency: thought.TestnetCurrency,
+		},
+	}
+
+	tests := map[string]*SelectCoinsParameters{
+		"missing account": {
+			Outputs: []*types.Operation{output},
+		},
+		"missing outputs": {
+			Account: account,
+		},
+		"p2sh account": {
+			Account: &types.AccountIdentifier{
+				Address: "2M17aDpXzXfBYASFUJkY9KnrtjeDgAn5vFT",
+			},
+			Outputs: []*types.Operation{output},
+		},
+		"p2pk account": {
+			Account: &types.AccountIdentifier{
+				Address: "03462779ad4aad39514614751a71085f2f10e1c7a593e4e030efb5b8721ce55b0b",
+			},
+			Outputs: []*types.Operation{output},
+		},
+		"mainnet account": {
+			Account: &types.AccountIdentifier{
+				Address: "3pTXmhvm2P7H7WbaNYTMkEQTQDitRkBp1P",
+			},
+			Outputs: []*types.Operation{output},
+		},
+		"invalid account": {
+			Account: &types.AccountIdentifier{
+				Address: "invalid",
+			},
+			Outputs: []*types.Operation{output},
+		},
+		"invalid strategy": {
+			Account:  account,
+			Outputs:  []*types.Operation{output},
+			Strategy: "smallest_first",
+		},
+		"invalid output type": {
+			Account: account,
+			Outputs: []*types.Operation{
+				{
+					Type:    thought.InputOpType,
+					Account: output.Account,
+					Amount:  output.Amount,
+				},
+			},
+		},
+		"negative output": {
+			Account: account,
+			Outputs: []*types.Operation{
+				{
+					Type:    thought.OutputOpType,
+					Account: output.Account,
+					Amount: &types.Amount{
+						Value:    "-1",
+						Currency: thought.TestnetCurrency,
+					},
+				},
+			},
+		},
+	}
+
+	for name, parameters := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := servicer.Call(ctx, &types.CallRequest{
+				Method:     SelectCoinsMethod,
+				Parameters: forceMarshalMap(t, parameters),
+			})
+			assert.Nil(t, resp)
+			assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+		})
+	}
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCall_FindTransaction(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	blockIdentifier := &types.BlockIdentifier{
+		Index: 10,
+		Hash:  "block 10",
+	}
+	transactionIdentifier := &types.TransactionIdentifier{
+		Hash: "tx 1",
+	}
+	transaction := &types.Transaction{
+		TransactionIdentifier: transactionIdentifier,
+		Operations:            []*types.Operation{},
+	}
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		transactionIdentifier,
+	).Return(
+		blockIdentifier,
+		transaction,
+		nil,
+	).Once()
+
+	resp, err := servicer.Call(ctx, &types.CallRequest{
+		Method: FindTransactionMethod,
+		Parameters: map[string]interface{}{
+			"transaction_identifier": map[string]interface{}{
+				"hash": "tx 1",
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.False(t, resp.Idempotent)
+
+	var result types.BlockTransaction
+	assert.NoError(t, types.UnmarshalMap(resp.Result, &result))
+	assert.Equal(t, blockIdentifier, result.BlockIdentifier)
+	assert.Equal(t, transaction, result.Transaction)
+
+	// Not found
+	missing := &types.TransactionIdentifier{
+		Hash: "tx 2",
+	}
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		missing,
+	).Return(
+		nil,
+		nil,
+		fmt.Errorf("%w: transaction tx 2 is not indexed", thought.ErrTransactionNotFound),
+	).Once()
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: FindTransactionMethod,
+		Parameters: map[string]interface{}{
+			"transaction_identifier": map[string]interface{}{
+				"hash": "tx 2",
+			},
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+
+	// Storage errors are not reported as not found
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		missing,
+	).Return(
+		nil,
+		nil,
+		errors.New("unable to scan search index"),
+	).Once()
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: FindTransactionMethod,
+		Parameters: map[string]interface{}{
+			"transaction_identifier": map[string]interface{}{
+				"hash": "tx 2",
+			},
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnableToSearchTransactions.Code, err.Code)
+
+	// Missing transaction identifier
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method:     FindTransactionMethod,
+		Parameters: map[string]interface{}{},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCall_AccountCoins(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+	blockIdentifier := &types.PartialBlockIdentifier{
+		Index: types.Int64(10),
+	}
+	block := &types.BlockIdentifier{
+		Index: 10,
+		Hash:  "block 10",
+	}
+	coins := []*types.Coin{
+		{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 1",
+			},
+			Amount: &types.Amount{
+				Value:    "10",
+				Currency: thought.MainnetCurrency,
+			},
+		},
+	}
+	mockIndexer.On(
+		"GetCoins",
+		ctx,
+		account,
+		false,
+		blockIdentifier,
+	).Return(
+		coins,
+		block,
+		nil,
+	).Once()
+
+	resp, err := servicer.Call(ctx, &types.CallRequest{
+		Method: AccountCoinsMethod,
+		Parameters: map[string]interface{}{
+			"account_identifier": map[string]interface{}{
+				"address": "hello",
+			},
+			"block_identifier": map[string]interface{}{
+				"index": 10,
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	var result types.AccountCoinsResponse
+	assert.NoError(t, types.UnmarshalMap(resp.Result, &result))
+	assert.Equal(t, &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           coins,
+	}, &result)
+
+	// Missing account identifier
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method:     AccountCoinsMethod,
+		Parameters: map[string]interface{}{},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCall_BatchAccounts(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Currency: thought.MainnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	accounts := []*types.AccountIdentifier{
+		{Address: "addr1"},
+		{Address: "addr2"},
+	}
+	block := &types.BlockIdentifier{
+		Index: 10,
+		Hash:  "block 10",
+	}
+	balances := []*types.Amount{
+		{Value: "10", Currency: thought.MainnetCurrency},
+		{Value: "0", Currency: thought.MainnetCurrency},
+	}
+	coins := [][]*types.Coin{
+		{
+			{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "coin 1",
+				},
+				Amount: balances[0],
+			},
+		},
+		{},
+	}
+	mockIndexer.On(
+		"GetAccounts",
+		ctx,
+		accounts,
+		thought.MainnetCurrency,
+		true,
+		true,
+		(*types.PartialBlockIdentifier)(nil),
+	).Return(
+		balances,
+		coins,
+		block,
+		nil,
+	).Once()
+
+	parameters := map[string]interface{}{
+		"account_identifiers": []interface{}{
+			map[string]interface{}{"address": "addr1"},
+			map[string]interface{}{"address": "addr2"},
+		},
+		"include_balances": true,
+		"include_coins":    true,
+	}
+	resp, err := servicer.Call(ctx, &types.CallRequest{
+		Method:     BatchAccountsMethod,
+		Parameters: parameters,
+	})
+	assert.Nil(t, err)
+
+	var result BatchAccountsResult
+	assert.NoError(t, types.UnmarshalMap(resp.Result, &result))
+	assert.Equal(t, &BatchAccountsResult{
+		BlockIdentifier: block,
+		Accounts: []*AccountState{
+			{
+				AccountIdentifier: accounts[0],
+				Balance:           balances[0],
+				Coins:             coins[0],
+			},
+			{
+				AccountIdentifier: accounts[1],
+				Balance:           balances[1],
+				Coins:             coins[1],
+			},
+		},
+	}, &result)
+
+	// Nothing to include
+	delete(parameters, "include_balances")
+	delete(parameters, "include_coins")
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method:     BatchAccountsMethod,
+		Parameters: parameters,
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	// Too many accounts
+	tooMany := make([]interface{}, maxBatchAccounts+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]interface{}{"address": "addr1"}
+	}
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: BatchAccountsMethod,
+		Parameters: map[string]interface{}{
+			"account_identifiers": tooMany,
+			"include_balances":    true,
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCall_ScanXpub(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	// The BIP32 test vector 1 key m/0H with testnet magic bytes
+	xpub := "LoBz4NyCak3EYnSWUPpZZbE2YSeCBE6RDYdTxzyrT6zZYG6zEFcrQDmpsDaUeQka3jdiMCBw9RqVpVoD8DUD3v2Fdis8DjojPT9iJukDMWQ1DhuB"
+	receive := []string{
+		"m3mSCWMxoAQFUgHb3eZyzXbqyYaqwBPSUi",
+		"m2rCeWMc9AaqYLcTRAqDonN6V3EaSMpMdo",
+		"m4s6FNVrSdxa6ktFCUqS2GMmGacrcyEJMf",
+	}
+	change := []string{
+		"m9vbyS69oFRHShgF3Diy5GJvf8JeoaiHpj",
+		"kw7hAuYXhS86zJcuXgJhD7TuJdzkkre8wc",
+	}
+
+	// receive/0 and receive/2 are found in the search index
+	// and change/0 only has a balance. The search index does
+	// not cover the change chain, so only balances are checked.
+	isChange := func(address string) bool {
+		return address == change[0] || address == change[1]
+	}
+	mockIndexer.On(
+		"SearchTransactions",
+		ctx,
+		mock.Anything,
+		int64(0),
+		int64(1),
+	).Return(
+		func(
+			ctx context.Context,
+			request *types.SearchTransactionsRequest,
+			offset int64,
+			limit int64,
+		) *types.SearchTransactionsResponse {
+			if isChange(*request.Address) {
+				return nil
+			}
+
+			if *request.Address == receive[0] || *request.Address == receive[2] {
+				return &types.SearchTransactionsResponse{TotalCount: 1}
+			}
+
+			return &types.SearchTransactionsResponse{TotalCount: 0}
+		},
+		func(
+			ctx context.Context,
+			request *types.SearchTransactionsRequest,
+			offset int64,
+			limit int64,
+		) error {
+			if isChange(*request.Address) {
+				return fmt.Errorf("%w: oldest indexed block is 5, not genesis", thought.ErrSearchIndexIncomplete)
+			}
+
+			return nil
+		},
+	)
+	mockIndexer.On(
+		"GetBalance",
+		ctx,
+		mock.Anything,
+		thought.TestnetCurrency,
+		(*types.PartialBlockIdentifier)(nil),
+	).Return(
+		func(
+			ctx context.Context,
+			account *types.AccountIdentifier,
+			currency *types.Currency,
+			block *types.PartialBlockIdentifier,
+		) *types.Amount {
+			value := "0"
+			if account.Address == change[0] {
+				value = "5"
+			}
+
+			return &types.Amount{Value: value, Currency: currency}
+		},
+		nil,
+		nil,
+	)
+
+	block := &types.BlockIdentifier{
+		Index: 10,
+		Hash:  "block 10",
+	}
+	amount := func(value string) *types.Amount {
+		return &types.Amount{Value: value, Currency: thought.TestnetCurrency}
+	}
+	coin := &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: "coin 1"},
+		Amount:         amount("5"),
+	}
+	mockIndexer.On(
+		"GetAccounts",
+		ctx,
+		[]*types.AccountIdentifier{
+			{Address: receive[0]},
+			{Address: receive[2]},
+			{Address: change[0]},
+		},
+		thought.TestnetCurrency,
+		true,
+		true,
+		(*types.PartialBlockIdentifier)(nil),
+	).Return(
+		[]*types.Amount{amount("0"), amount("10"), amount("5")},
+		[][]*types.Coin{{}, {}, {coin}},
+		block,
+		nil,
+	).Once()
+
+	resp, err := servicer.Call(ctx, &types.CallRequest{
+		Method: ScanXpubMethod,
+		Parameters: map[string]interface{}{
+			"xpub":      xpub,
+			"gap_limit": 2,
+		},
+	})
+	assert.Nil(t, err)
+
+	var result ScanXpubResult
+	assert.NoError(t, types.UnmarshalMap(resp.Result, &result))
+	assert.Equal(t, &ScanXpubResult{
+		BlockIdentifier: block,
+		Balance:         amount("15"),
+		Coins:           []*types.Coin{coin},
+		Addresses: []*XpubAddress{
+			{Address: receive[0], Chain: ReceiveChain, Index: 0, Balance: amount("0")},
+			{Address: receive[2], Chain: ReceiveChain, Index: 2, Balance: amount("10")},
+			{Address: change[0], Chain: ChangeChain, Index: 0, Balance: amount("5")},
+		},
+		NextReceiveAddress: &XpubAddress{Address: receive[1], Chain: ReceiveChain, Index: 1},
+		NextChangeAddress:  &XpubAddress{Address: change[1], Chain: ChangeChain, Index: 1},
+	}, &result)
+
+	// Invalid extended key
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: ScanXpubMethod,
+		Parameters: map[string]interface{}{
+			"xpub": receive[0],
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	// Extended keys of other networks are rejected
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: ScanXpubMethod,
+		Parameters: map[string]interface{}{
+			"xpub": "LJVu9KB6TGUsKHD6HNro6fupKTVh9mSAkyC1c2x5CnTbxe63j841svMM34txUt1od92AqwwrmTZVZLczF8dcsiLcvNj93Cr9dwpiWDoqA2f2QAx1",
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	// Gap limit above the maximum
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: ScanXpubMethod,
+		Parameters: map[string]interface{}{
+			"xpub":      xpub,
+			"gap_limit": maxGapLimit + 1,
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCall_AccountHistory(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Currency: thought.MainnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{Address: "addr1"}
+	transactions := []*thought.HistoryTransaction{
+		{
+			BlockIdentifier:       &types.BlockIdentifier{Index: 10, Hash: "block 10"},
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx 2"},
+			NetAmount:             &types.Amount{Value: "-40", Currency: thought.MainnetCurrency},
+		},
+		{
+			BlockIdentifier:       &types.BlockIdentifier{Index: 8, Hash: "block 8"},
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx 1"},
+			NetAmount:             &types.Amount{Value: "100", Currency: thought.MainnetCurrency},
+		},
+	}
+
+	// First page with the default limit
+	mockIndexer.On(
+		"GetAccountHistory",
+		ctx,
+		account,
+		"",
+		(*int64)(nil),
+		(*int64)(nil),
+		defaultHistoryLimit,
+	).Return(
+		transactions,
+		"cursor",
+		nil,
+	).Once()
+	resp, err := servicer.Call(ctx, &types.CallRequest{
+		Method: AccountHistoryMethod,
+		Parameters: map[string]interface{}{
+			"account_identifier": map[string]interface{}{"address": "addr1"},
+		},
+	})
+	assert.Nil(t, err)
+
+	var result AccountHistoryResult
+	assert.NoError(t, types.UnmarshalMap(resp.Result, &result))
+	assert.Equal(t, &AccountHistoryResult{
+		Transactions: []*AccountTransaction{
+			{
+				BlockIdentifier:       transactions[0].BlockIdentifier,
+				TransactionIdentifier: transactions[0].TransactionIdentifier,
+				NetAmount:             transactions[0].NetAmount,
+			},
+			{
+				BlockIdentifier:       transactions[1].BlockIdentifier,
+				TransactionIdentifier: transactions[1].TransactionIdentifier,
+				NetAmount:             transactions[1].NetAmount,
+			},
+		},
+		NextCursor: "cursor",
+	}, &result)
+
+	// Next page in a height range with a limit
+	// above the maximum
+	minIndex := int64(5)
+	maxIndex := int64(9)
+	mockIndexer.On(
+		"GetAccountHistory",
+		ctx,
+		account,
+		"cursor",
+		&minIndex,
+		&maxIndex,
+		maxHistoryLimit,
+	).Return(
+		[]*thought.HistoryTransaction{},
+		"",
+		nil,
+	).Once()
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: AccountHistoryMethod,
+		Parameters: map[string]interface{}{
+			"account_identifier": map[string]interface{}{"address": "addr1"},
+			"cursor":             "cursor",
+			"limit":              maxHistoryLimit + 1,
+			"min_index":          minIndex,
+			"max_index":          maxIndex,
+		},
+	})
+	assert.Nil(t, err)
+
+	result = AccountHistoryResult{}
+	assert.NoError(t, types.UnmarshalMap(resp.Result, &result))
+	assert.Equal(t, &AccountHistoryResult{
+		Transactions: []*AccountTransaction{},
+	}, &result)
+
+	// Invalid cursor
+	mockIndexer.On(
+		"GetAccountHistory",
+		ctx,
+		account,
+		"invalid",
+		(*int64)(nil),
+		(*int64)(nil),
+		defaultHistoryLimit,
+	).Return(
+		nil,
+		"",
+		errors.New("history cursor invalid is invalid"),
+	).Once()
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: AccountHistoryMethod,
+		Parameters: map[string]interface{}{
+			"account_identifier": map[string]interface{}{"address": "addr1"},
+			"cursor":             "invalid",
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnableToGetHistory.Code, err.Code)
+
+	// Inverted height range
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: AccountHistoryMethod,
+		Parameters: map[string]interface{}{
+			"account_identifier": map[string]interface{}{"address": "addr1"},
+			"min_index":          maxIndex,
+			"max_index":          minIndex,
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	// Missing account
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method:     AccountHistoryMethod,
+		Parameters: map[string]interface{}{},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestCall_Statistics(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Currency: thought.MainnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	block := &types.BlockIdentifier{Index: 10, Hash: "block 10"}
+	supply := &types.Amount{Value: "110", Currency: thought.MainnetCurrency}
+	accounts := []*types.AccountIdentifier{
+		{Address: "addr2"},
+		{Address: "addr1"},
+	}
+	balances := []*types.Amount{
+		{Value: "60", Currency: thought.MainnetCurrency},
+		{Value: "50", Currency: thought.MainnetCurrency},
+	}
+	mockIndexer.On(
+		"GetStatistics",
+		ctx,
+		thought.MainnetCurrency,
+		defaultRichListLimit,
+	).Return(
+		&thought.Statistics{
+			BlockIdentifier: block,
+			Supply:          supply,
+			CoinCount:       3,
+			AddressCount:    2,
+			RichList: []*thought.AccountBalance{
+				{AccountIdentifier: accounts[0], Balance: balances[0]},
+				{AccountIdentifier: accounts[1], Balance: balances[1]},
+			},
+		},
+		nil,
+	).Once()
+	resp, err := servicer.Call(ctx, &types.CallRequest{
+		Method:     StatisticsMethod,
+		Parameters: map[string]interface{}{},
+	})
+	assert.Nil(t, err)
+
+	var result StatisticsResult
+	assert.NoError(t, types.UnmarshalMap(resp.Result, &result))
+	assert.Equal(t, &StatisticsResult{
+		BlockIdentifier:    block,
+		Supply:             supply,
+		CoinCount:          3,
+		FundedAddressCount: 2,
+		RichList: []*AccountState{
+			{AccountIdentifier: accounts[0], Balance: balances[0]},
+			{AccountIdentifier: accounts[1], Balance: balances[1]},
+		},
+	}, &result)
+
+	// Limit above the maximum
+	mockIndexer.On(
+		"GetStatistics",
+		ctx,
+		thought.MainnetCurrency,
+		maxRichListLimit,
+	).Return(
+		nil,
+		errors.New("statistics do not start at genesis"),
+	).Once()
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: StatisticsMethod,
+		Parameters: map[string]interface{}{
+			"limit": maxRichListLimit + 1,
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnableToGetStatistics.Code, err.Code)
+
+	// Negative limit
+	resp, err = servicer.Call(ctx, &types.CallRequest{
+		Method: StatisticsMethod,
+		Parameters: map[string]interface{}{
+			"limit": -1,
+		},
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// maxBranchAndBoundTries is the maximum number of
+	// branches visited by selectBranchAndBound before
+	// it returns the best selection found.
+	maxBranchAndBoundTries = 100000
+)
+
+// selectionCoin is a coin that can be selected
+// to fund a transaction.
+type selectionCoin struct {
+	coin  *types.Coin
+	value int64
+
+	// effectiveValue is the value of the coin
+	// less the fee to spend it.
+	effectiveValue int64
+}
+
+// selectionTarget is the effective value that
+// selected coins must add up to.
+type selectionTarget struct {
+	// value is the value of the outputs plus the fee of
+	// the transaction without any inputs or change.
+	value int64
+
+	// costOfChange is the fee to create a change
+	// output and to later spend it.
+	costOfChange int64
+}
+
+// coinSelector returns coins with a total effective value of at
+// least target.value, or nil if the coins are insufficient.
+type coinSelector func(coins []*selectionCoin, target *selectionTarget) []*selectionCoin
+
+// coinSelectors are the coinSelector of each
+// supported coin selection strategy.
+var coinSelectors = map[string]coinSelector{
+	LargestFirstStrategy:   selectLargestFirst,
+	BranchAndBoundStrategy: selectBranchAndBound,
+	PrivacyStrategy:        selectPrivacy,
+}
+
+// spendableCoins returns the coins that are worth more than the fee
+// to spend them, sorted by descending effective value.
+func spendableCoins(coins []*selectionCoin) []*selectionCoin {
+	spendable := []*selectionCoin{}
+	for _, coin := range coins {
+		if coin.effectiveValue > 0 {
+			spendable = append(spendable, coin)
+		}
+	}
+
+	sort.SliceStable(spendable, func(i, j int) bool {
+		return spendable[i].effectiveValue > spendable[j].effectiveValue
+	})
+
+	return spendable
+}
+
+// accumulateCoins selects coins in order until
+// their effective value reaches target.value.
+func accumulateCoins(coins []*selectionCoin, target *selectionTarget) []*selectionCoin {
+	selected := []*selectionCoin{}
+	total := int64(0)
+	for _, coin := range coins {
+		selected = append(selected, coin)
+		total += coin.effectiveValue
+		if total >= target.value {
+			return selected
+		}
+	}
+
+	return nil
+}
+
+// selectLargestFirst selects the largest coins first, which
+// minimizes the number of inputs (and the fee) of a transaction.
+func selectLargestFirst(coins []*selectionCoin, target *selectionTarget) []*selectionCoin {
+	return accumulateCoins(spendableCoins(coins), target)
+}
+
+// selectBranchAndBound searches for the coins with the smallest total
+// effective value that funds target.value without exceeding it by more
+// than target.costOfChange, so that no change output is needed. If no
+// such coins are found, it falls back to selectLargestFirst.
+func selectBranchAndBound(coins []*selectionCoin, target *selectionTarget) []*selectionCoin {
+	spendable := spendableCoins(coins)
+	remaining := int64(0)
+	for _, coin := range spendable {
+		remaining += coin.effectiveValue
+	}
+
+	upperBound := target.value + target.costOfChange
+	selected := make([]bool, len(spendable))
+	var best []bool
+	var bestExcess int64
+	tries := 0
+
+	var search func(depth int, total int64, remaining int64)
+	search = func(depth int, total int64, remaining int64) {
+		tries++
+		if tries > maxBranchAndBoundTries || (best != nil && bestExcess == 0) {
+			return
+		}
+
+		// Prune branches that overshoot the target
+		// or can no longer reach it.
+		if total > upperBound || total+remaining < target.value {
+			return
+		}
+
+		if total >= target.value {
+			excess := total - target.value
+			if best == nil || excess < bestExcess {
+				best = append([]bool{}, selected...)
+				bestExcess = excess
+			}
+
+			return
+		}
+
+		if depth == len(spendable) {
+			return
+		}
+
+		value := spendable[depth].effectiveValue
+		selected[depth] = true
+		search(depth+1, total+value, remaining-value)
+		selected[depth] = false
+		search(depth+1, total, remaining-value)
+	}
+	search(0, 0, remaining)
+
+	if best == nil {
+		return selectLargestFirst(coins, target)
+	}
+
+	result := []*selectionCoin{}
+	for i, ok := range best {
+		if ok {
+			result = append(result, spendable[i])
+		}
+	}
+
+	return result
+}
+
+// selectPrivacy selects the smallest single coin that funds
+// target.value, so that coins owned by the account are not linked
+// by the transaction. If no single coin is sufficient, coins are
+// selected at random.
+func selectPrivacy(coins []*selectionCoin, target *selectionTarget) []*selectionCoin {
+	spendable := spendableCoins(coins)
+	for i := len(spendable) - 1; i >= 0; i-- {
+		if spendable[i].effectiveValue >= target.value {
+			return []*selectionCoin{spendable[i]}
+		}
+	}
+
+	rand.Shuffle(len(spendable), func(i, j int) {
+		spendable[i], spendable[j] = spendable[j], spendable[i]
+	})
+
+	return accumulateCoins(spendable, target)
+}
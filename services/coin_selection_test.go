@@ -0,0 +1,127 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func selectionCoins(values ...int64) []*selectionCoin {
+	coins := make([]*selectionCoin, len(values))
+	for i, value := range values {
+		coins[i] = &selectionCoin{
+			value:          value,
+			effectiveValue: value,
+		}
+	}
+
+	return coins
+}
+
+func selectedValues(coins []*selectionCoin) []int64 {
+	if coins == nil {
+		return nil
+	}
+
+	values := make([]int64, len(coins))
+	for i, coin := range coins {
+		values[i] = coin.value
+	}
+
+	return values
+}
+
+func TestCoinSelectors(t *testing.T) {
+	tests := map[string]struct {
+		selector coinSelector
+		coins    []*selectionCoin
+		target   *selectionTarget
+		expected []int64
+	}{
+		"largest first": {
+			selector: selectLargestFirst,
+			coins:    selectionCoins(10, 50, 30, 20),
+			target:   &selectionTarget{value: 60, costOfChange: 5},
+			expected: []int64{50, 30},
+		},
+		"largest first skips uneconomical coins": {
+			selector: selectLargestFirst,
+			coins: append(
+				selectionCoins(50),
+				&selectionCoin{value: 5, effectiveValue: -10},
+			),
+			target:   &selectionTarget{value: 40},
+			expected: []int64{50},
+		},
+		"largest first insufficient": {
+			selector: selectLargestFirst,
+			coins:    selectionCoins(10, 20),
+			target:   &selectionTarget{value: 31},
+		},
+		"branch and bound exact": {
+			selector: selectBranchAndBound,
+			coins:    selectionCoins(10, 50, 30, 20),
+			target:   &selectionTarget{value: 60, costOfChange: 5},
+			expected: []int64{50, 10},
+		},
+		"branch and bound within cost of change": {
+			selector: selectBranchAndBound,
+			coins:    selectionCoins(42, 25, 19),
+			target:   &selectionTarget{value: 60, costOfChange: 5},
+			expected: []int64{42, 19},
+		},
+		"branch and bound fallback": {
+			selector: selectBranchAndBound,
+			coins:    selectionCoins(100, 50),
+			target:   &selectionTarget{value: 60, costOfChange: 5},
+			expected: []int64{100},
+		},
+		"branch and bound insufficient": {
+			selector: selectBranchAndBound,
+			coins:    selectionCoins(10, 20),
+			target:   &selectionTarget{value: 31},
+		},
+		"privacy single coin": {
+			selector: selectPrivacy,
+			coins:    selectionCoins(10, 100, 70, 65, 20),
+			target:   &selectionTarget{value: 60, costOfChange: 5},
+			expected: []int64{65},
+		},
+		"privacy insufficient": {
+			selector: selectPrivacy,
+			coins:    selectionCoins(10, 20),
+			target:   &selectionTarget{value: 31},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, selectedValues(test.selector(test.coins, test.target)))
+		})
+	}
+}
+
+func TestSelectPrivacyRandom(t *testing.T) {
+	coins := selectionCoins(10, 20, 30, 40)
+	selected := selectPrivacy(coins, &selectionTarget{value: 60})
+
+	total := int64(0)
+	for _, coin := range selected {
+		total += coin.effectiveValue
+	}
+	assert.GreaterOrEqual(t, total, int64(60))
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
@@ -44,6 +45,14 @@ const (
 	// defaultConfirmationTarget is the number of blocks we would
 	// like our transaction to be included by.
 	defaultConfirmationTarget = int64(2) // nolint:gomnd
+
+	// ecdsaSignatureLength is the length of
+	// an R || S signature.
+	ecdsaSignatureLength = 64
+
+	// ecdsaRecoverySignatureLength is the length
+	// of an R || S || V signature.
+	ecdsaRecoverySignatureLength = 65
 )
 
 // ConstructionAPIService implements the server.ConstructionAPIServicer interface.
@@ -71,18 +80,127 @@ func (s *ConstructionAPIService) ConstructionDerive(
 	ctx context.Context,
 	request *types.ConstructionDeriveRequest,
 ) (*types.ConstructionDeriveResponse, *types.Error) {
-	addr, err := util.NewAddressPubKeyHash(
-		util.Hash160(request.PublicKey.Bytes),
-		s.config.Params,
-	)
+	var metadata DeriveMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	switch metadata.AddressType {
+	case "", P2PKHAddressType:
+		addr, err := util.NewAddressPubKeyHash(
+			util.Hash160(request.PublicKey.Bytes),
+			s.config.Params,
+		)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDerive, err)
+		}
+
+		return &types.ConstructionDeriveResponse{
+			AccountIdentifier: &types.AccountIdentifier{
+				Address: addr.EncodeAddress(),
+			},
+		}, nil
+	case P2PKAddressType:
+		addr, err := util.NewAddressPubKey(request.PublicKey.Bytes, s.config.Params)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDerive, err)
+		}
+
+		// Pay-to-pubkey outputs have no base58 encoding, so we
+		// use the hex-encoded public key (which util.DecodeAddress
+		// accepts when constructing outputs). The indexer assigns
+		// P2PK outputs to the same account.
+		return &types.ConstructionDeriveResponse{
+			AccountIdentifier: &types.AccountIdentifier{
+				Address: addr.String(),
+			},
+		}, nil
+	case P2SHMultisigAddressType:
+		return s.deriveMultisig(&metadata)
+	default:
+		return nil, wrapErr(
+			ErrUnableToDerive,
+			fmt.Errorf("unsupported address type: %s", metadata.AddressType),
+		)
+	}
+}
+
+// deriveMultisig returns the P2SH address of an m-of-n multisig
+// redeem script and the redeem script needed to spend from it.
+func (s *ConstructionAPIService) deriveMultisig(
+	metadata *DeriveMetadata,
+) (*types.ConstructionDeriveResponse, *types.Error) {
+	if len(metadata.PublicKeys) == 0 {
+		return nil, wrapErr(ErrUnableToDerive, errors.New("public keys must be provided"))
+	}
+
+	if len(metadata.PublicKeys) > txscript.MaxPubKeysPerMultiSig {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf(
+			"%d public keys exceeds the maximum of %d",
+			len(metadata.PublicKeys),
+			txscript.MaxPubKeysPerMultiSig,
+		))
+	}
+
+	if metadata.Threshold <= 0 || metadata.Threshold > len(metadata.PublicKeys) {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf(
+			"threshold must be between 1 and %d, got %d",
+			len(metadata.PublicKeys),
+			metadata.Threshold,
+		))
+	}
+
+	pubKeys := make([]*util.AddressPubKey, len(metadata.PublicKeys))
+	for i, publicKey := range metadata.PublicKeys {
+		serializedPubKey, err := hex.DecodeString(publicKey)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnableToDerive,
+				fmt.Errorf("%w unable to decode public key %d", err, i),
+			)
+		}
+
+		pubKeys[i], err = util.NewAddressPubKey(serializedPubKey, s.config.Params)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnableToDerive,
+				fmt.Errorf("%w unable to parse public key %d", err, i),
+			)
+		}
+	}
+
+	redeemScript, err := txscript.MultiSigScript(pubKeys, metadata.Threshold)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	// The redeem script is pushed onto the stack when spending,
+	// so it cannot exceed the maximum element size.
+	if len(redeemScript) > txscript.MaxScriptElementSize {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf(
+			"redeem script size %d exceeds the maximum of %d",
+			len(redeemScript),
+			txscript.MaxScriptElementSize,
+		))
+	}
+
+	addr, err := util.NewAddressScriptHash(redeemScript, s.config.Params)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToDerive, err)
 	}
 
+	responseMetadata, err := types.MarshalMap(&DeriveResponseMetadata{
+		RedeemScript: hex.EncodeToString(redeemScript),
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
 	return &types.ConstructionDeriveResponse{
 		AccountIdentifier: &types.AccountIdentifier{
 			Address: addr.EncodeAddress(),
 		},
+		Metadata: responseMetadata,
 	}, nil
 }
 
@@ -92,16 +210,10 @@ func (s *ConstructionAPIService) estimateSize(operations []*types.Operation) flo
 	for _, operation := range operations {
 		switch operation.Type {
 		case thought.InputOpType:
-			size += thought.InputSize
+			size += s.estimateInputSize(operation)
 		case thought.OutputOpType:
 			size += thought.OutputOverhead
-			addr, err := util.DecodeAddress(operation.Account.Address, s.config.Params)
-			if err != nil {
-				size += thought.P2PKHScriptPubkeySize
-				continue
-			}
-
-			script, err := txscript.PayToAddrScript(addr)
+			script, err := s.outputScript(operation)
 			if err != nil {
 				size += thought.P2PKHScriptPubkeySize
 				continue
@@ -114,6 +226,100 @@ func (s *ConstructionAPIService) estimateSize(operations []*types.Operation) flo
 	return float64(size)
 }
 
+// outputScript returns the locking script for an OUTPUT operation. If
+// the operation metadata contains OP_RETURN data, a null data script is
+// returned instead of paying to the operation account.
+func (s *ConstructionAPIService) outputScript(
+	operation *types.Operation,
+) ([]byte, *types.Error) {
+	var metadata OutputMetadata
+	if err := types.UnmarshalMap(operation.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	if len(metadata.OPReturn) > 0 {
+		if operation.Account != nil {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				errors.New("OP_RETURN outputs cannot have an account"),
+			)
+		}
+
+		data, err := hex.DecodeString(metadata.OPReturn)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("%w unable to decode OP_RETURN data", err),
+			)
+		}
+
+		script, err := txscript.NullDataScript(data)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("%w unable to construct OP_RETURN script", err),
+			)
+		}
+
+		return script, nil
+	}
+
+	if operation.Account == nil {
+		return nil, wrapErr(ErrUnclearIntent, errors.New("output account cannot be nil"))
+	}
+
+	addr, err := util.DecodeAddress(operation.Account.Address, s.config.Params)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDecodeAddress, fmt.Errorf(
+			"%w unable to decode address %s",
+			err,
+			operation.Account.Address,
+		),
+		)
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, wrapErr(
+			ErrUnableToDecodeAddress,
+			fmt.Errorf("%w unable to construct payToAddrScript", err),
+		)
+	}
+
+	return pkScript, nil
+}
+
+// estimateInputSize returns the estimated size of an input in vBytes.
+// Inputs spending a P2SH multisig output must provide the redeem
+// script in their metadata, otherwise they are assumed to be P2PKH.
+func (s *ConstructionAPIService) estimateInputSize(operation *types.Operation) int {
+	var metadata InputMetadata
+	if err := types.UnmarshalMap(operation.Metadata, &metadata); err != nil {
+		return thought.InputSize
+	}
+
+	redeemScript, err := hex.DecodeString(metadata.RedeemScript)
+	if err != nil || len(redeemScript) == 0 {
+		return thought.InputSize
+	}
+
+	_, requiredSigs, err := txscript.CalcMultiSigStats(redeemScript)
+	if err != nil {
+		return thought.InputSize
+	}
+
+	// OP_0 <sigs...> <redeemScript>
+	scriptSig, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(redeemScript).
+		Script()
+	if err != nil {
+		return thought.InputSize
+	}
+
+	return thought.InputOverhead + len(scriptSig) + requiredSigs*thought.MultiSigSignatureSize
+}
+
 // ConstructionPreprocess implements the /construction/preprocess
 // endpoint.
 func (s *ConstructionAPIService) ConstructionPreprocess(
@@ -155,10 +361,16 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 		}
 	}
 
+	var metadata TransactionMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
 	options, err := types.MarshalMap(&preprocessOptions{
 		Coins:         coins,
 		EstimatedSize: s.estimateSize(request.Operations),
 		FeeMultiplier: request.SuggestedFeeMultiplier,
+		LockTime:      metadata.LockTime,
 	})
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
@@ -183,21 +395,11 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	// Determine feePerKB and ensure it is not below the minimum fee
-	// relay rate.
-	feePerKB, err := s.client.SuggestedFeeRate(ctx, defaultConfirmationTarget)
-	if err != nil {
-		return nil, wrapErr(ErrCouldNotGetFeeRate, err)
-	}
-	if options.FeeMultiplier != nil {
-		feePerKB *= *options.FeeMultiplier
-	}
-	if feePerKB < thought.MinFeeRate {
-		feePerKB = thought.MinFeeRate
+	satoshisPerB, rosettaErr := s.feeRate(ctx, options.FeeMultiplier)
+	if rosettaErr != nil {
+		return nil, rosettaErr
 	}
 
-	// Calculated the estimated fee in Notions - the smallest tTHT unit
-	satoshisPerB := (feePerKB * float64(thought.NotionsInThought)) / bytesInKb
 	estimatedFee := satoshisPerB * options.EstimatedSize
 	suggestedFee := &types.Amount{
 		Value:    fmt.Sprintf("%d", int64(estimatedFee)),
@@ -209,7 +411,10 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, wrapErr(ErrScriptPubKeysMissing, err)
 	}
 
-	metadata, err := types.MarshalMap(&constructionMetadata{ScriptPubKeys: scripts})
+	metadata, err := types.MarshalMap(&constructionMetadata{
+		ScriptPubKeys: scripts,
+		LockTime:      options.LockTime,
+	})
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
@@ -220,6 +425,124 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 	}, nil
 }
 
+// feeRate returns the suggested fee rate in Notions (the smallest
+// THT unit) per byte, scaled by the optional fee multiplier.
+func (s *ConstructionAPIService) feeRate(
+	ctx context.Context,
+	feeMultiplier *float64,
+) (float64, *types.Error) {
+	// Determine feePerKB and ensure it is not below the minimum fee
+	// relay rate.
+	feePerKB, err := s.client.SuggestedFeeRate(ctx, defaultConfirmationTarget)
+	if err != nil {
+		return 0, wrapErr(ErrCouldNotGetFeeRate, err)
+	}
+	if feeMultiplier != nil {
+		feePerKB *= *feeMultiplier
+	}
+	if feePerKB < thought.MinFeeRate {
+		feePerKB = thought.MinFeeRate
+	}
+
+	return (feePerKB * float64(thought.NotionsInThought)) / bytesInKb, nil
+}
+
+// multisigPubKeys returns the public keys in a multisig script
+// and the number of signatures required to spend it.
+func (s *ConstructionAPIService) multisigPubKeys(
+	script []byte,
+) ([]*util.AddressPubKey, int, error) {
+	numPubKeys, _, err := txscript.CalcMultiSigStats(script)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, addrs, requiredSigs, err := txscript.ExtractPkScriptAddrs(script, s.config.Params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w unable to extract script addresses", err)
+	}
+
+	// Invalid public keys are omitted by ExtractPkScriptAddrs.
+	if len(addrs) != numPubKeys {
+		return nil, 0, fmt.Errorf("expected %d public keys, got %d", numPubKeys, len(addrs))
+	}
+
+	pubKeys := make([]*util.AddressPubKey, len(addrs))
+	for i, addr := range addrs {
+		pubKeys[i] = addr.(*util.AddressPubKey)
+	}
+
+	return pubKeys, requiredSigs, nil
+}
+
+// parseRedeemScript validates the redeem script provided to spend
+// a P2SH output and returns the public keys that will sign it, in
+// the order they appear in the script.
+func (s *ConstructionAPIService) parseRedeemScript(
+	scriptAddress util.Address,
+	metadata *InputMetadata,
+) ([]byte, []*util.AddressPubKey, error) {
+	if len(metadata.RedeemScript) == 0 {
+		return nil, nil, errors.New("redeem script must be provided")
+	}
+
+	redeemScript, err := hex.DecodeString(metadata.RedeemScript)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w unable to decode redeem script", err)
+	}
+
+	addr, err := util.NewAddressScriptHash(redeemScript, s.config.Params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w unable to hash redeem script", err)
+	}
+
+	if addr.EncodeAddress() != scriptAddress.EncodeAddress() {
+		return nil, nil, fmt.Errorf(
+			"redeem script hashes to %s, not %s",
+			addr.EncodeAddress(),
+			scriptAddress.EncodeAddress(),
+		)
+	}
+
+	pubKeys, requiredSigs, err := s.multisigPubKeys(redeemScript)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(metadata.Signers) == 0 {
+		return redeemScript, pubKeys[:requiredSigs], nil
+	}
+
+	if len(metadata.Signers) != requiredSigs {
+		return nil, nil, fmt.Errorf(
+			"expected %d signers, got %d",
+			requiredSigs,
+			len(metadata.Signers),
+		)
+	}
+
+	selected := make(map[string]struct{}, len(metadata.Signers))
+	for _, signer := range metadata.Signers {
+		selected[signer] = struct{}{}
+	}
+
+	signers := []*util.AddressPubKey{}
+	for _, pubKey := range pubKeys {
+		if _, ok := selected[pubKey.String()]; !ok {
+			continue
+		}
+
+		delete(selected, pubKey.String())
+		signers = append(signers, pubKey)
+	}
+
+	if len(signers) != requiredSigs {
+		return nil, nil, errors.New("signers must be distinct public keys in the redeem script")
+	}
+
+	return redeemScript, signers, nil
+}
+
 // ConstructionPayloads implements the /construction/payloads endpoint.
 func (s *ConstructionAPIService) ConstructionPayloads(
 	ctx context.Context,
@@ -241,13 +564,12 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 				CoinAction:   types.CoinSpent,
 			},
 			{
+				// OP_RETURN outputs have no account and a zero
+				// amount, so both are checked in outputScript.
 				Type: thought.OutputOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
 				Amount: &parser.AmountDescription{
 					Exists:   true,
-					Sign:     parser.PositiveAmountSign,
+					Sign:     parser.PositiveOrZeroAmountSign,
 					Currency: s.config.Currency,
 				},
 				AllowRepeats: true,
@@ -261,14 +583,32 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		return nil, wrapErr(ErrUnclearIntent, err)
 	}
 
-	// Check for Mainnet or Testnet to modify transaction version (TESTNET=2, MAINNET=3) - "NetworkChain" added to configuration.go for this - flip order of mainnet and testnet
-	tx := wire.NewMsgTx(wire.MainnetTxVersion)
-	if s.config.NetworkChain != configuration.Mainnet {
+	// The transaction version depends on the chain (MAINNET=3, TESTNET=2, REGTEST=2)
+	var tx *wire.MsgTx
+	switch s.config.NetworkChain {
+	case configuration.Mainnet:
+		tx = wire.NewMsgTx(wire.MainnetTxVersion)
+	case configuration.Regtest:
+		tx = wire.NewMsgTx(wire.RegtestTxVersion)
+	default:
 		tx = wire.NewMsgTx(wire.TestnetTxVersion)
 	}
 
+	var metadata constructionMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	if metadata.LockTime != nil {
+		tx.LockTime = *metadata.LockTime
+	}
+
 	//tx := wire.NewMsgTx(wire.TxVersion)
-	for _, input := range matches[0].Operations {
+	finalInputs := 0
+	hashTypes := make([]txscript.SigHashType, len(matches[0].Operations))
+	signatureTypes := make([]types.SignatureType, len(matches[0].Operations))
+	customHashTypes := false
+	for i, input := range matches[0].Operations {
 		if input.CoinChange == nil {
 			return nil, wrapErr(ErrUnclearIntent, errors.New("CoinChange cannot be nil"))
 		}
@@ -278,50 +618,101 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 			return nil, wrapErr(ErrInvalidCoin, err)
 		}
 
+		var inputMetadata InputMetadata
+		if err := types.UnmarshalMap(input.Metadata, &inputMetadata); err != nil {
+			return nil, wrapErr(ErrUnclearIntent, err)
+		}
+
+		hashTypes[i], err = parseSigHashType(inputMetadata.SigHashType)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("%w unable to parse sighash type of input %d", err, i),
+			)
+		}
+		if hashTypes[i] != txscript.SigHashAll {
+			customHashTypes = true
+		}
+
+		signatureTypes[i], err = parseSignatureType(inputMetadata.SignatureType)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("%w unable to parse signature type of input %d", err, i),
+			)
+		}
+
+		// The lock time is only enforced if at least one
+		// input is not final.
+		sequence := uint32(wire.MaxTxInSequenceNum)
+		if tx.LockTime != 0 {
+			sequence = wire.MaxTxInSequenceNum - 1
+		}
+		if inputMetadata.Sequence != nil {
+			sequence = *inputMetadata.Sequence
+		}
+		if sequence == wire.MaxTxInSequenceNum {
+			finalInputs++
+		}
+
 		tx.AddTxIn(&wire.TxIn{
 			PreviousOutPoint: wire.OutPoint{
 				Hash:  *transactionHash,
 				Index: index,
 			},
 			SignatureScript: nil,
-			Sequence:        wire.MaxTxInSequenceNum,
+			Sequence:        sequence,
 		})
 	}
 
+	if tx.LockTime != 0 && finalInputs == len(tx.TxIn) {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("lock_time %d has no effect when all inputs are final", tx.LockTime),
+		)
+	}
+
+	dataOutputs := 0
 	for i, output := range matches[1].Operations {
-		addr, err := util.DecodeAddress(output.Account.Address, s.config.Params)
-		if err != nil {
-			return nil, wrapErr(ErrUnableToDecodeAddress, fmt.Errorf(
-				"%w unable to decode address %s",
-				err,
-				output.Account.Address,
-			),
-			)
+		pkScript, rosettaErr := s.outputScript(output)
+		if rosettaErr != nil {
+			return nil, rosettaErr
 		}
 
-		pkScript, err := txscript.PayToAddrScript(addr)
-		if err != nil {
-			return nil, wrapErr(
-				ErrUnableToDecodeAddress,
-				fmt.Errorf("%w unable to construct payToAddrScript", err),
-			)
+		amount := matches[1].Amounts[i]
+		if txscript.IsNullData(pkScript) {
+			dataOutputs++
+			if amount.Sign() != 0 {
+				return nil, wrapErr(
+					ErrUnclearIntent,
+					errors.New("OP_RETURN outputs must have a zero amount"),
+				)
+			}
+		} else if amount.Sign() <= 0 {
+			return nil, wrapErr(ErrUnclearIntent, errors.New("output amount must be positive"))
 		}
 
 		tx.AddTxOut(&wire.TxOut{
-			Value:    matches[1].Amounts[i].Int64(),
+			Value:    amount.Int64(),
 			PkScript: pkScript,
 		})
 	}
 
+	// Transactions with more than one OP_RETURN output
+	// are not relayed by thoughtd.
+	if dataOutputs > 1 {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("at most 1 OP_RETURN output is allowed, got %d", dataOutputs),
+		)
+	}
+
 	// Create Signing Payloads (must be done after entire tx is constructed
 	// or hash will not be correct).
 	inputAmounts := make([]string, len(tx.TxIn))
 	inputAddresses := make([]string, len(tx.TxIn))
-	payloads := make([]*types.SigningPayload, len(tx.TxIn))
-	var metadata constructionMetadata
-	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
-	}
+	payloads := []*types.SigningPayload{}
+	var redeemScripts []string
 
 	for i := range tx.TxIn {
 		address := matches[0].Operations[i].Account.Address
@@ -330,7 +721,7 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
 		}
 
-		class, _, err := thought.ParseSingleAddress(s.config.Params, script)
+		class, scriptAddress, err := thought.ParseSingleAddress(s.config.Params, script)
 		if err != nil {
 			return nil, wrapErr(
 				ErrUnableToDecodeAddress,
@@ -341,11 +732,21 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		inputAddresses[i] = address
 		inputAmounts[i] = matches[0].Amounts[i].String()
 
+		// SIGHASH_SINGLE signs the output at the same index as
+		// the input, so there must be one.
+		if hashTypes[i]&^txscript.SigHashAnyOneCanPay == txscript.SigHashSingle &&
+			i >= len(tx.TxOut) {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("input %d uses SINGLE sighash type without a matching output", i),
+			)
+		}
+
 		switch class {
 		case txscript.PubKeyHashTy:
 			hash, err := txscript.CalcSignatureHash(
 				script,
-				txscript.SigHashAll,
+				hashTypes[i],
 				tx,
 				i,
 			)
@@ -353,13 +754,56 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 				return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
 			}
 
-			payloads[i] = &types.SigningPayload{
+			payloads = append(payloads, &types.SigningPayload{
 				AccountIdentifier: &types.AccountIdentifier{
 					Address: address,
 				},
 				Bytes:         hash,
-				SignatureType: types.Ecdsa,
+				SignatureType: signatureTypes[i],
+			})
+		case txscript.ScriptHashTy:
+			var inputMetadata InputMetadata
+			if err := types.UnmarshalMap(
+				matches[0].Operations[i].Metadata,
+				&inputMetadata,
+			); err != nil {
+				return nil, wrapErr(ErrUnclearIntent, err)
+			}
+
+			redeemScript, signers, err := s.parseRedeemScript(scriptAddress, &inputMetadata)
+			if err != nil {
+				return nil, wrapErr(
+					ErrInvalidRedeemScript,
+					fmt.Errorf("%w unable to parse redeem script for utxo %d", err, i),
+				)
+			}
+
+			// The signature hash of a P2SH input commits to the
+			// redeem script instead of the ScriptPubKey.
+			hash, err := txscript.CalcSignatureHash(
+				redeemScript,
+				hashTypes[i],
+				tx,
+				i,
+			)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
+			}
+
+			for _, signer := range signers {
+				payloads = append(payloads, &types.SigningPayload{
+					AccountIdentifier: &types.AccountIdentifier{
+						Address: signer.EncodeAddress(),
+					},
+					Bytes:         hash,
+					SignatureType: signatureTypes[i],
+				})
+			}
+
+			if redeemScripts == nil {
+				redeemScripts = make([]string, len(tx.TxIn))
 			}
+			redeemScripts[i] = hex.EncodeToString(redeemScript)
 		default:
 			return nil, wrapErr(
 				ErrUnsupportedScriptType,
@@ -373,12 +817,18 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	rawTx, err := json.Marshal(&unsignedTransaction{
+	unsigned := &unsignedTransaction{
 		Transaction:    hex.EncodeToString(buf.Bytes()),
 		ScriptPubKeys:  metadata.ScriptPubKeys,
 		InputAmounts:   inputAmounts,
 		InputAddresses: inputAddresses,
-	})
+		RedeemScripts:  redeemScripts,
+	}
+	if customHashTypes {
+		unsigned.SigHashTypes = hashTypes
+	}
+
+	rawTx, err := json.Marshal(unsigned)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
@@ -427,7 +877,11 @@ func (s *ConstructionAPIService) ConstructionCombine(
 		)
 	}
 
+	// Signatures are provided in the same order as the
+	// payloads returned by /construction/payloads.
+	sigIndex := 0
 	for i := range tx.TxIn {
+		hashType := unsigned.sigHashType(i)
 		decodedScript, err := hex.DecodeString(unsigned.ScriptPubKeys[i].Hex)
 		if err != nil {
 			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
@@ -441,57 +895,93 @@ func (s *ConstructionAPIService) ConstructionCombine(
 			)
 		}
 
-		// Instantiate new secp256k1 ModNScalars for the R and S values of the DER signature format
-		rvalue := new(secp256k1.ModNScalar)
-		svalue := new(secp256k1.ModNScalar)
-		// Strip leading zeros from raw signature bytes - leading zeros causes issues when grabbing the first 32 bytes and last 32 bytes
-		rawBytes := request.Signatures[i].Bytes
-		for len(rawBytes) > 0 && rawBytes[0] == 0x00 {
-			rawBytes = rawBytes[1:]
-		}
-		// Place raw signature bytes into R and S values of DER format -> then error check
-		rresult := rvalue.SetByteSlice(rawBytes[:32])
-		sresult := svalue.SetByteSlice(rawBytes[32:64])
-		if rresult || sresult {
-			return nil, wrapErr(
-				ErrUnableToParseIntermediateResult,
-				fmt.Errorf("%w transaction signature cannot be parsed: signature could not be placed into DER format", err),
-			)
-		}
-		// Create a new signature from R and S values -> then Serialize, which places the R & S values into DER format ((30) (length of remainder) (02) (length of R) (R) (02) (length of S) (S))
-		sig := ecdsa.NewSignature(rvalue, svalue)
-		//sigBytes := sig.Serialize()
-
-		// Parse DER signature for error checking -> ParseSignature also recreates the signature - There exists code bloat here, but may be useful for debugging
-		//fullsig, err := ecdsa.ParseSignature(sigBytes)
-		//if fullsig == nil {
-		//	return nil, wrapErr(
-		//		ErrUnableToParseIntermediateResult,
-		//		fmt.Errorf("%w transaction signature cannot be parsed: value is nil", err),
-		//	)
-		//}
-
-		// Normalize DER signature output from ecdsa Parse by serializing and appending the 0x01 (SigHashAll) byte
-		normalizedSignature := append(sig.Serialize(), byte(txscript.SigHashAll))
-		if err != nil {
-			return nil, wrapErr(
-				ErrUnableToParseIntermediateResult,
-				fmt.Errorf("%w transaction signature cannot be normalized", err),
-			)
-		}
+		switch class {
+		case txscript.PubKeyHashTy:
+			if sigIndex >= len(request.Signatures) {
+				return nil, wrapErr(
+					ErrUnableToParseIntermediateResult,
+					fmt.Errorf("missing signature for input %d", i),
+				)
+			}
 
-		pkData := request.Signatures[i].PublicKey.Bytes
+			signature := request.Signatures[sigIndex]
+			sigIndex++
+
+			normalizedSignature, err := normalizeSignature(signature, hashType)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+			}
 
-		if class != txscript.PubKeyHashTy {
+			pkData := signature.PublicKey.Bytes
+			tx.TxIn[i].SignatureScript, err = txscript.NewScriptBuilder().AddData(normalizedSignature).AddData(pkData).Script()
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, fmt.Errorf("%w calculate input signature", err))
+			}
+		case txscript.ScriptHashTy:
+			if i >= len(unsigned.RedeemScripts) || len(unsigned.RedeemScripts[i]) == 0 {
+				return nil, wrapErr(
+					ErrInvalidRedeemScript,
+					fmt.Errorf("missing redeem script for input %d", i),
+				)
+			}
+
+			redeemScript, err := hex.DecodeString(unsigned.RedeemScripts[i])
+			if err != nil {
+				return nil, wrapErr(ErrInvalidRedeemScript, err)
+			}
+
+			_, requiredSigs, err := txscript.CalcMultiSigStats(redeemScript)
+			if err != nil {
+				return nil, wrapErr(ErrInvalidRedeemScript, err)
+			}
+
+			if sigIndex+requiredSigs > len(request.Signatures) {
+				return nil, wrapErr(
+					ErrUnableToParseIntermediateResult,
+					fmt.Errorf("missing signatures for input %d: %d required", i, requiredSigs),
+				)
+			}
+
+			// OP_CHECKMULTISIG pops one more item than it needs
+			// from the stack, so the scriptSig must start with OP_0.
+			builder := txscript.NewScriptBuilder().AddOp(txscript.OP_0)
+			for j := 0; j < requiredSigs; j++ {
+				normalizedSignature, err := normalizeSignature(
+					request.Signatures[sigIndex],
+					hashType,
+				)
+				if err != nil {
+					return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+				}
+
+				builder.AddData(normalizedSignature)
+				sigIndex++
+			}
+
+			tx.TxIn[i].SignatureScript, err = builder.AddData(redeemScript).Script()
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, fmt.Errorf("%w calculate input signature", err))
+			}
+		default:
 			return nil, wrapErr(
 				ErrUnsupportedScriptType,
 				fmt.Errorf("unupported script type: %s", class),
 			)
 		}
+	}
 
-		tx.TxIn[i].SignatureScript, err = txscript.NewScriptBuilder().AddData(normalizedSignature).AddData(pkData).Script()
-		if err != nil {
-			return nil, wrapErr(ErrUnableToParseIntermediateResult, fmt.Errorf("%w calculate input signature", err))
+	if sigIndex != len(request.Signatures) {
+		return nil, wrapErr(
+			ErrUnableToParseIntermediateResult,
+			fmt.Errorf("expected %d signatures, got %d", sigIndex, len(request.Signatures)),
+		)
+	}
+
+	// Execute each input script so that an invalid signature is
+	// reported here instead of when the transaction is submitted.
+	for i := range tx.TxIn {
+		if err := s.verifyInput(&tx, i, unsigned.ScriptPubKeys[i]); err != nil {
+			return nil, wrapErr(ErrInvalidSignature, err)
 		}
 	}
 
@@ -516,6 +1006,195 @@ func (s *ConstructionAPIService) ConstructionCombine(
 	}, nil
 }
 
+// verifyInput executes the scriptSig of the input at idx against
+// the ScriptPubKey of the output it spends using the standard
+// script verification flags enforced by thoughtd.
+func (s *ConstructionAPIService) verifyInput(
+	tx *wire.MsgTx,
+	idx int,
+	scriptPubKey *thought.ScriptPubKey,
+) error {
+	pkScript, err := hex.DecodeString(scriptPubKey.Hex)
+	if err != nil {
+		return fmt.Errorf("%w unable to decode script pub key of input %d", err, idx)
+	}
+
+	// The input amount is only used to verify
+	// segwit scripts, which are not supported.
+	vm, err := txscript.NewEngine(
+		pkScript,
+		tx,
+		idx,
+		txscript.StandardVerifyFlags,
+		nil,
+		nil,
+		0,
+	)
+	if err != nil {
+		return fmt.Errorf("%w unable to create script engine for input %d", err, idx)
+	}
+
+	if err := vm.Execute(); err != nil {
+		return fmt.Errorf("%w signature verification failed for input %d", err, idx)
+	}
+
+	return nil
+}
+
+// normalizeSignature converts a signature provided to /construction/combine
+// into the low-S DER format expected in a scriptSig, appending the hash type
+// byte. Signatures may be a 64-byte R || S (optionally left-padded with a
+// zero byte), a 65-byte R || S || V (ecdsa_recovery) or DER-encoded.
+func normalizeSignature(
+	signature *types.Signature,
+	hashType txscript.SigHashType,
+) ([]byte, error) {
+	rawBytes := signature.Bytes
+
+	var sig *ecdsa.Signature
+	switch {
+	case signature.SignatureType == types.EcdsaRecovery:
+		if len(rawBytes) != ecdsaRecoverySignatureLength {
+			return nil, fmt.Errorf(
+				"ecdsa_recovery signature must be %d bytes, got %d",
+				ecdsaRecoverySignatureLength,
+				len(rawBytes),
+			)
+		}
+
+		// The recovery byte is not included in a scriptSig.
+		parsed, err := parseCompactSignature(rawBytes[:ecdsaSignatureLength])
+		if err != nil {
+			return nil, err
+		}
+		sig = parsed
+	case len(rawBytes) == ecdsaSignatureLength+1 && rawBytes[0] == 0x00:
+		// Some signers left-pad R || S with a zero byte.
+		parsed, err := parseCompactSignature(rawBytes[1:])
+		if err != nil {
+			return nil, err
+		}
+		sig = parsed
+	case len(rawBytes) == ecdsaSignatureLength:
+		parsed, err := parseCompactSignature(rawBytes)
+		if err != nil {
+			return nil, err
+		}
+		sig = parsed
+	default:
+		parsed, err := ecdsa.ParseDERSignature(rawBytes)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w signature is neither %d bytes nor DER-encoded",
+				err,
+				ecdsaSignatureLength,
+			)
+		}
+		sig = parsed
+	}
+
+	// Serialize places the R & S values into DER format ((30) (length of
+	// remainder) (02) (length of R) (R) (02) (length of S) (S)) and negates
+	// S if it is over the half order, which thoughtd requires for relay.
+	return append(sig.Serialize(), byte(hashType)), nil
+}
+
+// parseCompactSignature parses a 64-byte R || S signature,
+// ensuring R and S are in the range [1, N-1].
+func parseCompactSignature(rawBytes []byte) (*ecdsa.Signature, error) {
+	var rvalue, svalue secp256k1.ModNScalar
+	if overflow := rvalue.SetByteSlice(rawBytes[:32]); overflow || rvalue.IsZero() {
+		return nil, errors.New("invalid signature: R must be in the range [1, N-1]")
+	}
+	if overflow := svalue.SetByteSlice(rawBytes[32:64]); overflow || svalue.IsZero() {
+		return nil, errors.New("invalid signature: S must be in the range [1, N-1]")
+	}
+
+	return ecdsa.NewSignature(&rvalue, &svalue), nil
+}
+
+// parseSigHashType parses the name of a signature hash type
+// (ex: ALL, SINGLE|ANYONECANPAY). An empty name is SigHashAll.
+func parseSigHashType(name string) (txscript.SigHashType, error) {
+	if len(name) == 0 {
+		return txscript.SigHashAll, nil
+	}
+
+	var hashType txscript.SigHashType
+	baseName := strings.ToUpper(name)
+	if strings.HasSuffix(baseName, anyoneCanPaySuffix) {
+		hashType = txscript.SigHashAnyOneCanPay
+		baseName = strings.TrimSuffix(baseName, anyoneCanPaySuffix)
+	}
+
+	for baseType, typeName := range sigHashTypeNames {
+		if typeName == baseName {
+			return hashType | baseType, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s is not a valid sighash type", name)
+}
+
+// parseSignatureType returns the signature type requested
+// in the metadata of an INPUT operation (ecdsa if empty).
+func parseSignatureType(signatureType types.SignatureType) (types.SignatureType, error) {
+	if len(signatureType) == 0 {
+		return types.Ecdsa, nil
+	}
+
+	for _, supported := range SignatureTypes {
+		if signatureType == supported {
+			return signatureType, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s is not a supported signature type", signatureType)
+}
+
+// sigHashTypeName returns the name of a signature hash
+// type, as accepted by parseSigHashType.
+func sigHashTypeName(hashType txscript.SigHashType) string {
+	name, ok := sigHashTypeNames[hashType&^txscript.SigHashAnyOneCanPay]
+	if !ok {
+		return fmt.Sprintf("0x%x", uint32(hashType))
+	}
+
+	if hashType&txscript.SigHashAnyOneCanPay != 0 {
+		name += anyoneCanPaySuffix
+	}
+
+	return name
+}
+
+// sigHashType returns the signature hash
+// type used to sign the input at idx.
+func (u *unsignedTransaction) sigHashType(idx int) txscript.SigHashType {
+	if idx >= len(u.SigHashTypes) {
+		return txscript.SigHashAll
+	}
+
+	return u.SigHashTypes[idx]
+}
+
+// scriptSigHashType returns the signature hash type of the
+// first signature in a scriptSig. Signatures are the only
+// non-empty pushes preceding the last push of a scriptSig.
+func scriptSigHashType(sigScript []byte) (txscript.SigHashType, error) {
+	pushes, err := txscript.PushedData(sigScript)
+	if err != nil {
+		return 0, fmt.Errorf("%w unable to parse scriptSig", err)
+	}
+
+	for _, push := range pushes {
+		if len(push) > 0 {
+			return txscript.SigHashType(push[len(push)-1]), nil
+		}
+	}
+
+	return 0, errors.New("scriptSig does not contain a signature")
+}
+
 // ConstructionHash implements the /construction/hash endpoint.
 func (s *ConstructionAPIService) ConstructionHash(
 	ctx context.Context,
@@ -598,6 +1277,11 @@ func (s *ConstructionAPIService) parseUnsignedTransaction(
 	//						Index refers to rosetta specifc indexing that relies on some garbage single list starting from 1 - can be seen in the .ros file created for testing with the rosetta-cli
 	ops := []*types.Operation{}
 	for i, input := range tx.TxIn {
+		metadata, rosettaErr := inputMetadata(input, unsigned.sigHashType(i))
+		if rosettaErr != nil {
+			return nil, rosettaErr
+		}
+
 		networkIndex := int64(i)
 		ops = append(ops, &types.Operation{
 			OperationIdentifier: &types.OperationIdentifier{
@@ -622,39 +1306,24 @@ func (s *ConstructionAPIService) parseUnsignedTransaction(
 					),
 				},
 			},
+			Metadata: metadata,
 		})
 	}
 
-	for i, output := range tx.TxOut {
-		networkIndex := int64(i)
-		_, addr, err := thought.ParseSingleAddress(s.config.Params, output.PkScript)
-
-		if err != nil {
-			return nil, wrapErr(
-				ErrUnableToDecodeAddress,
-				fmt.Errorf("%w unable to parse output address", err),
-			)
-		}
+	ops, rosettaErr := s.parseOutputOperations(ops, &tx)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
 
-		ops = append(ops, &types.Operation{
-			OperationIdentifier: &types.OperationIdentifier{
-				Index:        int64(len(ops)),
-				NetworkIndex: &networkIndex,
-			},
-			Type: thought.OutputOpType,
-			Account: &types.AccountIdentifier{
-				Address: addr.String(),
-			},
-			Amount: &types.Amount{
-				Value:    strconv.FormatInt(output.Value, 10),
-				Currency: s.config.Currency,
-			},
-		})
+	metadata, rosettaErr := transactionMetadata(&tx)
+	if rosettaErr != nil {
+		return nil, rosettaErr
 	}
 
 	return &types.ConstructionParseResponse{
 		Operations:               ops,
 		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 metadata,
 	}, nil
 }
 
@@ -712,10 +1381,37 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 			)
 		}
 
+		switch pkScript.Class() {
+		case txscript.ScriptHashTy:
+			multisigSigners, err := s.multisigSigners(&tx, i, input.SignatureScript)
+			if err != nil {
+				return nil, wrapErr(
+					ErrInvalidRedeemScript,
+					fmt.Errorf("%w unable to determine signers of input %d", err, i),
+				)
+			}
+
+			signers = append(signers, multisigSigners...)
+		default:
+			signers = append(signers, &types.AccountIdentifier{
+				Address: addr.EncodeAddress(),
+			})
+		}
+
+		hashType, err := scriptSigHashType(input.SignatureScript)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnableToParseIntermediateResult,
+				fmt.Errorf("%w unable to determine sighash type of input %d", err, i),
+			)
+		}
+
+		metadata, rosettaErr := inputMetadata(input, hashType)
+		if rosettaErr != nil {
+			return nil, rosettaErr
+		}
+
 		networkIndex := int64(i)
-		signers = append(signers, &types.AccountIdentifier{
-			Address: addr.EncodeAddress(),
-		})
 		ops = append(ops, &types.Operation{
 			OperationIdentifier: &types.OperationIdentifier{
 				Index:        int64(len(ops)),
@@ -739,39 +1435,191 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 					),
 				},
 			},
+			Metadata: metadata,
 		})
 	}
 
+	ops, rosettaErr := s.parseOutputOperations(ops, &tx)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	metadata, rosettaErr := transactionMetadata(&tx)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	return &types.ConstructionParseResponse{
+		Operations:               ops,
+		AccountIdentifierSigners: signers,
+		Metadata:                 metadata,
+	}, nil
+}
+
+// inputMetadata returns the metadata of a parsed INPUT operation,
+// which only includes the sequence number if the input is not final
+// and the sighash type if it is not SigHashAll.
+func inputMetadata(
+	input *wire.TxIn,
+	hashType txscript.SigHashType,
+) (map[string]interface{}, *types.Error) {
+	var inputMetadata InputMetadata
+	if input.Sequence != wire.MaxTxInSequenceNum {
+		sequence := input.Sequence
+		inputMetadata.Sequence = &sequence
+	}
+
+	if hashType != txscript.SigHashAll {
+		inputMetadata.SigHashType = sigHashTypeName(hashType)
+	}
+
+	if inputMetadata.Sequence == nil && len(inputMetadata.SigHashType) == 0 {
+		return nil, nil
+	}
+
+	metadata, err := types.MarshalMap(&inputMetadata)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return metadata, nil
+}
+
+// transactionMetadata returns the metadata of a parsed transaction,
+// which only includes the lock time if it is set.
+func transactionMetadata(tx *wire.MsgTx) (map[string]interface{}, *types.Error) {
+	if tx.LockTime == 0 {
+		return nil, nil
+	}
+
+	lockTime := tx.LockTime
+	metadata, err := types.MarshalMap(&TransactionMetadata{LockTime: &lockTime})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return metadata, nil
+}
+
+// parseOutputOperations appends an OUTPUT operation for each
+// output in a transaction to ops.
+func (s *ConstructionAPIService) parseOutputOperations(
+	ops []*types.Operation,
+	tx *wire.MsgTx,
+) ([]*types.Operation, *types.Error) {
 	for i, output := range tx.TxOut {
 		networkIndex := int64(i)
-		_, addr, err := thought.ParseSingleAddress(s.config.Params, output.PkScript)
-		if err != nil {
-			return nil, wrapErr(
-				ErrUnableToDecodeAddress,
-				fmt.Errorf("%w unable to parse output address", err),
-			)
-		}
-
-		ops = append(ops, &types.Operation{
+		op := &types.Operation{
 			OperationIdentifier: &types.OperationIdentifier{
 				Index:        int64(len(ops)),
 				NetworkIndex: &networkIndex,
 			},
 			Type: thought.OutputOpType,
-			Account: &types.AccountIdentifier{
-				Address: addr.String(),
-			},
 			Amount: &types.Amount{
 				Value:    strconv.FormatInt(output.Value, 10),
 				Currency: s.config.Currency,
 			},
-		})
+		}
+
+		if txscript.IsNullData(output.PkScript) {
+			pushes, err := txscript.PushedData(output.PkScript)
+			if err != nil {
+				return nil, wrapErr(
+					ErrUnableToParseIntermediateResult,
+					fmt.Errorf("%w unable to parse OP_RETURN data", err),
+				)
+			}
+
+			metadata, err := types.MarshalMap(&OutputMetadata{
+				OPReturn: hex.EncodeToString(bytes.Join(pushes, nil)),
+			})
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+			}
+
+			op.Metadata = metadata
+			ops = append(ops, op)
+			continue
+		}
+
+		_, addr, err := thought.ParseSingleAddress(s.config.Params, output.PkScript)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnableToDecodeAddress,
+				fmt.Errorf("%w unable to parse output address", err),
+			)
+		}
+
+		op.Account = &types.AccountIdentifier{
+			Address: addr.String(),
+		}
+		ops = append(ops, op)
 	}
 
-	return &types.ConstructionParseResponse{
-		Operations:               ops,
-		AccountIdentifierSigners: signers,
-	}, nil
+	return ops, nil
+}
+
+// multisigSigners returns the accounts that signed a P2SH multisig
+// input by matching each signature in the scriptSig against the
+// public keys in the redeem script (as OP_CHECKMULTISIG does).
+func (s *ConstructionAPIService) multisigSigners(
+	tx *wire.MsgTx,
+	idx int,
+	sigScript []byte,
+) ([]*types.AccountIdentifier, error) {
+	pushes, err := txscript.PushedData(sigScript)
+	if err != nil {
+		return nil, fmt.Errorf("%w unable to parse scriptSig", err)
+	}
+
+	// OP_0 <sigs...> <redeemScript>
+	if len(pushes) < 2 { // nolint:gomnd
+		return nil, errors.New("scriptSig does not contain a redeem script")
+	}
+
+	redeemScript := pushes[len(pushes)-1]
+	pubKeys, _, err := s.multisigPubKeys(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := []*types.AccountIdentifier{}
+	keyIndex := 0
+	for j, sig := range pushes[1 : len(pushes)-1] {
+		if len(sig) == 0 {
+			return nil, fmt.Errorf("signature %d is empty", j)
+		}
+
+		hashType := txscript.SigHashType(sig[len(sig)-1])
+		hash, err := txscript.CalcSignatureHash(redeemScript, hashType, tx, idx)
+		if err != nil {
+			return nil, fmt.Errorf("%w unable to calculate signature hash", err)
+		}
+
+		parsed, err := ecdsa.ParseDERSignature(sig[:len(sig)-1])
+		if err != nil {
+			return nil, fmt.Errorf("%w unable to parse signature %d", err, j)
+		}
+
+		matched := false
+		for keyIndex < len(pubKeys) && !matched {
+			pubKey := pubKeys[keyIndex]
+			keyIndex++
+
+			if parsed.Verify(hash, pubKey.PubKey()) {
+				signers = append(signers, &types.AccountIdentifier{
+					Address: pubKey.EncodeAddress(),
+				})
+				matched = true
+			}
+		}
+
+		if !matched {
+			return nil, fmt.Errorf("signature %d does not match any public key", j)
+		}
+	}
+
+	return signers, nil
 }
 
 // ConstructionParse implements the /construction/parse endpoint.
@@ -15,14 +15,24 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
 	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/services"
 	"github.com/thoughtnetwork/rosetta-thought/thought"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/thtec"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/thtec/ecdsa"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/txscript"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/util"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/wire"
 
+	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -371,3 +381,1163 @@ func TestConstructionServiceTestnet(t *testing.T) {
 	mockClient.AssertExpectations(t)
 	mockIndexer.AssertExpectations(t)
 }
+
+func TestConstructionServiceMultisig(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    thought.TestnetNetwork,
+		Blockchain: thought.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	// Create a 2-of-3 multisig redeem script
+	privateKeys := make([]*thtec.PrivateKey, 3)
+	pubKeys := make([]*util.AddressPubKey, 3)
+	for i := range privateKeys {
+		privateKeys[i], _ = thtec.PrivKeyFromBytes(bytes.Repeat([]byte{byte(i + 1)}, 32))
+		pubKey, err := util.NewAddressPubKey(
+			privateKeys[i].PubKey().SerializeCompressed(),
+			cfg.Params,
+		)
+		assert.NoError(t, err)
+		pubKeys[i] = pubKey
+	}
+
+	redeemScript, err := txscript.MultiSigScript(pubKeys, 2)
+	assert.NoError(t, err)
+	scriptAddress, err := util.NewAddressScriptHash(redeemScript, cfg.Params)
+	assert.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(scriptAddress)
+	assert.NoError(t, err)
+
+	inputMetadata := &InputMetadata{
+		RedeemScript: hex.EncodeToString(redeemScript),
+	}
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: thought.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: scriptAddress.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "-40000",
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:0",
+				},
+				CoinAction: types.CoinSpent,
+			},
+			Metadata: forceMarshalMap(t, inputMetadata),
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: thought.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU",
+			},
+			Amount: &types.Amount{
+				Value:    "38000",
+				Currency: thought.TestnetCurrency,
+			},
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, rosettaErr := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, rosettaErr)
+	var options preprocessOptions
+	assert.NoError(t, types.UnmarshalMap(preprocessResponse.Options, &options))
+	// 10 overhead + (41 + 1 OP_0 + 2 push + 105 redeem script + 2 * 74 sigs) + 34 output
+	assert.Equal(t, float64(341), options.EstimatedSize)
+
+	// Test Payloads
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*thought.ScriptPubKey{
+			{
+				Hex:  hex.EncodeToString(pkScript),
+				Type: "scripthash",
+			},
+		},
+	}
+	payloadsResponse, rosettaErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Len(t, payloadsResponse.Payloads, 2)
+	for i, payload := range payloadsResponse.Payloads {
+		assert.Equal(t, pubKeys[i].EncodeAddress(), payload.AccountIdentifier.Address)
+		assert.Equal(t, types.Ecdsa, payload.SignatureType)
+	}
+
+	// Test Combine
+	signatures := make([]*types.Signature, len(payloadsResponse.Payloads))
+	for i, payload := range payloadsResponse.Payloads {
+		compact, err := ecdsa.SignCompact(privateKeys[i], payload.Bytes, true)
+		assert.NoError(t, err)
+		signatures[i] = &types.Signature{
+			Bytes:          compact[1:],
+			SigningPayload: payload,
+			PublicKey: &types.PublicKey{
+				Bytes:     pubKeys[i].ScriptAddress(),
+				CurveType: types.Secp256k1,
+			},
+			SignatureType: types.Ecdsa,
+		}
+	}
+
+	combineResponse, rosettaErr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, rosettaErr)
+
+	// Missing signatures
+	_, rosettaErr = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures:          signatures[:1],
+	})
+	assert.Equal(t, ErrUnableToParseIntermediateResult.Code, rosettaErr.Code)
+
+	// Signatures out of order
+	_, rosettaErr = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures:          []*types.Signature{signatures[1], signatures[0]},
+	})
+	assert.Equal(t, ErrInvalidSignature.Code, rosettaErr.Code)
+	assert.Contains(t, rosettaErr.Details["context"], "input 0")
+
+	// Test Parse Signed
+	parseSignedResponse, rosettaErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, []*types.AccountIdentifier{
+		{Address: pubKeys[0].EncodeAddress()},
+		{Address: pubKeys[1].EncodeAddress()},
+	}, parseSignedResponse.AccountIdentifierSigners)
+	assert.Equal(t, scriptAddress.EncodeAddress(), parseSignedResponse.Operations[0].Account.Address)
+
+	// Select signers
+	inputMetadata.Signers = []string{pubKeys[2].String(), pubKeys[0].String()}
+	ops[0].Metadata = forceMarshalMap(t, inputMetadata)
+	payloadsResponse, rosettaErr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Len(t, payloadsResponse.Payloads, 2)
+	assert.Equal(t, pubKeys[0].EncodeAddress(), payloadsResponse.Payloads[0].AccountIdentifier.Address)
+	assert.Equal(t, pubKeys[2].EncodeAddress(), payloadsResponse.Payloads[1].AccountIdentifier.Address)
+
+	// Redeem script does not match output
+	otherScript, err := txscript.MultiSigScript(pubKeys[:2], 1)
+	assert.NoError(t, err)
+	ops[0].Metadata = forceMarshalMap(t, &InputMetadata{
+		RedeemScript: hex.EncodeToString(otherScript),
+	})
+	_, rosettaErr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Equal(t, ErrInvalidRedeemScript.Code, rosettaErr.Code)
+
+	// Missing redeem script
+	ops[0].Metadata = nil
+	_, rosettaErr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Equal(t, ErrInvalidRedeemScript.Code, rosettaErr.Code)
+}
+
+func TestConstructionDeriveAddressTypes(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    thought.TestnetNetwork,
+		Blockchain: thought.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Offline,
+		Network:  networkIdentifier,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+
+	servicer := NewConstructionAPIService(cfg, nil, nil)
+	ctx := context.Background()
+
+	publicKeys := make([]string, 3)
+	pubKeys := make([]*util.AddressPubKey, 3)
+	for i := range publicKeys {
+		_, publicKey := thtec.PrivKeyFromBytes(bytes.Repeat([]byte{byte(i + 1)}, 32))
+		pubKey, err := util.NewAddressPubKey(publicKey.SerializeCompressed(), cfg.Params)
+		assert.NoError(t, err)
+		pubKeys[i] = pubKey
+		publicKeys[i] = pubKey.String()
+	}
+	publicKey := &types.PublicKey{
+		Bytes:     pubKeys[0].ScriptAddress(),
+		CurveType: types.Secp256k1,
+	}
+
+	// P2PKH (default)
+	deriveResponse, err := servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+		Metadata: forceMarshalMap(t, &DeriveMetadata{
+			AddressType: P2PKHAddressType,
+		}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: pubKeys[0].EncodeAddress(),
+		},
+	}, deriveResponse)
+
+	// P2PK
+	deriveResponse, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+		Metadata: forceMarshalMap(t, &DeriveMetadata{
+			AddressType: P2PKAddressType,
+		}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: publicKeys[0],
+		},
+	}, deriveResponse)
+
+	// P2SH multisig
+	redeemScript, rawErr := txscript.MultiSigScript(pubKeys, 2)
+	assert.NoError(t, rawErr)
+	scriptAddress, rawErr := util.NewAddressScriptHash(redeemScript, cfg.Params)
+	assert.NoError(t, rawErr)
+	deriveResponse, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+		Metadata: forceMarshalMap(t, &DeriveMetadata{
+			AddressType: P2SHMultisigAddressType,
+			PublicKeys:  publicKeys,
+			Threshold:   2,
+		}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: scriptAddress.EncodeAddress(),
+		},
+		Metadata: forceMarshalMap(t, &DeriveResponseMetadata{
+			RedeemScript: hex.EncodeToString(redeemScript),
+		}),
+	}, deriveResponse)
+
+	// Invalid threshold
+	_, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+		Metadata: forceMarshalMap(t, &DeriveMetadata{
+			AddressType: P2SHMultisigAddressType,
+			PublicKeys:  publicKeys,
+			Threshold:   4,
+		}),
+	})
+	assert.Equal(t, ErrUnableToDerive.Code, err.Code)
+
+	// Invalid public key
+	_, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+		Metadata: forceMarshalMap(t, &DeriveMetadata{
+			AddressType: P2SHMultisigAddressType,
+			PublicKeys:  []string{"02cfa3"},
+			Threshold:   1,
+		}),
+	})
+	assert.Equal(t, ErrUnableToDerive.Code, err.Code)
+
+	// Unsupported address type
+	_, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+		Metadata: forceMarshalMap(t, &DeriveMetadata{
+			AddressType: "p2wpkh",
+		}),
+	})
+	assert.Equal(t, ErrUnableToDerive.Code, err.Code)
+
+	// Send to the derived P2SH and P2PK addresses
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: thought.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "kyw8MaocLYCniZ3NnJqNST3qtZNygLSiCC",
+			},
+			Amount: &types.Amount{
+				Value:    "-40000",
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:0",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: thought.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: scriptAddress.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "20000",
+				Currency: thought.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: thought.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: publicKeys[0],
+			},
+			Amount: &types.Amount{
+				Value:    "18000",
+				Currency: thought.TestnetCurrency,
+			},
+		},
+	}
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*thought.ScriptPubKey{
+			{
+				Hex:  "76a9144dcd59a0e06d16b4004e6b5779a9a2f8bb9e29da88ac",
+				Type: "pubkeyhash",
+			},
+		},
+	}
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, parseUnsignedResponse.Operations, 3)
+	assert.Equal(t, scriptAddress.EncodeAddress(), parseUnsignedResponse.Operations[1].Account.Address)
+	assert.Equal(t, publicKeys[0], parseUnsignedResponse.Operations[2].Account.Address)
+
+	// The indexer assigns the P2PK output to the derived account
+	var unsigned unsignedTransaction
+	assert.NoError(t, json.Unmarshal(forceHexDecode(t, payloadsResponse.UnsignedTransaction), &unsigned))
+	serializedTx, rawErr := hex.DecodeString(unsigned.Transaction)
+	assert.NoError(t, rawErr)
+	var tx wire.MsgTx
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(serializedTx)))
+	assert.Equal(t, txscript.PubKeyTy, txscript.GetScriptClass(tx.TxOut[1].PkScript))
+	indexedAccount, rawErr := thought.ParsePubKeyScript(hex.EncodeToString(tx.TxOut[1].PkScript))
+	assert.NoError(t, rawErr)
+	assert.Equal(t, publicKeys[0], indexedAccount)
+}
+
+func TestConstructionServiceOPReturn(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    thought.TestnetNetwork,
+		Blockchain: thought.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Offline,
+		Network:  networkIdentifier,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+
+	servicer := NewConstructionAPIService(cfg, nil, nil)
+	ctx := context.Background()
+
+	documentHash := "8d3c8f5e0f1d2b2fb1a4d2c3b8e9f00112233445566778899aabbccddeeff001"
+	dataMetadata := forceMarshalMap(t, &OutputMetadata{OPReturn: documentHash})
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: thought.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "kyw8MaocLYCniZ3NnJqNST3qtZNygLSiCC",
+			},
+			Amount: &types.Amount{
+				Value:    "-40000",
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:0",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: thought.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU",
+			},
+			Amount: &types.Amount{
+				Value:    "38000",
+				Currency: thought.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: thought.OutputOpType,
+			Amount: &types.Amount{
+				Value:    "0",
+				Currency: thought.TestnetCurrency,
+			},
+			Metadata: dataMetadata,
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+	})
+	assert.Nil(t, err)
+	var options preprocessOptions
+	assert.NoError(t, types.UnmarshalMap(preprocessResponse.Options, &options))
+	// 192 + 9 output overhead + (1 OP_RETURN + 1 push + 32 data)
+	assert.Equal(t, float64(235), options.EstimatedSize)
+
+	// Test Payloads
+	metadata := forceMarshalMap(t, &constructionMetadata{
+		ScriptPubKeys: []*thought.ScriptPubKey{
+			{
+				Hex:  "76a9144dcd59a0e06d16b4004e6b5779a9a2f8bb9e29da88ac",
+				Type: "pubkeyhash",
+			},
+		},
+	})
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadata,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	val1 := int64(1)
+	assert.Equal(t, &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{
+			Index:        2,
+			NetworkIndex: &val1,
+		},
+		Type: thought.OutputOpType,
+		Amount: &types.Amount{
+			Value:    "0",
+			Currency: thought.TestnetCurrency,
+		},
+		Metadata: dataMetadata,
+	}, parseUnsignedResponse.Operations[2])
+
+	// Non-zero OP_RETURN amount
+	ops[2].Amount.Value = "1"
+	_, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadata,
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+	ops[2].Amount.Value = "0"
+
+	// Zero amount paying to an address
+	ops[1].Amount.Value = "0"
+	_, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadata,
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+	ops[1].Amount.Value = "38000"
+
+	// OP_RETURN with an account
+	ops[2].Account = &types.AccountIdentifier{Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU"}
+	_, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadata,
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+	ops[2].Account = nil
+
+	// Data too large
+	ops[2].Metadata = forceMarshalMap(t, &OutputMetadata{
+		OPReturn: hex.EncodeToString(make([]byte, txscript.MaxDataCarrierSize+1)),
+	})
+	_, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadata,
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+	ops[2].Metadata = dataMetadata
+
+	// Multiple OP_RETURN outputs
+	_, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations: append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 3,
+			},
+			Type: thought.OutputOpType,
+			Amount: &types.Amount{
+				Value:    "0",
+				Currency: thought.TestnetCurrency,
+			},
+			Metadata: dataMetadata,
+		}),
+		Metadata: metadata,
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+}
+
+func TestConstructionServiceLockTime(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    thought.TestnetNetwork,
+		Blockchain: thought.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	lockTime := uint32(150000)
+	sequence := uint32(0xfffffffd)
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: thought.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "kyw8MaocLYCniZ3NnJqNST3qtZNygLSiCC",
+			},
+			Amount: &types.Amount{
+				Value:    "-40000",
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:0",
+				},
+				CoinAction: types.CoinSpent,
+			},
+			Metadata: forceMarshalMap(t, &InputMetadata{Sequence: &sequence}),
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: thought.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "kyw8MaocLYCniZ3NnJqNST3qtZNygLSiCC",
+			},
+			Amount: &types.Amount{
+				Value:    "-10000",
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: thought.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU",
+			},
+			Amount: &types.Amount{
+				Value:    "48000",
+				Currency: thought.TestnetCurrency,
+			},
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, &TransactionMetadata{LockTime: &lockTime}),
+	})
+	assert.Nil(t, err)
+	var options preprocessOptions
+	assert.NoError(t, types.UnmarshalMap(preprocessResponse.Options, &options))
+	assert.Equal(t, lockTime, *options.LockTime)
+
+	// Test Metadata
+	scriptPubKey := &thought.ScriptPubKey{
+		Hex:  "76a9144dcd59a0e06d16b4004e6b5779a9a2f8bb9e29da88ac",
+		Type: "pubkeyhash",
+	}
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		options.Coins,
+	).Return(
+		[]*thought.ScriptPubKey{scriptPubKey, scriptPubKey},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestedFeeRate",
+		ctx,
+		defaultConfirmationTarget,
+	).Return(
+		thought.MinFeeRate,
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, err)
+	var metadata constructionMetadata
+	assert.NoError(t, types.UnmarshalMap(metadataResponse.Metadata, &metadata))
+	assert.Equal(t, lockTime, *metadata.LockTime)
+
+	// Test Payloads
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, err)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		forceMarshalMap(t, &TransactionMetadata{LockTime: &lockTime}),
+		parseUnsignedResponse.Metadata,
+	)
+	assert.Equal(t, ops[0].Metadata, parseUnsignedResponse.Operations[0].Metadata)
+
+	// Inputs without a sequence are not final when a lock time is set.
+	defaultSequence := uint32(0xfffffffe)
+	assert.Equal(
+		t,
+		forceMarshalMap(t, &InputMetadata{Sequence: &defaultSequence}),
+		parseUnsignedResponse.Operations[1].Metadata,
+	)
+	assert.Nil(t, parseUnsignedResponse.Operations[2].Metadata)
+
+	// Lock time is ignored if all inputs are final
+	finalSequence := uint32(0xffffffff)
+	for _, op := range ops[:2] {
+		op.Metadata = forceMarshalMap(t, &InputMetadata{Sequence: &finalSequence})
+	}
+	_, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionServiceSigHashTypes(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    thought.TestnetNetwork,
+		Blockchain: thought.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	privateKey, _ := thtec.PrivKeyFromBytes(bytes.Repeat([]byte{0x04}, 32))
+	pubKey, err := util.NewAddressPubKey(privateKey.PubKey().SerializeCompressed(), cfg.Params)
+	assert.NoError(t, err)
+	address := pubKey.AddressPubKeyHash()
+	pkScript, err := txscript.PayToAddrScript(address)
+	assert.NoError(t, err)
+
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: thought.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: address.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "-40000",
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:0",
+				},
+				CoinAction: types.CoinSpent,
+			},
+			Metadata: forceMarshalMap(t, &InputMetadata{SigHashType: "single|anyonecanpay"}),
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: thought.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU",
+			},
+			Amount: &types.Amount{
+				Value:    "38000",
+				Currency: thought.TestnetCurrency,
+			},
+		},
+	}
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*thought.ScriptPubKey{
+			{
+				Hex:  hex.EncodeToString(pkScript),
+				Type: "pubkeyhash",
+			},
+		},
+	}
+
+	// Test Payloads
+	payloadsResponse, rosettaErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+
+	// Test Parse Unsigned
+	expectedMetadata := forceMarshalMap(t, &InputMetadata{SigHashType: "SINGLE|ANYONECANPAY"})
+	parseUnsignedResponse, rosettaErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, expectedMetadata, parseUnsignedResponse.Operations[0].Metadata)
+
+	// Test Combine
+	compact, err := ecdsa.SignCompact(privateKey, payloadsResponse.Payloads[0].Bytes, true)
+	assert.NoError(t, err)
+	combineResponse, rosettaErr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				Bytes:          compact[1:],
+				SigningPayload: payloadsResponse.Payloads[0],
+				PublicKey: &types.PublicKey{
+					Bytes:     privateKey.PubKey().SerializeCompressed(),
+					CurveType: types.Secp256k1,
+				},
+				SignatureType: types.Ecdsa,
+			},
+		},
+	})
+	assert.Nil(t, rosettaErr)
+
+	// Signature of the wrong payload
+	compact, err = ecdsa.SignCompact(privateKey, bytes.Repeat([]byte{0x01}, 32), true)
+	assert.NoError(t, err)
+	_, rosettaErr = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				Bytes:          compact[1:],
+				SigningPayload: payloadsResponse.Payloads[0],
+				PublicKey: &types.PublicKey{
+					Bytes:     privateKey.PubKey().SerializeCompressed(),
+					CurveType: types.Secp256k1,
+				},
+				SignatureType: types.Ecdsa,
+			},
+		},
+	})
+	assert.Equal(t, ErrInvalidSignature.Code, rosettaErr.Code)
+
+	// Test Parse Signed
+	parseSignedResponse, rosettaErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, expectedMetadata, parseSignedResponse.Operations[0].Metadata)
+	assert.Equal(t, []*types.AccountIdentifier{
+		{Address: address.EncodeAddress()},
+	}, parseSignedResponse.AccountIdentifierSigners)
+
+	// Invalid sighash type
+	ops[0].Metadata = forceMarshalMap(t, &InputMetadata{SigHashType: "SOME"})
+	_, rosettaErr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, rosettaErr.Code)
+
+	// SINGLE without a matching output
+	ops[0].Metadata = nil
+	ops = append(ops, &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{
+			Index: 2,
+		},
+		Type: thought.InputOpType,
+		Account: &types.AccountIdentifier{
+			Address: address.EncodeAddress(),
+		},
+		Amount: &types.Amount{
+			Value:    "-10000",
+			Currency: thought.TestnetCurrency,
+		},
+		CoinChange: &types.CoinChange{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:1",
+			},
+			CoinAction: types.CoinSpent,
+		},
+		Metadata: forceMarshalMap(t, &InputMetadata{SigHashType: "SINGLE"}),
+	})
+	metadata.ScriptPubKeys = append(metadata.ScriptPubKeys, metadata.ScriptPubKeys[0])
+	_, rosettaErr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, rosettaErr.Code)
+}
+
+func TestNormalizeSignature(t *testing.T) {
+	privateKey, _ := thtec.PrivKeyFromBytes(bytes.Repeat([]byte{0x05}, 32))
+	hash := bytes.Repeat([]byte{0x06}, 32)
+	compact, err := ecdsa.SignCompact(privateKey, hash, true)
+	assert.NoError(t, err)
+
+	rs := compact[1:]
+	expected := append(ecdsa.Sign(privateKey, hash).Serialize(), byte(txscript.SigHashAll))
+
+	// Negate S to create the high-S form of the same signature.
+	var r, s thtec.ModNScalar
+	r.SetByteSlice(rs[:32])
+	s.SetByteSlice(rs[32:])
+	s.Negate()
+	highS := s.Bytes()
+	highSCompact := append(append([]byte{}, rs[:32]...), highS[:]...)
+
+	tests := map[string]struct {
+		signature *types.Signature
+		err       bool
+	}{
+		"ecdsa": {
+			signature: &types.Signature{Bytes: rs, SignatureType: types.Ecdsa},
+		},
+		"padded ecdsa": {
+			signature: &types.Signature{
+				Bytes:         append([]byte{0x00}, rs...),
+				SignatureType: types.Ecdsa,
+			},
+		},
+		"ecdsa_recovery": {
+			signature: &types.Signature{
+				Bytes:         append(append([]byte{}, rs...), compact[0]-31),
+				SignatureType: types.EcdsaRecovery,
+			},
+		},
+		"der": {
+			signature: &types.Signature{
+				Bytes:         expected[:len(expected)-1],
+				SignatureType: types.Ecdsa,
+			},
+		},
+		"high s": {
+			signature: &types.Signature{Bytes: highSCompact, SignatureType: types.Ecdsa},
+		},
+		"short": {
+			signature: &types.Signature{Bytes: rs[:10], SignatureType: types.Ecdsa},
+			err:       true,
+		},
+		"short ecdsa_recovery": {
+			signature: &types.Signature{Bytes: rs, SignatureType: types.EcdsaRecovery},
+			err:       true,
+		},
+		"zero r": {
+			signature: &types.Signature{
+				Bytes:         append(make([]byte, 32), rs[32:]...),
+				SignatureType: types.Ecdsa,
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			normalized, err := normalizeSignature(test.signature, txscript.SigHashAll)
+			if test.err {
+				assert.Error(t, err)
+				assert.Nil(t, normalized)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, normalized)
+		})
+	}
+}
+
+func TestConstructionServiceRecoverableSignatures(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    thought.TestnetNetwork,
+		Blockchain: thought.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   thought.TestnetParams,
+		Currency: thought.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	serverAsserter, err := asserter.NewServer(
+		thought.OperationTypes,
+		HistoricalBalanceLookup,
+		[]*types.NetworkIdentifier{networkIdentifier},
+		CallMethods,
+		MempoolCoins,
+		"",
+	)
+	assert.NoError(t, err)
+	router := NewBlockchainRouter(cfg, mockClient, mockIndexer, nil, nil, serverAsserter)
+
+	post := func(path string, request interface{}, response interface{}) int {
+		body, err := json.Marshal(request)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), response))
+		return w.Code
+	}
+
+	privateKey, _ := thtec.PrivKeyFromBytes(bytes.Repeat([]byte{0x04}, 32))
+	pubKey, err := util.NewAddressPubKey(privateKey.PubKey().SerializeCompressed(), cfg.Params)
+	assert.NoError(t, err)
+	address := pubKey.AddressPubKeyHash()
+	pkScript, err := txscript.PayToAddrScript(address)
+	assert.NoError(t, err)
+
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: thought.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: address.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "-40000",
+				Currency: thought.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "5d7ffb8cf555d87a9524d26d5b2f49570ad1b62fd58bcc391ebe8a469ce1da7f:0",
+				},
+				CoinAction: types.CoinSpent,
+			},
+			Metadata: forceMarshalMap(t, &InputMetadata{SignatureType: types.EcdsaRecovery}),
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: thought.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "m92udt8YzZ3B2WZ4uzjuL5sdaQuNnLM8KU",
+			},
+			Amount: &types.Amount{
+				Value:    "38000",
+				Currency: thought.TestnetCurrency,
+			},
+		},
+	}
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*thought.ScriptPubKey{
+			{
+				Hex:  hex.EncodeToString(pkScript),
+				Type: "pubkeyhash",
+			},
+		},
+	}
+
+	// Test Payloads
+	var payloadsResponse types.ConstructionPayloadsResponse
+	code := post("/construction/payloads", &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	}, &payloadsResponse)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+	assert.Equal(t, types.EcdsaRecovery, payloadsResponse.Payloads[0].SignatureType)
+
+	// Rosetta ecdsa_recovery signatures are R || S || V
+	compact, err := ecdsa.SignCompact(privateKey, payloadsResponse.Payloads[0].Bytes, true)
+	assert.NoError(t, err)
+	recoverable := append(append([]byte{}, compact[1:]...), compact[0]-27-4)
+	assert.Len(t, recoverable, 65)
+	signature := &types.Signature{
+		Bytes:          recoverable,
+		SigningPayload: payloadsResponse.Payloads[0],
+		PublicKey: &types.PublicKey{
+			Bytes:     privateKey.PubKey().SerializeCompressed(),
+			CurveType: types.Secp256k1,
+		},
+		SignatureType: types.EcdsaRecovery,
+	}
+
+	// Test Combine
+	var combineResponse types.ConstructionCombineResponse
+	code = post("/construction/combine", &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures:          []*types.Signature{signature},
+	}, &combineResponse)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, combineResponse.SignedTransaction)
+
+	// Test Parse Signed
+	var parseSignedResponse types.ConstructionParseResponse
+	code = post("/construction/parse", &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	}, &parseSignedResponse)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []*types.AccountIdentifier{
+		{Address: address.EncodeAddress()},
+	}, parseSignedResponse.AccountIdentifierSigners)
+
+	// The asserter rejects signatures of another type
+	var rosettaErr types.Error
+	code = post("/construction/combine", &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				Bytes:          compact[1:],
+				SigningPayload: payloadsResponse.Payloads[0],
+				PublicKey:      signature.PublicKey,
+				SignatureType:  types.Ecdsa,
+			},
+		},
+	}, &rosettaErr)
+	assert.Equal(t, http.StatusInternalServerError, code)
+
+	// Unsupported signature type
+	ops[0].Metadata = forceMarshalMap(t, &InputMetadata{SignatureType: types.Ed25519})
+	code = post("/construction/payloads", &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	}, &rosettaErr)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, ErrUnclearIntent.Code, rosettaErr.Code)
+}
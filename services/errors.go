@@ -41,6 +41,19 @@ var (
 		ErrTransactionNotFound,
 		ErrCouldNotGetFeeRate,
 		ErrUnableToGetBalance,
+		ErrInvalidRedeemScript,
+		ErrInvalidSignature,
+		ErrCallMethodInvalid,
+		ErrCallParametersInvalid,
+		ErrInsufficientFunds,
+		ErrSearchFiltersInvalid,
+		ErrUnableToSearchTransactions,
+		ErrUnableToGetEvents,
+		ErrSubscriptionInvalid,
+		ErrSubscriptionLagged,
+		ErrUnableToGetHistory,
+		ErrUnableToGetStatistics,
+		ErrSearchIndexIncomplete,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -183,6 +196,104 @@ var (
 		Code:    18, //nolint
 		Message: "Unable to get balance",
 	}
+
+	// ErrInvalidRedeemScript is returned when the redeem
+	// script provided to spend a P2SH output is missing,
+	// does not match the output, or is not a multisig script.
+	ErrInvalidRedeemScript = &types.Error{
+		Code:    19, //nolint
+		Message: "Invalid redeem script",
+	}
+
+	// ErrInvalidSignature is returned when a signature
+	// provided to /construction/combine does not satisfy
+	// the script of the input it signs.
+	ErrInvalidSignature = &types.Error{
+		Code:    20, //nolint
+		Message: "Invalid signature",
+	}
+
+	// ErrCallMethodInvalid is returned when the
+	// method provided to /call is not supported.
+	ErrCallMethodInvalid = &types.Error{
+		Code:    21, //nolint
+		Message: "Call method is not supported",
+	}
+
+	// ErrCallParametersInvalid is returned when the
+	// parameters provided to /call are invalid.
+	ErrCallParametersInvalid = &types.Error{
+		Code:    22, //nolint
+		Message: "Call parameters are invalid",
+	}
+
+	// ErrInsufficientFunds is returned when the coins
+	// owned by an account cannot fund a transaction.
+	ErrInsufficientFunds = &types.Error{
+		Code:    23, //nolint
+		Message: "Insufficient funds",
+	}
+
+	// ErrSearchFiltersInvalid is returned when the filters
+	// provided to /search/transactions are not supported.
+	ErrSearchFiltersInvalid = &types.Error{
+		Code:    24, //nolint
+		Message: "Search filters are not supported",
+	}
+
+	// ErrUnableToSearchTransactions is returned by the
+	// indexer when it is not possible to search transactions.
+	ErrUnableToSearchTransactions = &types.Error{
+		Code:    25, //nolint
+		Message: "Unable to search transactions",
+	}
+
+	// ErrUnableToGetEvents is returned by the indexer
+	// when it is not possible to get block events.
+	ErrUnableToGetEvents = &types.Error{
+		Code:    26, //nolint
+		Message: "Unable to get block events",
+	}
+
+	// ErrSubscriptionInvalid is returned to /ws clients
+	// when a subscription request is invalid.
+	ErrSubscriptionInvalid = &types.Error{
+		Code:    27, //nolint
+		Message: "Subscription request is invalid",
+	}
+
+	// ErrSubscriptionLagged is returned to /ws clients
+	// before they are disconnected for falling too far
+	// behind the event stream.
+	ErrSubscriptionLagged = &types.Error{
+		Code:      28, //nolint
+		Message:   "Subscription fell behind the event stream",
+		Retriable: true,
+	}
+
+	// ErrUnableToGetHistory is returned by the indexer
+	// when it is not possible to get the transactions
+	// of an account.
+	ErrUnableToGetHistory = &types.Error{
+		Code:    29, //nolint
+		Message: "Unable to get account history",
+	}
+
+	// ErrUnableToGetStatistics is returned by the indexer
+	// when it is not possible to get the statistics of
+	// the unspent coins.
+	ErrUnableToGetStatistics = &types.Error{
+		Code:    30, //nolint
+		Message: "Unable to get statistics",
+	}
+
+	// ErrSearchIndexIncomplete is returned by the indexer
+	// when the search index does not start at genesis, so
+	// a transaction missing from it may still exist.
+	ErrSearchIndexIncomplete = &types.Error{
+		Code:    31, //nolint
+		Message: "Search index does not cover this range, resync",
+	}
 )
 
 // wrapErr adds details to the types.Error provided. We use a function
@@ -0,0 +1,75 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// EventsAPIService implements the server.EventsAPIServicer interface.
+type EventsAPIService struct {
+	config *configuration.Configuration
+	i      Indexer
+}
+
+// NewEventsAPIService creates a new instance of an EventsAPIService.
+func NewEventsAPIService(
+	config *configuration.Configuration,
+	i Indexer,
+) server.EventsAPIServicer {
+	return &EventsAPIService{
+		config: config,
+		i:      i,
+	}
+}
+
+// EventsBlocks implements the /events/blocks endpoint.
+func (s *EventsAPIService) EventsBlocks(
+	ctx context.Context,
+	request *types.EventsBlocksRequest,
+) (*types.EventsBlocksResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	limit := defaultEventsLimit
+	if request.Limit != nil {
+		limit = *request.Limit
+	}
+	if limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+
+	events, maxSequence, err := s.i.GetBlockEvents(ctx, request.Offset, limit)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetEvents, err)
+	}
+
+	// The max sequence cannot be negative, so
+	// it is 0 before any block is indexed.
+	if maxSequence < 0 {
+		maxSequence = 0
+	}
+
+	return &types.EventsBlocksResponse{
+		MaxSequence: maxSequence,
+		Events:      events,
+	}, nil
+}
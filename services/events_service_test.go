@@ -0,0 +1,120 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsBlocks_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Offline,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewEventsAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	resp, err := servicer.EventsBlocks(ctx, &types.EventsBlocksRequest{})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestEventsBlocks(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewEventsAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	// No events
+	mockIndexer.On(
+		"GetBlockEvents",
+		ctx,
+		(*int64)(nil),
+		defaultEventsLimit,
+	).Return(
+		[]*types.BlockEvent{},
+		int64(-1),
+		nil,
+	).Once()
+	resp, err := servicer.EventsBlocks(ctx, &types.EventsBlocksRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.EventsBlocksResponse{
+		MaxSequence: 0,
+		Events:      []*types.BlockEvent{},
+	}, resp)
+
+	// Limit above the maximum
+	events := []*types.BlockEvent{
+		{
+			Sequence: 5,
+			BlockIdentifier: &types.BlockIdentifier{
+				Index: 3,
+				Hash:  "block 3",
+			},
+			Type: types.REMOVED,
+		},
+	}
+	offset := types.Int64(5)
+	mockIndexer.On(
+		"GetBlockEvents",
+		ctx,
+		offset,
+		maxEventsLimit,
+	).Return(
+		events,
+		int64(5),
+		nil,
+	).Once()
+	resp, err = servicer.EventsBlocks(ctx, &types.EventsBlocksRequest{
+		Offset: offset,
+		Limit:  types.Int64(5000),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.EventsBlocksResponse{
+		MaxSequence: 5,
+		Events:      events,
+	}, resp)
+
+	// Indexer error
+	mockIndexer.On(
+		"GetBlockEvents",
+		ctx,
+		offset,
+		defaultEventsLimit,
+	).Return(
+		nil,
+		int64(-1),
+		errors.New("boom"),
+	).Once()
+	resp, err = servicer.EventsBlocks(ctx, &types.EventsBlocksRequest{
+		Offset: offset,
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnableToGetEvents.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
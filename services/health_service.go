@@ -0,0 +1,202 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+)
+
+const (
+	// healthzPath is the path of the liveness endpoint,
+	// which passes as long as rosetta-thought serves
+	// requests, so that probes do not restart it while
+	// thoughtd starts or the indexer catches up.
+	healthzPath = "/healthz"
+
+	// readyzPath is the path of the readiness endpoint,
+	// which fails if rosetta-thought should not serve
+	// requests (e.g. while thoughtd starts or the
+	// indexer is behind thoughtd).
+	readyzPath = "/readyz"
+
+	// HealthStatusOK is the status of
+	// passing health checks.
+	HealthStatusOK = "ok"
+
+	// HealthStatusUnavailable is the status
+	// of failing health checks.
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthResponse is the response of the /healthz and
+// /readyz endpoints. Checks that are not performed
+// are omitted.
+type HealthResponse struct {
+	Status string `json:"status"`
+
+	// Errors describes every failed check.
+	Errors []string `json:"errors,omitempty"`
+
+	// ThoughtdRunning is omitted if thoughtd
+	// is not launched by rosetta-thought.
+	ThoughtdRunning *bool `json:"thoughtd_running,omitempty"`
+	RPCReachable    *bool `json:"rpc_reachable,omitempty"`
+	DatabaseHealthy *bool `json:"database_healthy,omitempty"`
+
+	NodeHeight    *int64 `json:"node_height,omitempty"`
+	IndexerHeight *int64 `json:"indexer_height,omitempty"`
+	Lag           *int64 `json:"lag,omitempty"`
+
+	// LastBlockTime is the timestamp of
+	// the last block added by the indexer.
+	LastBlockTime *time.Time `json:"last_block_time,omitempty"`
+	LastBlockAge  string     `json:"last_block_age,omitempty"`
+}
+
+// fail records a failed check.
+func (r *HealthResponse) fail(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// healthHandler serves the /healthz and /readyz
+// endpoints. /healthz performs no checks and
+// /readyz always passes in offline mode.
+type healthHandler struct {
+	config *configuration.Configuration
+	client Client
+	i      Indexer
+
+	// process is nil if thoughtd is not
+	// launched by rosetta-thought.
+	process *thought.Process
+	next    http.Handler
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != healthzPath && r.URL.Path != readyzPath {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	response := &HealthResponse{}
+	if r.URL.Path == readyzPath && h.config.Mode == configuration.Online {
+		h.checkReadiness(r.Context(), response)
+	}
+
+	if len(response.Errors) > 0 {
+		response.Status = HealthStatusUnavailable
+		server.EncodeJSONResponse(response, http.StatusServiceUnavailable, w)
+		return
+	}
+
+	response.Status = HealthStatusOK
+	server.EncodeJSONResponse(response, http.StatusOK, w)
+}
+
+// checkDatabase checks that the database can be read and
+// reports the last block added by the indexer (if any).
+func (h *healthHandler) checkDatabase(ctx context.Context, response *HealthResponse) {
+	healthy := true
+	block, err := h.i.GetBlockLazy(ctx, nil)
+	switch {
+	case err == nil:
+		index := block.Block.BlockIdentifier.Index
+		timestamp := time.UnixMilli(block.Block.Timestamp).UTC()
+		response.IndexerHeight = &index
+		response.LastBlockTime = &timestamp
+		response.LastBlockAge = time.Since(timestamp).Truncate(time.Second).String()
+	case errors.Is(err, storageErrs.ErrHeadBlockNotFound):
+		// The database is healthy but
+		// no block has been added yet.
+	default:
+		healthy = false
+		response.fail("unable to read database: %s", err.Error())
+	}
+	response.DatabaseHealthy = &healthy
+}
+
+// checkReadiness checks that thoughtd is running (if launched
+// by rosetta-thought) and responds to RPC requests, that the
+// database can be read and that the indexer is within the
+// configured thresholds of the tip of thoughtd.
+func (h *healthHandler) checkReadiness(ctx context.Context, response *HealthResponse) {
+	health := h.config.Health
+	if health == nil {
+		health = configuration.DefaultHealthConfiguration()
+	}
+
+	if h.process != nil {
+		running := h.process.Running()
+		response.ThoughtdRunning = &running
+		if !running {
+			response.fail("thoughtd is not running")
+		}
+	}
+
+	h.checkDatabase(ctx, response)
+
+	ctx, cancel := context.WithTimeout(ctx, health.Timeout)
+	defer cancel()
+
+	info, err := h.client.GetBlockchainInfo(ctx)
+	reachable := err == nil
+	response.RPCReachable = &reachable
+	if err != nil {
+		response.fail("unable to reach thoughtd: %s", err.Error())
+	} else {
+		response.NodeHeight = &info.Blocks
+	}
+
+	if response.IndexerHeight == nil {
+		if *response.DatabaseHealthy {
+			response.fail("no block has been indexed")
+		}
+
+		return
+	}
+
+	if response.NodeHeight != nil {
+		lag := *response.NodeHeight - *response.IndexerHeight
+		if lag < 0 {
+			lag = 0
+		}
+
+		response.Lag = &lag
+		if lag > health.MaxLag {
+			response.fail("indexer is %d blocks behind thoughtd (maximum %d)", lag, health.MaxLag)
+		}
+	}
+
+	if health.MaxBlockAge > 0 {
+		if age := time.Since(*response.LastBlockTime); age > health.MaxBlockAge {
+			response.fail(
+				"last block is %s old (maximum %s)",
+				age.Truncate(time.Second),
+				health.MaxBlockAge,
+			)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/services"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealthHandler(t *testing.T) {
+	recentBlock := &types.BlockResponse{
+		Block: &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{Index: 100, Hash: "block 100"},
+			Timestamp:       time.Now().Add(-time.Minute).UnixMilli(),
+		},
+	}
+	oldBlock := &types.BlockResponse{
+		Block: &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{Index: 100, Hash: "block 100"},
+			Timestamp:       time.Now().Add(-3 * time.Hour).UnixMilli(),
+		},
+	}
+
+	tests := map[string]struct {
+		mode    configuration.Mode
+		path    string
+		process *thought.Process
+
+		block      *types.BlockResponse
+		blockErr   error
+		nodeHeight int64
+		rpcErr     error
+
+		expectedCode   int
+		expectedErrors []string
+	}{
+		"offline": {
+			mode:         configuration.Offline,
+			path:         readyzPath,
+			expectedCode: http.StatusOK,
+		},
+		"live": {
+			mode:         configuration.Online,
+			path:         healthzPath,
+			expectedCode: http.StatusOK,
+		},
+		"live while thoughtd starts": {
+			mode:         configuration.Online,
+			path:         healthzPath,
+			process:      &thought.Process{},
+			expectedCode: http.StatusOK,
+		},
+		"live offline": {
+			mode:         configuration.Offline,
+			path:         healthzPath,
+			expectedCode: http.StatusOK,
+		},
+		"thoughtd not running": {
+			mode:           configuration.Online,
+			path:           readyzPath,
+			process:        &thought.Process{},
+			block:          recentBlock,
+			nodeHeight:     102,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedErrors: []string{"thoughtd is not running"},
+		},
+		"database unhealthy": {
+			mode:           configuration.Online,
+			path:           readyzPath,
+			blockErr:       errors.New("disk failure"),
+			nodeHeight:     102,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedErrors: []string{"unable to read database: disk failure"},
+		},
+		"ready": {
+			mode:         configuration.Online,
+			path:         readyzPath,
+			block:        recentBlock,
+			nodeHeight:   102,
+			expectedCode: http.StatusOK,
+		},
+		"not ready without blocks": {
+			mode:           configuration.Online,
+			path:           readyzPath,
+			blockErr:       storageErrs.ErrHeadBlockNotFound,
+			nodeHeight:     102,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedErrors: []string{"no block has been indexed"},
+		},
+		"rpc unreachable": {
+			mode:         configuration.Online,
+			path:         readyzPath,
+			block:        recentBlock,
+			rpcErr:       errors.New("connection refused"),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedErrors: []string{
+				"unable to reach thoughtd: connection refused",
+			},
+		},
+		"lagging": {
+			mode:         configuration.Online,
+			path:         readyzPath,
+			block:        recentBlock,
+			nodeHeight:   110,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedErrors: []string{
+				"indexer is 10 blocks behind thoughtd (maximum 6)",
+			},
+		},
+		"stale": {
+			mode:         configuration.Online,
+			path:         readyzPath,
+			block:        oldBlock,
+			nodeHeight:   100,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedErrors: []string{
+				"last block is 3h0m0s old (maximum 2h0m0s)",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode:   test.mode,
+				Health: configuration.DefaultHealthConfiguration(),
+			}
+			mockClient := &mocks.Client{}
+			mockIndexer := &mocks.Indexer{}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatalf("unexpected request to %s", r.URL.Path)
+			})
+			handler := &healthHandler{
+				config:  cfg,
+				client:  mockClient,
+				i:       mockIndexer,
+				process: test.process,
+				next:    next,
+			}
+
+			if test.mode == configuration.Online && test.path == readyzPath {
+				mockIndexer.On(
+					"GetBlockLazy",
+					mock.Anything,
+					(*types.PartialBlockIdentifier)(nil),
+				).Return(test.block, test.blockErr).Once()
+
+				var info *thought.BlockchainInfo
+				if test.rpcErr == nil {
+					info = &thought.BlockchainInfo{Blocks: test.nodeHeight}
+				}
+				mockClient.On("GetBlockchainInfo", mock.Anything).Return(info, test.rpcErr).Once()
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+
+			var response HealthResponse
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			if test.expectedCode == http.StatusOK {
+				assert.Equal(t, HealthStatusOK, response.Status)
+			} else {
+				assert.Equal(t, HealthStatusUnavailable, response.Status)
+			}
+			assert.Equal(t, test.expectedErrors, response.Errors)
+
+			mockClient.AssertExpectations(t)
+			mockIndexer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHealthHandler_Response(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:   configuration.Online,
+		Health: configuration.DefaultHealthConfiguration(),
+	}
+	mockClient := &mocks.Client{}
+	mockIndexer := &mocks.Indexer{}
+	handler := &healthHandler{
+		config: cfg,
+		client: mockClient,
+		i:      mockIndexer,
+		next:   http.NotFoundHandler(),
+	}
+
+	timestamp := time.Now().Add(-time.Minute).Truncate(time.Millisecond).UTC()
+	mockIndexer.On(
+		"GetBlockLazy",
+		mock.Anything,
+		(*types.PartialBlockIdentifier)(nil),
+	).Return(&types.BlockResponse{
+		Block: &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{Index: 100, Hash: "block 100"},
+			Timestamp:       timestamp.UnixMilli(),
+		},
+	}, nil)
+	mockClient.On("GetBlockchainInfo", mock.Anything).Return(&thought.BlockchainInfo{
+		Blocks: 103,
+	}, nil)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, readyzPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response HealthResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.True(t, *response.RPCReachable)
+	assert.True(t, *response.DatabaseHealthy)
+	assert.Nil(t, response.ThoughtdRunning)
+	assert.Equal(t, int64(103), *response.NodeHeight)
+	assert.Equal(t, int64(100), *response.IndexerHeight)
+	assert.Equal(t, int64(3), *response.Lag)
+	assert.True(t, timestamp.Equal(*response.LastBlockTime))
+	assert.Equal(t, "1m0s", response.LastBlockAge)
+
+	// Other paths are passed to the next handler.
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/network/list", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
@@ -15,6 +15,9 @@
 package services
 
 import (
+	"bufio"
+	"errors"
+	"net"
 	"net/http"
 	"time"
 
@@ -44,6 +47,25 @@ func (r *StatusRecorder) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
+// Hijack allows the connections of WebSocket
+// requests to be taken over by their handler.
+// The status code of hijacked requests is
+// http.StatusSwitchingProtocols.
+func (r *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.Code = http.StatusSwitchingProtocols
+	return conn, rw, nil
+}
+
 // LoggerMiddleware is a simple logger middleware that prints the requests in
 // an ad-hoc fashion to the stdlib's log.
 func LoggerMiddleware(loggerRaw *zap.Logger, inner http.Handler) http.Handler {
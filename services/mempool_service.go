@@ -16,8 +16,10 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
 
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -27,16 +29,19 @@ import (
 type MempoolAPIService struct {
 	config *configuration.Configuration
 	client Client
+	i      Indexer
 }
 
 // NewMempoolAPIService creates a new instance of a MempoolAPIService.
 func NewMempoolAPIService(
 	config *configuration.Configuration,
 	client Client,
+	i Indexer,
 ) server.MempoolAPIServicer {
 	return &MempoolAPIService{
 		config: config,
 		client: client,
+		i:      i,
 	}
 }
 
@@ -73,5 +78,15 @@ func (s *MempoolAPIService) MempoolTransaction(
 		return nil, wrapErr(ErrUnavailableOffline, nil)
 	}
 
-	return nil, wrapErr(ErrUnimplemented, nil)
+	transaction, err := s.i.GetMempoolTransaction(ctx, request.TransactionIdentifier)
+	if errors.Is(err, thought.ErrTransactionNotFound) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrThoughtd, err)
+	}
+
+	return &types.MempoolTransactionResponse{
+		Transaction: transaction,
+	}, nil
 }
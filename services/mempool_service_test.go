@@ -16,9 +16,12 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
 	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/services"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -30,7 +33,8 @@ func TestMempoolEndpoints_Offline(t *testing.T) {
 		Mode: configuration.Offline,
 	}
 	mockClient := &mocks.Client{}
-	servicer := NewMempoolAPIService(cfg, mockClient)
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
 	ctx := context.Background()
 	mem, err := servicer.Mempool(ctx, nil)
 	assert.Nil(t, mem)
@@ -42,6 +46,7 @@ func TestMempoolEndpoints_Offline(t *testing.T) {
 	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
 	assert.Equal(t, ErrUnavailableOffline.Message, err.Message)
 	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
 }
 
 func TestMempoolEndpoints_Online(t *testing.T) {
@@ -50,7 +55,8 @@ func TestMempoolEndpoints_Online(t *testing.T) {
 	}
 
 	mockClient := &mocks.Client{}
-	servicer := NewMempoolAPIService(cfg, mockClient)
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
 	ctx := context.Background()
 
 	mockClient.On("RawMempool", ctx).Return([]string{
@@ -70,9 +76,71 @@ func TestMempoolEndpoints_Online(t *testing.T) {
 		},
 	}, mem)
 
-	memTransaction, err := servicer.MempoolTransaction(ctx, nil)
+	transaction := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "tx1",
+		},
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index: 0,
+				},
+				Type:   thought.OutputOpType,
+				Status: types.String(thought.SuccessStatus),
+				Account: &types.AccountIdentifier{
+					Address: "addr1",
+				},
+				Amount: &types.Amount{
+					Value:    "100",
+					Currency: thought.MainnetCurrency,
+				},
+			},
+		},
+	}
+	mockIndexer.On(
+		"GetMempoolTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: "tx1"},
+	).Return(
+		transaction,
+		nil,
+	).Once()
+	memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.MempoolTransactionResponse{
+		Transaction: transaction,
+	}, memTransaction)
+
+	mockIndexer.On(
+		"GetMempoolTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: "tx3"},
+	).Return(
+		nil,
+		fmt.Errorf("%w: unable to get mempool entry", thought.ErrTransactionNotFound),
+	).Once()
+	memTransaction, err = servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx3"},
+	})
 	assert.Nil(t, memTransaction)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+
+	mockIndexer.On(
+		"GetMempoolTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: "tx2"},
+	).Return(
+		nil,
+		errors.New("connection refused"),
+	).Once()
+	memTransaction, err = servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx2"},
+	})
+	assert.Nil(t, memTransaction)
+	assert.Equal(t, ErrThoughtd.Code, err.Code)
+
 	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
 }
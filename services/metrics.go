@@ -0,0 +1,58 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thoughtnetwork/rosetta-thought/metrics"
+)
+
+// unmatchedEndpoint is the endpoint label of requests
+// that do not match any route, so that arbitrary paths
+// do not create new time series.
+const unmatchedEndpoint = "unmatched"
+
+// MetricsMiddleware serves the Prometheus metrics at
+// metrics.Path and records the latency and status code
+// of all other requests. The latency of WebSocket
+// connections is not recorded because the handler
+// only returns once the connection is closed.
+func MetricsMiddleware(inner http.Handler) http.Handler {
+	metricsHandler := metrics.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == metrics.Path {
+			metricsHandler.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		recorder := NewStatusRecorder(w)
+
+		inner.ServeHTTP(recorder, r)
+
+		endpoint := r.URL.Path
+		if recorder.Code == http.StatusNotFound || recorder.Code == http.StatusMethodNotAllowed {
+			endpoint = unmatchedEndpoint
+		}
+
+		if recorder.Code != http.StatusSwitchingProtocols {
+			metrics.APIDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		}
+		metrics.APIRequests.WithLabelValues(endpoint, strconv.Itoa(recorder.Code)).Inc()
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thoughtnetwork/rosetta-thought/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	server := httptest.NewServer(MetricsMiddleware(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/network/list":
+				w.WriteHeader(http.StatusOK)
+			case "/network/status":
+				w.WriteHeader(http.StatusInternalServerError)
+			case subscriptionPath:
+				conn, _, err := w.(http.Hijacker).Hijack()
+				assert.NoError(t, err)
+				_, err = conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n\r\n"))
+				assert.NoError(t, err)
+				conn.Close()
+			default:
+				http.NotFound(w, r)
+			}
+		},
+	)))
+	defer server.Close()
+
+	for _, path := range []string{"/network/list", "/network/status", "/random/path"} {
+		resp, err := http.Post(server.URL+path, "application/json", strings.NewReader("{}"))
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(server.URL + subscriptionPath)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + metrics.Path)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		`rosetta_thought_api_requests_total{code="200",endpoint="/network/list"} 1`,
+		`rosetta_thought_api_requests_total{code="500",endpoint="/network/status"} 1`,
+		`rosetta_thought_api_requests_total{code="404",endpoint="unmatched"} 1`,
+		`rosetta_thought_api_requests_total{code="101",endpoint="/ws"} 1`,
+		`rosetta_thought_api_request_duration_seconds_count{endpoint="/network/list"} 1`,
+		"rosetta_thought_indexer_head_height",
+		"go_goroutines",
+	} {
+		assert.Contains(t, string(body), expected)
+	}
+
+	// Arbitrary paths do not create new time series
+	assert.NotContains(t, string(body), "/random/path")
+
+	// The lifetime of WebSocket connections is not latency
+	assert.NotContains(t, string(body), `rosetta_thought_api_request_duration_seconds_count{endpoint="/ws"}`)
+}
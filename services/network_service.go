@@ -93,6 +93,9 @@ func (s *NetworkAPIService) NetworkOptions(
 			RosettaVersion:    types.RosettaAPIVersion,
 			NodeVersion:       NodeVersion,
 			MiddlewareVersion: types.String(MiddlewareVersion),
+			Metadata: map[string]interface{}{
+				"signature_types": SignatureTypes,
+			},
 		},
 		Allow: &types.Allow{
 			OperationStatuses:       thought.OperationStatuses,
@@ -100,6 +103,7 @@ func (s *NetworkAPIService) NetworkOptions(
 			Errors:                  Errors,
 			HistoricalBalanceLookup: HistoricalBalanceLookup,
 			MempoolCoins:            MempoolCoins,
+			CallMethods:             CallMethods,
 		},
 	}, nil
 }
@@ -33,12 +33,17 @@ var (
 			RosettaVersion:    types.RosettaAPIVersion,
 			NodeVersion:       "0.18.2",
 			MiddlewareVersion: &middlewareVersion,
+			Metadata: map[string]interface{}{
+				"signature_types": SignatureTypes,
+			},
 		},
 		Allow: &types.Allow{
 			OperationStatuses:       thought.OperationStatuses,
 			OperationTypes:          thought.OperationTypes,
 			Errors:                  Errors,
 			HistoricalBalanceLookup: HistoricalBalanceLookup,
+			MempoolCoins:            MempoolCoins,
+			CallMethods:             CallMethods,
 		},
 	}
 
@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
 
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/server"
@@ -29,6 +30,8 @@ func NewBlockchainRouter(
 	config *configuration.Configuration,
 	client Client,
 	i Indexer,
+	subscriptions *Subscriptions,
+	process *thought.Process,
 	asserter *asserter.Asserter,
 ) http.Handler {
 	networkAPIService := NewNetworkAPIService(config, client, i)
@@ -55,17 +58,54 @@ func NewBlockchainRouter(
 		asserter,
 	)
 
-	mempoolAPIService := NewMempoolAPIService(config, client)
+	mempoolAPIService := NewMempoolAPIService(config, client, i)
 	mempoolAPIController := server.NewMempoolAPIController(
 		mempoolAPIService,
 		asserter,
 	)
 
-	return server.NewRouter(
+	callAPIService := NewCallAPIService(config, client, i)
+	callAPIController := server.NewCallAPIController(
+		callAPIService,
+		asserter,
+	)
+
+	searchAPIService := NewSearchAPIService(config, i)
+	searchAPIController := server.NewSearchAPIController(
+		searchAPIService,
+		asserter,
+	)
+
+	eventsAPIService := NewEventsAPIService(config, i)
+	eventsAPIController := server.NewEventsAPIController(
+		eventsAPIService,
+		asserter,
+	)
+
+	router := server.NewRouter(
 		networkAPIController,
 		blockAPIController,
 		accountAPIController,
 		constructionAPIController,
 		mempoolAPIController,
+		callAPIController,
+		searchAPIController,
+		eventsAPIController,
 	)
+
+	return &healthHandler{
+		config:  config,
+		client:  client,
+		i:       i,
+		process: process,
+		next: &subscriptionHandler{
+			config:        config,
+			subscriptions: subscriptions,
+			next: &blockTransactionLookupHandler{
+				config: config,
+				i:      i,
+				next:   router,
+			},
+		},
+	}
 }
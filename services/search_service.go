@@ -0,0 +1,99 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// SearchAPIService implements the server.SearchAPIServicer interface.
+type SearchAPIService struct {
+	config *configuration.Configuration
+	i      Indexer
+}
+
+// NewSearchAPIService creates a new instance of a SearchAPIService.
+func NewSearchAPIService(
+	config *configuration.Configuration,
+	i Indexer,
+) server.SearchAPIServicer {
+	return &SearchAPIService{
+		config: config,
+		i:      i,
+	}
+}
+
+// SearchTransactions implements the /search/transactions endpoint.
+func (s *SearchAPIService) SearchTransactions(
+	ctx context.Context,
+	request *types.SearchTransactionsRequest,
+) (*types.SearchTransactionsResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	// Transactions are only indexed by hash,
+	// account address and coin identifier.
+	if request.TransactionIdentifier == nil &&
+		request.AccountIdentifier == nil &&
+		request.Address == nil &&
+		request.CoinIdentifier == nil {
+		return nil, wrapErr(
+			ErrSearchFiltersInvalid,
+			errors.New("a transaction identifier, account identifier, address or coin identifier is required"),
+		)
+	}
+
+	if request.Operator != nil && *request.Operator == types.OR &&
+		(request.Currency != nil ||
+			request.Status != nil ||
+			request.Type != nil ||
+			request.Success != nil) {
+		return nil, wrapErr(
+			ErrSearchFiltersInvalid,
+			errors.New("currency, status, type and success filters cannot be used with the or operator"),
+		)
+	}
+
+	offset := int64(0)
+	if request.Offset != nil {
+		offset = *request.Offset
+	}
+
+	limit := defaultSearchLimit
+	if request.Limit != nil {
+		limit = *request.Limit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	response, err := s.i.SearchTransactions(ctx, request, offset, limit)
+	if errors.Is(err, thought.ErrSearchIndexIncomplete) {
+		return nil, wrapErr(ErrSearchIndexIncomplete, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrUnableToSearchTransactions, err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+	mocks "github.com/thoughtnetwork/rosetta-thought/mocks/services"
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTransactions_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Offline,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewSearchAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	resp, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		Address: types.String("hello"),
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestSearchTransactions_InvalidFilters(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewSearchAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	// No indexed filter
+	resp, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		Type: types.String(thought.InputOpType),
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrSearchFiltersInvalid.Code, err.Code)
+
+	// Operation filter with the or operator
+	resp, err = servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		Operator: types.OperatorP(types.OR),
+		Address:  types.String("hello"),
+		Type:     types.String(thought.InputOpType),
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrSearchFiltersInvalid.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestSearchTransactions(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewSearchAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	response := &types.SearchTransactionsResponse{
+		Transactions: []*types.BlockTransaction{
+			{
+				BlockIdentifier: &types.BlockIdentifier{
+					Index: 10,
+					Hash:  "block 10",
+				},
+				Transaction: &types.Transaction{
+					TransactionIdentifier: &types.TransactionIdentifier{
+						Hash: "tx 1",
+					},
+				},
+			},
+		},
+		TotalCount: 1,
+	}
+
+	// Default limit
+	request := &types.SearchTransactionsRequest{
+		Address: types.String("hello"),
+	}
+	mockIndexer.On(
+		"SearchTransactions",
+		ctx,
+		request,
+		int64(0),
+		defaultSearchLimit,
+	).Return(
+		response,
+		nil,
+	).Once()
+	resp, err := servicer.SearchTransactions(ctx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, response, resp)
+
+	// Limit above the maximum
+	request = &types.SearchTransactionsRequest{
+		Address: types.String("hello"),
+		Offset:  types.Int64(5),
+		Limit:   types.Int64(1000),
+	}
+	mockIndexer.On(
+		"SearchTransactions",
+		ctx,
+		request,
+		int64(5),
+		maxSearchLimit,
+	).Return(
+		response,
+		nil,
+	).Once()
+	resp, err = servicer.SearchTransactions(ctx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, response, resp)
+
+	// Indexer error
+	request = &types.SearchTransactionsRequest{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: "tx 1:0"},
+	}
+	mockIndexer.On(
+		"SearchTransactions",
+		ctx,
+		request,
+		int64(0),
+		defaultSearchLimit,
+	).Return(
+		nil,
+		errors.New("boom"),
+	).Once()
+	resp, err = servicer.SearchTransactions(ctx, request)
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnableToSearchTransactions.Code, err.Code)
+
+	// Incomplete search index
+	request = &types.SearchTransactionsRequest{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: "tx 2:0"},
+	}
+	mockIndexer.On(
+		"SearchTransactions",
+		ctx,
+		request,
+		int64(0),
+		defaultSearchLimit,
+	).Return(
+		nil,
+		fmt.Errorf("%w: oldest indexed block is 5, not genesis", thought.ErrSearchIndexIncomplete),
+	).Once()
+	resp, err = servicer.SearchTransactions(ctx, request)
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrSearchIndexIncomplete.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
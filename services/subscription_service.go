@@ -0,0 +1,362 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// subscriptionPath is the path of
+	// the WebSocket subscription API.
+	subscriptionPath = "/ws"
+
+	// BlockAddedTopic events are published
+	// when a block is added.
+	BlockAddedTopic = "block_added"
+
+	// BlockRemovedTopic events are published
+	// when a block is removed during a reorg.
+	BlockRemovedTopic = "block_removed"
+
+	// MempoolTopic events are published when a
+	// transaction is added to the mempool.
+	MempoolTopic = "mempool"
+
+	// AddressTopic events are published when a subscribed
+	// address appears in a transaction of an added block
+	// or of the mempool.
+	AddressTopic = "address_activity"
+
+	// SubscribeMethod adds topics and addresses
+	// to a subscription.
+	SubscribeMethod = "subscribe"
+
+	// UnsubscribeMethod removes topics and
+	// addresses from a subscription.
+	UnsubscribeMethod = "unsubscribe"
+
+	// subscriptionBufferSize is the number of events
+	// buffered for each subscription. Subscriptions that
+	// fall further behind are closed so that publishing
+	// never blocks the indexer.
+	subscriptionBufferSize = 1000
+
+	// maxSubscriptionAddresses is the maximum number
+	// of addresses of a subscription.
+	maxSubscriptionAddresses = 1000
+
+	// subscriptionWriteTimeout is the maximum
+	// duration of a write to a /ws client.
+	subscriptionWriteTimeout = 15 * time.Second
+)
+
+// subscriptionTopics are the topics
+// that can be subscribed to.
+var subscriptionTopics = map[string]struct{}{
+	BlockAddedTopic:   {},
+	BlockRemovedTopic: {},
+	MempoolTopic:      {},
+	AddressTopic:      {},
+}
+
+// StreamEvent is published to the subscriptions of its topic.
+// The BlockIdentifier of AddressTopic events is nil if the
+// transaction is in the mempool.
+type StreamEvent struct {
+	Topic                 string                       `json:"topic"`
+	BlockIdentifier       *types.BlockIdentifier       `json:"block_identifier,omitempty"`
+	TransactionIdentifier *types.TransactionIdentifier `json:"transaction_identifier,omitempty"`
+	Address               string                       `json:"address,omitempty"`
+}
+
+// SubscriptionRequest is sent by /ws clients to
+// change the events they receive.
+type SubscriptionRequest struct {
+	Method    string   `json:"method"`
+	Topics    []string `json:"topics,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// SubscriptionMessage is sent to /ws clients.
+type SubscriptionMessage struct {
+	Event *StreamEvent `json:"event,omitempty"`
+	Error *types.Error `json:"error,omitempty"`
+}
+
+// Subscription is the topics and addresses
+// a subscriber receives events for.
+type Subscription struct {
+	events    chan *StreamEvent
+	topics    map[string]struct{}
+	addresses map[string]struct{}
+}
+
+// Events returns the events of the subscription. It is
+// closed if the subscription falls too far behind.
+func (s *Subscription) Events() <-chan *StreamEvent {
+	return s.events
+}
+
+// matches returns a boolean indicating if
+// an event should be sent to the subscription.
+func (s *Subscription) matches(event *StreamEvent) bool {
+	if _, ok := s.topics[event.Topic]; !ok {
+		return false
+	}
+
+	if event.Topic != AddressTopic {
+		return true
+	}
+
+	_, ok := s.addresses[event.Address]
+	return ok
+}
+
+// Subscriptions fans out the events published by
+// the indexer to subscribers without ever blocking
+// the publisher.
+type Subscriptions struct {
+	subscriptions map[*Subscription]struct{}
+	lock          sync.Mutex
+}
+
+// NewSubscriptions returns a new *Subscriptions.
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{
+		subscriptions: map[*Subscription]struct{}{},
+	}
+}
+
+// Subscribe returns a new *Subscription
+// without any topics.
+func (s *Subscriptions) Subscribe() *Subscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	subscription := &Subscription{
+		events:    make(chan *StreamEvent, subscriptionBufferSize),
+		topics:    map[string]struct{}{},
+		addresses: map[string]struct{}{},
+	}
+	s.subscriptions[subscription] = struct{}{}
+
+	return subscription
+}
+
+// Unsubscribe stops sending events to a *Subscription.
+func (s *Subscriptions) Unsubscribe(subscription *Subscription) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.remove(subscription)
+}
+
+// remove deletes a subscription and closes its
+// events. The caller must hold the lock.
+func (s *Subscriptions) remove(subscription *Subscription) {
+	if _, ok := s.subscriptions[subscription]; !ok {
+		return
+	}
+
+	delete(s.subscriptions, subscription)
+	close(subscription.events)
+}
+
+// Update applies a SubscriptionRequest to a *Subscription.
+func (s *Subscriptions) Update(
+	subscription *Subscription,
+	request *SubscriptionRequest,
+) error {
+	for _, topic := range request.Topics {
+		if _, ok := subscriptionTopics[topic]; !ok {
+			return fmt.Errorf("%s is not a valid topic", topic)
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch request.Method {
+	case SubscribeMethod:
+		added := map[string]struct{}{}
+		for _, address := range request.Addresses {
+			if _, ok := subscription.addresses[address]; !ok {
+				added[address] = struct{}{}
+			}
+		}
+
+		if len(subscription.addresses)+len(added) > maxSubscriptionAddresses {
+			return fmt.Errorf(
+				"subscriptions cannot have more than %d addresses",
+				maxSubscriptionAddresses,
+			)
+		}
+
+		for _, topic := range request.Topics {
+			subscription.topics[topic] = struct{}{}
+		}
+
+		for address := range added {
+			subscription.addresses[address] = struct{}{}
+		}
+	case UnsubscribeMethod:
+		for _, topic := range request.Topics {
+			delete(subscription.topics, topic)
+		}
+
+		for _, address := range request.Addresses {
+			delete(subscription.addresses, address)
+		}
+	default:
+		return fmt.Errorf("%s is not a valid subscription method", request.Method)
+	}
+
+	return nil
+}
+
+// Active returns a boolean indicating if there are any
+// subscriptions, so that publishers can skip building
+// events no one will receive.
+func (s *Subscriptions) Active() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.subscriptions) > 0
+}
+
+// Publish sends events to the matching subscriptions. Any
+// subscription with a full buffer is closed instead of
+// waiting for it to catch up.
+func (s *Subscriptions) Publish(events ...*StreamEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for subscription := range s.subscriptions {
+		for _, event := range events {
+			if !subscription.matches(event) {
+				continue
+			}
+
+			select {
+			case subscription.events <- event:
+				continue
+			default:
+			}
+
+			s.remove(subscription)
+			break
+		}
+	}
+}
+
+// subscriptionHandler serves the WebSocket subscription
+// API at subscriptionPath, deferring all other requests
+// to the next http.Handler.
+type subscriptionHandler struct {
+	config        *configuration.Configuration
+	subscriptions *Subscriptions
+	next          http.Handler
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *subscriptionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != subscriptionPath {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	if h.config.Mode != configuration.Online {
+		server.EncodeJSONResponse(wrapErr(ErrUnavailableOffline, nil), http.StatusInternalServerError, w)
+		return
+	}
+
+	// Any origin is accepted, as with the
+	// CORS headers of the other endpoints.
+	websocket.Server{Handler: h.serve}.ServeHTTP(w, r)
+}
+
+// send writes a SubscriptionMessage to a /ws client.
+func (h *subscriptionHandler) send(ws *websocket.Conn, message *SubscriptionMessage) error {
+	if err := ws.SetWriteDeadline(time.Now().Add(subscriptionWriteTimeout)); err != nil {
+		return err
+	}
+
+	return websocket.JSON.Send(ws, message)
+}
+
+// serve streams the events of a new subscription to a /ws
+// client while applying its subscription requests.
+func (h *subscriptionHandler) serve(ws *websocket.Conn) {
+	// Hijacked connections keep the deadlines set by the
+	// http.Server, which would otherwise close the stream.
+	if err := ws.SetDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	subscription := h.subscriptions.Subscribe()
+	defer h.subscriptions.Unsubscribe(subscription)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for {
+			var data []byte
+			if err := websocket.Message.Receive(ws, &data); err != nil {
+				return
+			}
+
+			var request SubscriptionRequest
+			err := json.Unmarshal(data, &request)
+			if err == nil {
+				err = h.subscriptions.Update(subscription, &request)
+			}
+
+			if err != nil {
+				message := &SubscriptionMessage{Error: wrapErr(ErrSubscriptionInvalid, err)}
+				if err := h.send(ws, message); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				_ = h.send(ws, &SubscriptionMessage{Error: wrapErr(ErrSubscriptionLagged, nil)})
+				return
+			}
+
+			if err := h.send(ws, &SubscriptionMessage{Event: event}); err != nil {
+				return
+			}
+		}
+	}
+}
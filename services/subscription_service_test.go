@@ -0,0 +1,193 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thoughtnetwork/rosetta-thought/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+func newSubscriptionServer(
+	mode configuration.Mode,
+	subscriptions *Subscriptions,
+) *httptest.Server {
+	cfg := &configuration.Configuration{
+		Mode: mode,
+	}
+
+	return httptest.NewServer(LoggerMiddleware(zap.NewNop(), &subscriptionHandler{
+		config:        cfg,
+		subscriptions: subscriptions,
+		next:          http.NotFoundHandler(),
+	}))
+}
+
+func TestSubscriptions_Offline(t *testing.T) {
+	server := newSubscriptionServer(configuration.Offline, NewSubscriptions())
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + subscriptionPath
+	_, err := websocket.Dial(wsURL, "", server.URL)
+	assert.Error(t, err)
+}
+
+func TestSubscriptions(t *testing.T) {
+	subscriptions := NewSubscriptions()
+	server := newSubscriptionServer(configuration.Online, subscriptions)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + subscriptionPath
+	ws, err := websocket.Dial(wsURL, "", server.URL)
+	assert.NoError(t, err)
+	defer ws.Close()
+
+	assert.NoError(t, websocket.JSON.Send(ws, &SubscriptionRequest{
+		Method:    SubscribeMethod,
+		Topics:    []string{BlockAddedTopic, AddressTopic},
+		Addresses: []string{"addr1"},
+	}))
+
+	// Requests are applied in order, so the subscription is
+	// active once the error of the next request is received.
+	assert.NoError(t, websocket.JSON.Send(ws, &SubscriptionRequest{
+		Method: SubscribeMethod,
+		Topics: []string{"blocks"},
+	}))
+
+	var message SubscriptionMessage
+	assert.NoError(t, websocket.JSON.Receive(ws, &message))
+	assert.Nil(t, message.Event)
+	assert.Equal(t, ErrSubscriptionInvalid.Code, message.Error.Code)
+	assert.True(t, subscriptions.Active())
+
+	block := &types.BlockIdentifier{Index: 1, Hash: "block 1"}
+	transaction := &types.TransactionIdentifier{Hash: "tx 1"}
+	events := []*StreamEvent{
+		{Topic: BlockAddedTopic, BlockIdentifier: block},
+		{Topic: MempoolTopic, TransactionIdentifier: transaction},
+		{
+			Topic:                 AddressTopic,
+			BlockIdentifier:       block,
+			TransactionIdentifier: transaction,
+			Address:               "addr2",
+		},
+		{
+			Topic:                 AddressTopic,
+			BlockIdentifier:       block,
+			TransactionIdentifier: transaction,
+			Address:               "addr1",
+		},
+		{Topic: BlockRemovedTopic, BlockIdentifier: block},
+	}
+	subscriptions.Publish(events...)
+
+	// Only events of subscribed topics and
+	// addresses are received.
+	for _, expected := range []*StreamEvent{events[0], events[3]} {
+		var message SubscriptionMessage
+		assert.NoError(t, websocket.JSON.Receive(ws, &message))
+		assert.Nil(t, message.Error)
+		assert.Equal(t, expected, message.Event)
+	}
+
+	// Subscriptions are removed when clients disconnect
+	assert.NoError(t, ws.Close())
+	assert.Eventually(t, func() bool {
+		return !subscriptions.Active()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSubscriptions_Update(t *testing.T) {
+	subscriptions := NewSubscriptions()
+	subscription := subscriptions.Subscribe()
+
+	assert.Error(t, subscriptions.Update(subscription, &SubscriptionRequest{
+		Method: "watch",
+		Topics: []string{BlockAddedTopic},
+	}))
+	assert.Error(t, subscriptions.Update(subscription, &SubscriptionRequest{
+		Method: SubscribeMethod,
+		Topics: []string{"blocks"},
+	}))
+
+	addresses := make([]string, maxSubscriptionAddresses+1)
+	for j := range addresses {
+		addresses[j] = fmt.Sprintf("addr%d", j)
+	}
+	assert.Error(t, subscriptions.Update(subscription, &SubscriptionRequest{
+		Method:    SubscribeMethod,
+		Topics:    []string{AddressTopic},
+		Addresses: addresses,
+	}))
+	assert.Empty(t, subscription.addresses)
+
+	assert.NoError(t, subscriptions.Update(subscription, &SubscriptionRequest{
+		Method:    SubscribeMethod,
+		Topics:    []string{BlockAddedTopic, AddressTopic},
+		Addresses: []string{"addr1", "addr2"},
+	}))
+	assert.NoError(t, subscriptions.Update(subscription, &SubscriptionRequest{
+		Method:    UnsubscribeMethod,
+		Topics:    []string{BlockAddedTopic},
+		Addresses: []string{"addr1"},
+	}))
+
+	subscriptions.Publish(
+		&StreamEvent{Topic: BlockAddedTopic},
+		&StreamEvent{Topic: AddressTopic, Address: "addr1"},
+		&StreamEvent{Topic: AddressTopic, Address: "addr2"},
+	)
+	assert.Equal(t, &StreamEvent{Topic: AddressTopic, Address: "addr2"}, <-subscription.Events())
+	assert.Len(t, subscription.Events(), 0)
+}
+
+func TestSubscriptions_Lagged(t *testing.T) {
+	subscriptions := NewSubscriptions()
+	slow := subscriptions.Subscribe()
+	assert.NoError(t, subscriptions.Update(slow, &SubscriptionRequest{
+		Method: SubscribeMethod,
+		Topics: []string{BlockAddedTopic},
+	}))
+
+	// Publishing never blocks on a full subscription,
+	// which is closed instead.
+	for j := 0; j <= subscriptionBufferSize; j++ {
+		subscriptions.Publish(&StreamEvent{
+			Topic:           BlockAddedTopic,
+			BlockIdentifier: &types.BlockIdentifier{Index: int64(j)},
+		})
+	}
+	assert.False(t, subscriptions.Active())
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	assert.Equal(t, subscriptionBufferSize, received)
+
+	// Unsubscribing a closed subscription is a no-op
+	subscriptions.Unsubscribe(slow)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/thoughtnetwork/rosetta-thought/thought"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/txscript"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
@@ -33,24 +34,180 @@ const (
 
 	// MempoolCoins indicates that
 	// including mempool coins in the /account/coins
-	// response is not supported.
-	MempoolCoins = false
+	// response is supported.
+	MempoolCoins = true
 
 	// inlineFetchLimit is the maximum number
 	// of transactions to fetch inline.
 	inlineFetchLimit = 100
 
+	// defaultSearchLimit is the number of transactions
+	// returned by /search/transactions if no limit
+	// is provided.
+	defaultSearchLimit = int64(25)
+
+	// maxSearchLimit is the maximum number of
+	// transactions returned by /search/transactions.
+	maxSearchLimit = int64(100)
+
+	// defaultEventsLimit is the number of block
+	// events returned by /events/blocks if no
+	// limit is provided.
+	defaultEventsLimit = int64(100)
+
+	// maxEventsLimit is the maximum number of block
+	// events returned by /events/blocks.
+	maxEventsLimit = int64(1000)
+
 	// MiddlewareVersion is the version
 	// of rosetta-thought. We set this as a
 	// variable instead of a constant because
 	// we typically need the pointer of this
 	// value.
 	MiddlewareVersion = "0.0.9"
+
+	// P2PKHAddressType is the default address type
+	// returned by /construction/derive.
+	P2PKHAddressType = "p2pkh"
+
+	// P2PKAddressType is used to derive a
+	// pay-to-pubkey address (the hex-encoded
+	// public key) in /construction/derive.
+	P2PKAddressType = "p2pk"
+
+	// P2SHMultisigAddressType is used to derive
+	// a P2SH-wrapped m-of-n multisig address in
+	// /construction/derive.
+	P2SHMultisigAddressType = "p2sh_multisig"
+
+	// SelectCoinsMethod is the /call method that selects
+	// coins owned by an account to fund a set of outputs
+	// and returns the operations of the transaction.
+	SelectCoinsMethod = "select_coins"
+
+	// FindTransactionMethod is the /call method that
+	// returns a transaction and the block containing
+	// it given only the transaction identifier.
+	FindTransactionMethod = "find_transaction"
+
+	// AccountCoinsMethod is the /call method that
+	// returns the coins owned by an account at
+	// a past block.
+	AccountCoinsMethod = "account_coins"
+
+	// BatchAccountsMethod is the /call method that
+	// returns the balances and coins of many accounts
+	// at a single block.
+	BatchAccountsMethod = "batch_accounts"
+
+	// maxBatchAccounts is the maximum number of
+	// accounts in a BatchAccountsMethod call.
+	maxBatchAccounts = 1000
+
+	// ScanXpubMethod is the /call method that discovers
+	// the used P2PKH addresses of a BIP44 account
+	// extended public key and returns their balance,
+	// coins and the next unused addresses.
+	ScanXpubMethod = "scan_xpub"
+
+	// defaultGapLimit is the number of consecutive
+	// unused addresses after which ScanXpubMethod
+	// stops scanning a chain (as in BIP44).
+	defaultGapLimit = 20
+
+	// maxGapLimit is the maximum gap limit
+	// of a ScanXpubMethod call.
+	maxGapLimit = 100
+
+	// ReceiveChain and ChangeChain are the BIP44 chains
+	// of receive and change addresses.
+	ReceiveChain = 0
+	ChangeChain  = 1
+
+	// AccountHistoryMethod is the /call method that
+	// returns the transactions of an account, from
+	// the most recent block, one page at a time.
+	AccountHistoryMethod = "account_history"
+
+	// defaultHistoryLimit is the number of transactions
+	// returned by AccountHistoryMethod if no limit is
+	// provided.
+	defaultHistoryLimit = int64(50)
+
+	// maxHistoryLimit is the maximum number of
+	// transactions returned by AccountHistoryMethod.
+	maxHistoryLimit = int64(1000)
+
+	// StatisticsMethod is the /call method that returns
+	// the supply, the number of unspent coins and funded
+	// addresses, and the addresses with the largest
+	// balances.
+	StatisticsMethod = "statistics"
+
+	// defaultRichListLimit is the number of addresses
+	// returned by StatisticsMethod if no limit is
+	// provided.
+	defaultRichListLimit = int64(100)
+
+	// maxRichListLimit is the maximum number of
+	// addresses returned by StatisticsMethod.
+	maxRichListLimit = int64(1000)
+
+	// zeroValue is 0 as a string
+	zeroValue = "0"
+
+	// LargestFirstStrategy selects the largest coins
+	// until the outputs and fee are funded.
+	LargestFirstStrategy = "largest_first"
+
+	// BranchAndBoundStrategy searches for a set of coins
+	// that funds the outputs and fee without change,
+	// falling back to LargestFirstStrategy.
+	BranchAndBoundStrategy = "branch_and_bound"
+
+	// PrivacyStrategy prefers spending a single coin,
+	// falling back to selecting coins at random so that
+	// coins are not consistently merged by size.
+	PrivacyStrategy = "privacy"
+
+	// anyoneCanPaySuffix is appended to a signature
+	// hash type name when SigHashAnyOneCanPay is set.
+	anyoneCanPaySuffix = "|ANYONECANPAY"
 )
 
+// CallMethods are all supported /call methods.
+var CallMethods = []string{
+	SelectCoinsMethod,
+	FindTransactionMethod,
+	AccountCoinsMethod,
+	BatchAccountsMethod,
+	ScanXpubMethod,
+	AccountHistoryMethod,
+	StatisticsMethod,
+}
+
+// SignatureTypes are the types of signatures accepted
+// by /construction/combine. INPUT operations request
+// ecdsa_recovery signatures with the signature_type
+// metadata field. They are advertised in the version
+// metadata of /network/options.
+var SignatureTypes = []types.SignatureType{
+	types.Ecdsa,
+	types.EcdsaRecovery,
+}
+
+// sigHashTypeNames are the names of the base signature
+// hash types accepted in INPUT operation metadata.
+var sigHashTypeNames = map[txscript.SigHashType]string{
+	txscript.SigHashAll:    "ALL",
+	txscript.SigHashNone:   "NONE",
+	txscript.SigHashSingle: "SINGLE",
+}
+
 // Client is used by the servicers to get Peer information
 // and to submit transactions.
 type Client interface {
+	GetBlockchainInfo(context.Context) (*thought.BlockchainInfo, error)
 	GetPeers(context.Context) ([]*types.Peer, error)
 	SendRawTransaction(context.Context, string) (string, error)
 	SuggestedFeeRate(context.Context, int64) (float64, error)
@@ -71,7 +228,17 @@ type Indexer interface {
 	GetCoins(
 		context.Context,
 		*types.AccountIdentifier,
+		bool,
+		*types.PartialBlockIdentifier,
 	) ([]*types.Coin, *types.BlockIdentifier, error)
+	GetAccounts(
+		context.Context,
+		[]*types.AccountIdentifier,
+		*types.Currency,
+		bool,
+		bool,
+		*types.PartialBlockIdentifier,
+	) ([]*types.Amount, [][]*types.Coin, *types.BlockIdentifier, error)
 	GetScriptPubKeys(
 		context.Context,
 		[]*types.Coin,
@@ -82,6 +249,43 @@ type Indexer interface {
 		*types.Currency,
 		*types.PartialBlockIdentifier,
 	) (*types.Amount, *types.BlockIdentifier, error)
+	GetMempoolTransaction(
+		context.Context,
+		*types.TransactionIdentifier,
+	) (*types.Transaction, error)
+	GetMempoolBalance(
+		context.Context,
+		*types.AccountIdentifier,
+		*types.Currency,
+	) (*types.Amount, error)
+	SearchTransactions(
+		context.Context,
+		*types.SearchTransactionsRequest,
+		int64,
+		int64,
+	) (*types.SearchTransactionsResponse, error)
+	GetBlockEvents(
+		context.Context,
+		*int64,
+		int64,
+	) ([]*types.BlockEvent, int64, error)
+	FindTransaction(
+		context.Context,
+		*types.TransactionIdentifier,
+	) (*types.BlockIdentifier, *types.Transaction, error)
+	GetAccountHistory(
+		context.Context,
+		*types.AccountIdentifier,
+		string,
+		*int64,
+		*int64,
+		int64,
+	) ([]*thought.HistoryTransaction, string, error)
+	GetStatistics(
+		context.Context,
+		*types.Currency,
+		int64,
+	) (*thought.Statistics, error)
 }
 
 type unsignedTransaction struct {
@@ -89,16 +293,20 @@ type unsignedTransaction struct {
 	ScriptPubKeys  []*thought.ScriptPubKey `json:"scriptPubKeys"`
 	InputAmounts   []string                `json:"input_amounts"`
 	InputAddresses []string                `json:"input_addresses"`
+	RedeemScripts  []string                `json:"redeem_scripts,omitempty"`
+	SigHashTypes   []txscript.SigHashType  `json:"sighash_types,omitempty"`
 }
 
 type preprocessOptions struct {
 	Coins         []*types.Coin `json:"coins"`
 	EstimatedSize float64       `json:"estimated_size"`
 	FeeMultiplier *float64      `json:"fee_multiplier,omitempty"`
+	LockTime      *uint32       `json:"lock_time,omitempty"`
 }
 
 type constructionMetadata struct {
 	ScriptPubKeys []*thought.ScriptPubKey `json:"script_pub_keys"`
+	LockTime      *uint32                 `json:"lock_time,omitempty"`
 }
 
 type signedTransaction struct {
@@ -106,6 +314,279 @@ type signedTransaction struct {
 	InputAmounts []string `json:"input_amounts"`
 }
 
+// AccountBalanceMetadata is returned from
+// AccountBalance when fetching the current balance.
+type AccountBalanceMetadata struct {
+	// PendingBalance is the balance of the account
+	// once all transactions in the mempool are confirmed.
+	PendingBalance *types.Amount `json:"pending_balance"`
+}
+
+// InputMetadata is the metadata accepted on INPUT
+// operations provided to /construction/preprocess
+// and /construction/payloads.
+type InputMetadata struct {
+	// RedeemScript is the hex-encoded multisig script
+	// committed to by the P2SH output being spent.
+	RedeemScript string `json:"redeem_script,omitempty"`
+
+	// Signers are the hex-encoded public keys in
+	// RedeemScript that will sign the input. If not
+	// populated, the first keys required by the
+	// script are used.
+	Signers []string `json:"signers,omitempty"`
+
+	// Sequence is the sequence number of the input. If not
+	// populated, inputs are final unless a lock time is set.
+	Sequence *uint32 `json:"sequence,omitempty"`
+
+	// SigHashType is the signature hash type used to sign
+	// the input. It is one of ALL (default), NONE or SINGLE,
+	// optionally followed by |ANYONECANPAY.
+	SigHashType string `json:"sighash_type,omitempty"`
+
+	// SignatureType is the type of the signatures requested
+	// in the signing payloads of the input. It is one of
+	// SignatureTypes (ecdsa by default).
+	SignatureType types.SignatureType `json:"signature_type,omitempty"`
+}
+
+// TransactionMetadata is the metadata accepted by
+// /construction/preprocess and returned from
+// /construction/parse.
+type TransactionMetadata struct {
+	// LockTime is the block height (below 500000000) or
+	// unix timestamp before which the transaction cannot
+	// be included in a block.
+	LockTime *uint32 `json:"lock_time,omitempty"`
+}
+
+// OutputMetadata is the metadata accepted on OUTPUT
+// operations provided to /construction/preprocess
+// and /construction/payloads.
+type OutputMetadata struct {
+	// OPReturn is the hex-encoded data to embed in a
+	// zero-value OP_RETURN output. OUTPUT operations
+	// with OPReturn populated must not have an account.
+	OPReturn string `json:"op_return,omitempty"`
+}
+
+// SelectCoinsParameters are the parameters
+// of the SelectCoinsMethod /call method.
+type SelectCoinsParameters struct {
+	// Account is the P2PKH account funding the outputs.
+	// Other accounts are rejected with ErrCallParametersInvalid.
+	Account *types.AccountIdentifier `json:"account"`
+
+	// Outputs are the OUTPUT operations to fund, in the
+	// format accepted by /construction/preprocess.
+	Outputs []*types.Operation `json:"outputs"`
+
+	// FeeMultiplier scales the suggested fee rate.
+	FeeMultiplier *float64 `json:"fee_multiplier,omitempty"`
+
+	// Strategy is one of LargestFirstStrategy (default),
+	// BranchAndBoundStrategy or PrivacyStrategy.
+	Strategy string `json:"strategy,omitempty"`
+
+	// ChangeAddress receives any change. If not
+	// populated, change is returned to Account.
+	ChangeAddress string `json:"change_address,omitempty"`
+}
+
+// SelectCoinsResult is the result of
+// the SelectCoinsMethod /call method.
+type SelectCoinsResult struct {
+	// Operations spend the selected coins and
+	// create the outputs and change (if any). They
+	// can be provided to /construction/preprocess.
+	Operations []*types.Operation `json:"operations"`
+
+	// Fee is the difference between the
+	// selected coins and the outputs.
+	Fee *types.Amount `json:"fee"`
+}
+
+// FindTransactionParameters are the parameters
+// of the FindTransactionMethod /call method. The
+// result is a *types.BlockTransaction.
+type FindTransactionParameters struct {
+	TransactionIdentifier *types.TransactionIdentifier `json:"transaction_identifier"`
+}
+
+// AccountCoinsParameters are the parameters of
+// the AccountCoinsMethod /call method. The result
+// is a *types.AccountCoinsResponse.
+type AccountCoinsParameters struct {
+	AccountIdentifier *types.AccountIdentifier `json:"account_identifier"`
+
+	// BlockIdentifier is the block at which coins
+	// are returned. If it is not populated, coins
+	// are returned at the current block.
+	BlockIdentifier *types.PartialBlockIdentifier `json:"block_identifier,omitempty"`
+}
+
+// BatchAccountsParameters are the parameters of
+// the BatchAccountsMethod /call method.
+type BatchAccountsParameters struct {
+	AccountIdentifiers []*types.AccountIdentifier `json:"account_identifiers"`
+
+	// BlockIdentifier is the block at which balances
+	// and coins are returned. If it is not populated,
+	// they are returned at the current block. Coins at
+	// a past block require the search index to cover
+	// every later block.
+	BlockIdentifier *types.PartialBlockIdentifier `json:"block_identifier,omitempty"`
+
+	IncludeBalances bool `json:"include_balances"`
+	IncludeCoins    bool `json:"include_coins"`
+}
+
+// BatchAccountsResult is the result of
+// the BatchAccountsMethod /call method.
+type BatchAccountsResult struct {
+	BlockIdentifier *types.BlockIdentifier `json:"block_identifier"`
+
+	// Accounts are in the order of the
+	// requested account identifiers.
+	Accounts []*AccountState `json:"accounts"`
+}
+
+// ScanXpubParameters are the parameters
+// of the ScanXpubMethod /call method.
+type ScanXpubParameters struct {
+	// Xpub is the extended public key of a
+	// BIP44 account (m/44'/coin_type'/account').
+	Xpub string `json:"xpub"`
+
+	// GapLimit defaults to defaultGapLimit.
+	GapLimit int64 `json:"gap_limit,omitempty"`
+}
+
+// ScanXpubResult is the result of
+// the ScanXpubMethod /call method.
+type ScanXpubResult struct {
+	BlockIdentifier *types.BlockIdentifier `json:"block_identifier"`
+
+	// Balance and Coins are aggregated
+	// over all used addresses.
+	Balance *types.Amount `json:"balance"`
+	Coins   []*types.Coin `json:"coins"`
+
+	// Addresses are the used addresses of the
+	// account, each with its balance.
+	Addresses []*XpubAddress `json:"addresses"`
+
+	// NextReceiveAddress and NextChangeAddress are
+	// the first unused address of each chain.
+	NextReceiveAddress *XpubAddress `json:"next_receive_address"`
+	NextChangeAddress  *XpubAddress `json:"next_change_address"`
+}
+
+// XpubAddress is an address derived from an extended
+// public key at the path chain/index.
+type XpubAddress struct {
+	Address string        `json:"address"`
+	Chain   uint32        `json:"chain"`
+	Index   uint32        `json:"index"`
+	Balance *types.Amount `json:"balance,omitempty"`
+}
+
+// AccountState is the balance and coins of an
+// account. Balance and Coins are omitted if they
+// are not requested.
+type AccountState struct {
+	AccountIdentifier *types.AccountIdentifier `json:"account_identifier"`
+	Balance           *types.Amount            `json:"balance,omitempty"`
+	Coins             []*types.Coin            `json:"coins,omitempty"`
+}
+
+// AccountHistoryParameters are the parameters
+// of the AccountHistoryMethod /call method.
+type AccountHistoryParameters struct {
+	AccountIdentifier *types.AccountIdentifier `json:"account_identifier"`
+
+	// Cursor is the NextCursor of the previous
+	// page. The first page is returned if it is
+	// not populated.
+	Cursor string `json:"cursor,omitempty"`
+
+	// Limit defaults to defaultHistoryLimit.
+	Limit int64 `json:"limit,omitempty"`
+
+	// MinIndex and MaxIndex limit the transactions
+	// to those in blocks in the (inclusive) range.
+	MinIndex *int64 `json:"min_index,omitempty"`
+	MaxIndex *int64 `json:"max_index,omitempty"`
+}
+
+// AccountHistoryResult is the result of
+// the AccountHistoryMethod /call method.
+type AccountHistoryResult struct {
+	Transactions []*AccountTransaction `json:"transactions"`
+
+	// NextCursor is populated if
+	// there are more transactions.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// AccountTransaction is a transaction of an account and
+// the net amount it adds to the balance of the account
+// (negative if the balance decreases).
+type AccountTransaction struct {
+	BlockIdentifier       *types.BlockIdentifier       `json:"block_identifier"`
+	TransactionIdentifier *types.TransactionIdentifier `json:"transaction_identifier"`
+	NetAmount             *types.Amount                `json:"net_amount"`
+}
+
+// StatisticsParameters are the parameters
+// of the StatisticsMethod /call method.
+type StatisticsParameters struct {
+	// Limit is the number of addresses in the
+	// rich list and defaults to defaultRichListLimit.
+	Limit *int64 `json:"limit,omitempty"`
+}
+
+// StatisticsResult is the result of
+// the StatisticsMethod /call method.
+type StatisticsResult struct {
+	BlockIdentifier *types.BlockIdentifier `json:"block_identifier"`
+
+	// Supply is the value of all unspent coins.
+	Supply             *types.Amount `json:"supply"`
+	CoinCount          int64         `json:"coin_count"`
+	FundedAddressCount int64         `json:"funded_address_count"`
+
+	// RichList is the addresses with the largest
+	// balances, in descending order of balance.
+	RichList []*AccountState `json:"rich_list"`
+}
+
+// DeriveMetadata is the metadata accepted by
+// /construction/derive.
+type DeriveMetadata struct {
+	// AddressType is one of P2PKHAddressType (default),
+	// P2PKAddressType or P2SHMultisigAddressType.
+	AddressType string `json:"address_type,omitempty"`
+
+	// PublicKeys are the hex-encoded public keys of a
+	// multisig address, in the order they should appear
+	// in the redeem script.
+	PublicKeys []string `json:"public_keys,omitempty"`
+
+	// Threshold is the number of signatures required
+	// to spend from a multisig address.
+	Threshold int `json:"threshold,omitempty"`
+}
+
+// DeriveResponseMetadata is returned from
+// /construction/derive for multisig addresses.
+type DeriveResponseMetadata struct {
+	// RedeemScript is the hex-encoded script that must
+	// be provided to spend from the address.
+	RedeemScript string `json:"redeem_script"`
+}
+
 // ParseOperationMetadata is returned from
 // ConstructionParse.
 type ParseOperationMetadata struct {
@@ -0,0 +1,180 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thoughtnetwork/rosetta-thought/thought"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/util/hdkeychain"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// scanXpub discovers the used addresses of the receive and
+// change chains of an extended public key, stopping each
+// chain after parameters.GapLimit consecutive unused addresses.
+func (s *CallAPIService) scanXpub(
+	ctx context.Context,
+	parameters *ScanXpubParameters,
+) (*ScanXpubResult, *types.Error) {
+	key, err := hdkeychain.NewKeyFromString(parameters.Xpub, s.config.Params)
+	if err != nil {
+		return nil, wrapErr(ErrCallParametersInvalid, fmt.Errorf("%w: unable to parse xpub", err))
+	}
+
+	gapLimit := parameters.GapLimit
+	if gapLimit == 0 {
+		gapLimit = defaultGapLimit
+	}
+	if gapLimit < 0 || gapLimit > maxGapLimit {
+		return nil, wrapErr(
+			ErrCallParametersInvalid,
+			fmt.Errorf("gap limit must be between 1 and %d", maxGapLimit),
+		)
+	}
+
+	result := &ScanXpubResult{
+		Addresses: []*XpubAddress{},
+	}
+	for _, chain := range []uint32{ReceiveChain, ChangeChain} {
+		chainKey, err := key.Derive(chain)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDerive, err)
+		}
+
+		var next *XpubAddress
+		gap := int64(0)
+		for index := uint32(0); gap < gapLimit; index++ {
+			childKey, err := chainKey.Derive(index)
+			if errors.Is(err, hdkeychain.ErrInvalidChild) {
+				continue
+			}
+			if err != nil {
+				return nil, wrapErr(ErrUnableToDerive, err)
+			}
+
+			address, err := childKey.Address(s.config.Params)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToDerive, err)
+			}
+
+			xpubAddress := &XpubAddress{
+				Address: address.EncodeAddress(),
+				Chain:   chain,
+				Index:   index,
+			}
+			used, rosettaErr := s.addressUsed(ctx, xpubAddress.Address)
+			if rosettaErr != nil {
+				return nil, rosettaErr
+			}
+
+			if used {
+				result.Addresses = append(result.Addresses, xpubAddress)
+				gap = 0
+				continue
+			}
+
+			if next == nil {
+				next = xpubAddress
+			}
+			gap++
+		}
+
+		if chain == ReceiveChain {
+			result.NextReceiveAddress = next
+		} else {
+			result.NextChangeAddress = next
+		}
+	}
+
+	// Balances and coins of all used addresses are
+	// read at a single block.
+	accounts := make([]*types.AccountIdentifier, len(result.Addresses))
+	for i, address := range result.Addresses {
+		accounts[i] = &types.AccountIdentifier{Address: address.Address}
+	}
+
+	balances, coins, block, err := s.i.GetAccounts(
+		ctx,
+		accounts,
+		s.config.Currency,
+		true,
+		true,
+		nil,
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetBalance, err)
+	}
+
+	total := zeroValue
+	result.Coins = []*types.Coin{}
+	for i, address := range result.Addresses {
+		address.Balance = balances[i]
+		total, err = types.AddValues(total, balances[i].Value)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToGetBalance, err)
+		}
+
+		result.Coins = append(result.Coins, coins[i]...)
+	}
+
+	result.BlockIdentifier = block
+	result.Balance = &types.Amount{
+		Value:    total,
+		Currency: s.config.Currency,
+	}
+
+	return result, nil
+}
+
+// addressUsed returns true if an address appears in any
+// indexed transaction or has a balance. The balance covers
+// blocks indexed before the transaction search index (which
+// cannot be searched until they are resynced).
+func (s *CallAPIService) addressUsed(
+	ctx context.Context,
+	address string,
+) (bool, *types.Error) {
+	response, err := s.i.SearchTransactions(
+		ctx,
+		&types.SearchTransactionsRequest{Address: types.String(address)},
+		0,
+		1,
+	)
+	// If the search index is incomplete, only
+	// the balance of the address is checked.
+	if err != nil && !errors.Is(err, thought.ErrSearchIndexIncomplete) {
+		return false, wrapErr(ErrUnableToSearchTransactions, err)
+	}
+
+	if err == nil && response.TotalCount > 0 {
+		return true, nil
+	}
+
+	balance, _, err := s.i.GetBalance(
+		ctx,
+		&types.AccountIdentifier{Address: address},
+		s.config.Currency,
+		nil,
+	)
+	if err != nil {
+		return false, wrapErr(ErrUnableToGetBalance, err)
+	}
+
+	return balance.Value != zeroValue, nil
+}
@@ -17,6 +17,8 @@ package thought
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,8 +26,10 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/thoughtnetwork/rosetta-thought/metrics"
 	thoughtUtils "github.com/thoughtnetwork/rosetta-thought/utils"
 	"github.com/thoughtnetwork/rosetta-thought/thoughtd/util"
 
@@ -51,6 +55,11 @@ const (
 	// * 1 returns the JSON representation
 	// * 2 returns the JSON representation with included Transaction data
 	blockVerbosity = 2
+
+	// mempoolTransactionIndex is the index provided when parsing
+	// a transaction that is not part of a block. A mempool transaction
+	// can never be the coinbase transaction.
+	mempoolTransactionIndex = -1
 )
 
 type requestMethod string
@@ -80,8 +89,18 @@ const (
 	// https://developer.bitcoin.org/reference/rpc/getrawmempool.html
 	requestMethodRawMempool requestMethod = "getrawmempool"
 
+	// https://developer.bitcoin.org/reference/rpc/getrawtransaction.html
+	requestMethodGetRawTransaction requestMethod = "getrawtransaction"
+
+	// https://developer.bitcoin.org/reference/rpc/getmempoolentry.html
+	requestMethodGetMempoolEntry requestMethod = "getmempoolentry"
+
 	// blockNotFoundErrCode is the RPC error code when a block cannot be found
 	blockNotFoundErrCode = -5
+
+	// transactionNotFoundErrCode is the RPC error code when a transaction
+	// cannot be found (or is not in the mempool)
+	transactionNotFoundErrCode = -5
 )
 
 const (
@@ -92,10 +111,10 @@ const (
 	// returned in Thought blocks to be milliseconds.
 	timeMultiplier = 1000
 
-	// rpc credentials are fixed in rosetta-thought
-	// because we never expose access to the raw thoughtd
-	// endpoints (that could be used perform an attack, like
-	// changing our peers).
+	// rpc credentials of the thoughtd launched by
+	// rosetta-thought are fixed because we never expose
+	// access to the raw thoughtd endpoints (that could be
+	// used perform an attack, like changing our peers).
 	rpcUsername = "rosetta"
 	rpcPassword = "rosetta"
 )
@@ -105,6 +124,15 @@ var (
 	// cannot be found by the node
 	ErrBlockNotFound = errors.New("unable to find block")
 
+	// ErrTransactionNotFound is returned when the requested transaction
+	// cannot be found by the node
+	ErrTransactionNotFound = errors.New("unable to find transaction")
+
+	// ErrSearchIndexIncomplete is returned when the search index
+	// does not cover the blocks requested because it was introduced
+	// after they were indexed
+	ErrSearchIndexIncomplete = errors.New("search index does not cover this range, resync")
+
 	// ErrJSONRPCError is returned when receiving an error from a JSON-RPC response
 	ErrJSONRPCError = errors.New("JSON-RPC error")
 )
@@ -118,38 +146,124 @@ var (
 type Client struct {
 	baseURL string
 
+	// username and password authenticate RPC requests
+	// unless cookieFile is populated, in which case the
+	// credentials are read from it.
+	username   string
+	password   string
+	cookieFile string
+	tlsConfig  *tls.Config
+
 	genesisBlockIdentifier *types.BlockIdentifier
 	currency               *types.Currency
 
 	httpClient *http.Client
 }
 
+// ClientOption is used to overwrite default values
+// when constructing a *Client.
+type ClientOption func(c *Client)
+
+// WithCredentials sets the username and password
+// used to authenticate RPC requests.
+func WithCredentials(username string, password string) ClientOption {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithCookieFile authenticates RPC requests with the
+// credentials in a thoughtd cookie file. The file is read
+// on each request because thoughtd rewrites it whenever it
+// restarts.
+func WithCookieFile(cookieFile string) ClientOption {
+	return func(c *Client) {
+		c.cookieFile = cookieFile
+	}
+}
+
+// WithTLSConfig sets the TLS configuration
+// of connections to an https baseURL.
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// LoadTLSConfig returns the *tls.Config that verifies the server
+// certificate with the CA certificates in caFile (or the system
+// roots if it is empty) and presents the client certificate in
+// certFile and keyFile (if populated).
+func LoadTLSConfig(caFile string, certFile string, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(caFile) > 0 {
+		ca, err := ioutil.ReadFile(caFile) // #nosec G304
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read %s", err, caFile)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("%s does not contain any PEM certificates", caFile)
+		}
+	}
+
+	if len(certFile) > 0 || len(keyFile) > 0 {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load client certificate", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
 // LocalhostURL returns the URL to use
 // for a client that is running at localhost.
 func LocalhostURL(rpcPort int) string {
 	return fmt.Sprintf("http://localhost:%d", rpcPort)
 }
 
-// NewClient creates a new Thought client.
+// NewClient creates a new Thought client. Requests are
+// authenticated with the credentials of the thoughtd
+// launched by rosetta-thought unless overwritten by
+// options.
 func NewClient(
 	baseURL string,
 	genesisBlockIdentifier *types.BlockIdentifier,
 	currency *types.Currency,
+	options ...ClientOption,
 ) *Client {
-	return &Client{
+	client := &Client{
 		baseURL:                baseURL,
+		username:               rpcUsername,
+		password:               rpcPassword,
 		genesisBlockIdentifier: genesisBlockIdentifier,
 		currency:               currency,
-		httpClient:             newHTTPClient(defaultTimeout),
 	}
+
+	for _, opt := range options {
+		opt(client)
+	}
+
+	client.httpClient = newHTTPClient(defaultTimeout, client.tlsConfig)
+
+	return client
 }
 
 // newHTTPClient returns a new HTTP client
-func newHTTPClient(timeout time.Duration) *http.Client {
+func newHTTPClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
 	var netTransport = &http.Transport{
 		Dial: (&net.Dialer{
 			Timeout: dialTimeout,
 		}).Dial,
+		TLSClientConfig: tlsConfig,
 	}
 
 	httpClient := &http.Client{
@@ -337,6 +451,119 @@ func (b *Client) RawMempool(
 	return response.Result, nil
 }
 
+// GetRawTransaction returns the decoded representation of a
+// transaction. Because thoughtd runs without a transaction index,
+// this only succeeds for transactions in the mempool.
+func (b *Client) GetRawTransaction(
+	ctx context.Context,
+	hash string,
+) (*Transaction, error) {
+	// Parameters:
+	//   1. txid
+	//   2. verbose
+	params := []interface{}{hash, true}
+
+	response := &rawTransactionResponse{}
+	if err := b.post(ctx, requestMethodGetRawTransaction, params, response); err != nil {
+		return nil, fmt.Errorf("%w: error getting raw transaction %s", err, hash)
+	}
+
+	return response.Result, nil
+}
+
+// GetMempoolEntry returns the mempool data for a
+// transaction. It returns ErrTransactionNotFound if
+// the transaction is not in the mempool.
+func (b *Client) GetMempoolEntry(
+	ctx context.Context,
+	hash string,
+) (*MempoolEntry, error) {
+	// Parameters:
+	//   1. txid
+	params := []interface{}{hash}
+
+	response := &mempoolEntryResponse{}
+	if err := b.post(ctx, requestMethodGetMempoolEntry, params, response); err != nil {
+		return nil, fmt.Errorf("%w: error getting mempool entry %s", err, hash)
+	}
+
+	return response.Result, nil
+}
+
+// ParseTransaction returns a parsed thought transaction given a raw
+// transaction that is not part of a block (i.e. in the mempool) and
+// a map of coins spent by its inputs.
+func (b *Client) ParseTransaction(
+	ctx context.Context,
+	transaction *Transaction,
+	coins map[string]*types.AccountCoin,
+) (*types.Transaction, error) {
+	if transaction == nil {
+		return nil, errors.New("error parsing nil transaction")
+	}
+
+	txOps, err := b.parseTxOperations(transaction, mempoolTransactionIndex, coins)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error parsing transaction operations", err)
+	}
+
+	metadata, err := transaction.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get metadata for transaction", err)
+	}
+
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: transaction.Hash,
+		},
+		Operations: txOps,
+		Metadata:   metadata,
+	}, nil
+}
+
+// ParseCoins returns the coins created by the outputs
+// of a raw transaction, keyed by coin identifier. Outputs
+// that do not create a coin (OP_RETURN) are skipped.
+func (b *Client) ParseCoins(
+	transaction *Transaction,
+) (map[string]*types.AccountCoin, error) {
+	if transaction == nil {
+		return nil, errors.New("error parsing nil transaction")
+	}
+
+	coins := map[string]*types.AccountCoin{}
+	for networkIndex, output := range transaction.Outputs {
+		op, err := b.parseOutputTransactionOperation(
+			output,
+			transaction.Hash,
+			int64(networkIndex),
+			int64(networkIndex),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: error parsing tx output, hash: %s, index: %d",
+				err,
+				transaction.Hash,
+				networkIndex,
+			)
+		}
+
+		if op.CoinChange == nil {
+			continue
+		}
+
+		coins[op.CoinChange.CoinIdentifier.Identifier] = &types.AccountCoin{
+			Account: op.Account,
+			Coin: &types.Coin{
+				CoinIdentifier: op.CoinChange.CoinIdentifier,
+				Amount:         op.Amount,
+			},
+		}
+	}
+
+	return coins, nil
+}
+
 // getPeerInfo performs the `getpeerinfo` JSON-RPC request
 func (b *Client) getPeerInfo(
 	ctx context.Context,
@@ -373,8 +600,8 @@ func (b *Client) getBlock(
 	return response.Result, nil
 }
 
-// getBlockchainInfo performs the `getblockchaininfo` JSON-RPC request
-func (b *Client) getBlockchainInfo(
+// GetBlockchainInfo performs the `getblockchaininfo` JSON-RPC request
+func (b *Client) GetBlockchainInfo(
 	ctx context.Context,
 ) (*BlockchainInfo, error) {
 	response := &blockchainInfoResponse{}
@@ -395,7 +622,7 @@ func (b *Client) getBlockHash(
 ) (string, error) {
 	// Lookup best block if no PartialBlockIdentifier provided.
 	if identifier == nil || (identifier.Hash == nil && identifier.Index == nil) {
-		info, err := b.getBlockchainInfo(ctx)
+		info, err := b.GetBlockchainInfo(ctx)
 		if err != nil {
 			return "", fmt.Errorf("%w: unable to get blockchain info", err)
 		}
@@ -757,10 +984,19 @@ func (b *Client) parseAmount(amount float64) (uint64, error) {
 
 // parseOutputAccount parses a thoughtScriptPubKey and returns an account
 // identifier. The account identifier's address corresponds to the first
-// address encoded in the script.
+// address encoded in the script. Pay-to-pubkey outputs are assigned to
+// the hex-encoded public key (the account /construction/derive returns
+// for the p2pk address type), as thoughtd does not consistently report
+// an address for them.
 func (b *Client) parseOutputAccount(
 	scriptPubKey *ScriptPubKey,
 ) *types.AccountIdentifier {
+	if scriptPubKey.Type == PubKey {
+		if pubKey, err := ParsePubKeyScript(scriptPubKey.Hex); err == nil {
+			return &types.AccountIdentifier{Address: pubKey}
+		}
+	}
+
 	if len(scriptPubKey.Addresses) != 1 {
 		return &types.AccountIdentifier{Address: scriptPubKey.Hex}
 	}
@@ -791,13 +1027,40 @@ func (b *Client) coinbaseTxOperation(
 	}, nil
 }
 
+// setCredentials authenticates an RPC request.
+func (b *Client) setCredentials(req *http.Request) error {
+	if len(b.cookieFile) == 0 {
+		req.SetBasicAuth(b.username, b.password)
+		return nil
+	}
+
+	cookie, err := ioutil.ReadFile(b.cookieFile) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("%w: unable to read cookie file %s", err, b.cookieFile)
+	}
+
+	// The cookie file contains <username>:<password>
+	credentials := strings.SplitN(strings.TrimSpace(string(cookie)), ":", 2) // nolint:gomnd
+	if len(credentials) != 2 { // nolint:gomnd
+		return fmt.Errorf("cookie file %s is invalid", b.cookieFile)
+	}
+
+	req.SetBasicAuth(credentials[0], credentials[1])
+	return nil
+}
+
 // post makes a HTTP request to a Thought node
 func (b *Client) post(
 	ctx context.Context,
 	method requestMethod,
 	params []interface{},
 	response jSONRPCResponse,
-) error {
+) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveRPC(string(method), start, err)
+	}()
+
 	rpcRequest := &request{
 		JSONRPC: jSONRPCVersion,
 		ID:      requestID,
@@ -816,7 +1079,9 @@ func (b *Client) post(
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(rpcUsername, rpcPassword)
+	if err := b.setCredentials(req); err != nil {
+		return err
+	}
 
 	// Perform the post request
 	res, err := b.httpClient.Do(req.WithContext(ctx))
@@ -844,7 +1109,12 @@ func (b *Client) get(
 	ctx context.Context,
 	method requestMethod,
 	response jSONRPCResponse,
-) error {
+) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveRPC(string(method), start, err)
+	}()
+
 	rpcRequest := &request{
 		JSONRPC: jSONRPCVersion,
 		ID:      requestID,
@@ -862,7 +1132,9 @@ func (b *Client) get(
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(rpcUsername, rpcPassword)
+	if err := b.setCredentials(req); err != nil {
+		return err
+	}
 
 	// Perform the get request
 	res, err := b.httpClient.Do(req.WithContext(ctx))
@@ -16,12 +16,14 @@ package thought
 
 import (
 	"context"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"testing"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -290,6 +292,48 @@ var (
 			},
 		},
 	}
+
+	mempoolTransaction = &Transaction{
+		Hex:     "03000000",
+		Hash:    "9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab",
+		Size:    234,
+		Version: 3,
+		Inputs: []*Input{
+			{
+				TxHash: "37b4fcc8e0b229412faeab8baad45d3eb8e4eec41840d6ac2103987163459e75",
+				Vout:   1,
+				ScriptSig: &ScriptSig{
+					ASM: "3044022040a1c631554b8b210fbdf2a73f191b2851afb51d5171fb53502a3a040a38d2c0022040d11cf6e7b41fe1b66c3d08f6ada1aee07a047cb77f242b8ecc63812c832c9a[ALL] 02bcfad931b502761e452962a5976c79158a0f6d307ad31b739611dac6a297c256", // nolint
+					Hex: "473044022040a1c631554b8b210fbdf2a73f191b2851afb51d5171fb53502a3a040a38d2c0022040d11cf6e7b41fe1b66c3d08f6ada1aee07a047cb77f242b8ecc63812c832c9a012102bcfad931b502761e452962a5976c79158a0f6d307ad31b739611dac6a297c256", // nolint
+				},
+				Sequence: 4294967295,
+			},
+		},
+		Outputs: []*Output{
+			{
+				Value: 0.0381,
+				Index: 0,
+				ScriptPubKey: &ScriptPubKey{
+					ASM:          "OP_DUP OP_HASH160 45db0b779c0b9fa207f12a8218c94fc77aff5045 OP_EQUALVERIFY OP_CHECKSIG",
+					Hex:          "76a91445db0b779c0b9fa207f12a8218c94fc77aff504588ac",
+					RequiredSigs: 1,
+					Type:         "pubkeyhash",
+					Addresses: []string{
+						"mmtKKnjqTPdkBnBMbNt5Yu2SCwpMaEshEL",
+					},
+				},
+			},
+			{
+				Value: 0,
+				Index: 1,
+				ScriptPubKey: &ScriptPubKey{
+					ASM:  "OP_RETURN 68656c6c6f",
+					Hex:  "6a0568656c6c6f",
+					Type: NullData,
+				},
+			},
+		},
+	}
 )
 
 func TestNetworkStatus(t *testing.T) {
@@ -748,7 +792,7 @@ func TestParseBlock(t *testing.T) {
 								Type:   OutputOpType,
 								Status: types.String(SuccessStatus),
 								Account: &types.AccountIdentifier{
-									Address: "4104f5eeb2b10c944c6b9fbcfff94c35bdeecd93df977882babc7f3a2cf7f5c81d3b09a68db7f0e04f21de5d4230e75e6dbe7ad16eefe0d4325a62067dc6f369446aac", // nolint
+									Address: "04f5eeb2b10c944c6b9fbcfff94c35bdeecd93df977882babc7f3a2cf7f5c81d3b09a68db7f0e04f21de5d4230e75e6dbe7ad16eefe0d4325a62067dc6f369446a", // nolint
 								},
 								Amount: &types.Amount{
 									Value:    "5000000000",
@@ -1411,6 +1455,391 @@ func TestRawMempool(t *testing.T) {
 	}
 }
 
+func TestClientCredentials(t *testing.T) {
+	dir := t.TempDir()
+	cookieFile := path.Join(dir, ".cookie")
+	assert.NoError(t, ioutil.WriteFile(cookieFile, []byte("__cookie__:secret\n"), 0600))
+
+	invalidCookieFile := path.Join(dir, "invalid.cookie")
+	assert.NoError(t, ioutil.WriteFile(invalidCookieFile, []byte("secret"), 0600))
+
+	tests := map[string]struct {
+		options []ClientOption
+
+		expectedUsername string
+		expectedPassword string
+		expectedError    error
+	}{
+		"default": {
+			expectedUsername: rpcUsername,
+			expectedPassword: rpcPassword,
+		},
+		"credentials": {
+			options:          []ClientOption{WithCredentials("user", "password")},
+			expectedUsername: "user",
+			expectedPassword: "password",
+		},
+		"cookie file": {
+			options:          []ClientOption{WithCookieFile(cookieFile)},
+			expectedUsername: "__cookie__",
+			expectedPassword: "secret",
+		},
+		"missing cookie file": {
+			options:       []ClientOption{WithCookieFile(path.Join(dir, "missing"))},
+			expectedError: errors.New("unable to read cookie file"),
+		},
+		"invalid cookie file": {
+			options:       []ClientOption{WithCookieFile(invalidCookieFile)},
+			expectedError: fmt.Errorf("cookie file %s is invalid", invalidCookieFile),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				assert.True(ok)
+				assert.Equal(test.expectedUsername, username)
+				assert.Equal(test.expectedPassword, password)
+
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintln(w, loadFixture("raw_mempool.json"))
+			}))
+			defer ts.Close()
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency, test.options...)
+			_, err := client.RawMempool(context.Background())
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, loadFixture("raw_mempool.json"))
+	}))
+	defer ts.Close()
+
+	// The certificate of the server is unknown
+	// without its CA.
+	client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+	_, err := client.RawMempool(context.Background())
+	assert.Error(t, err)
+
+	caFile := path.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	assert.NoError(t, ioutil.WriteFile(caFile, ca, 0600))
+
+	tlsConfig, err := LoadTLSConfig(caFile, "", "")
+	assert.NoError(t, err)
+
+	client = NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency, WithTLSConfig(tlsConfig))
+	txs, err := client.RawMempool(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, txs, 3)
+
+	_, err = LoadTLSConfig(path.Join(t.TempDir(), "missing.pem"), "", "")
+	assert.Error(t, err)
+}
+
+func TestGetRawTransaction(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedTransaction *Transaction
+		expectedError       error
+	}{
+		"successful": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_raw_transaction_response.json"),
+					url:    url,
+				},
+			},
+			expectedTransaction: mempoolTransaction,
+		},
+		"not found": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_raw_transaction_not_found_response.json"),
+					url:    url,
+				},
+			},
+			expectedError: ErrTransactionNotFound,
+		},
+		"500 error": {
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   "{}",
+					url:    url,
+				},
+			},
+			expectedError: errors.New("invalid response: 500 Internal Server Error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			tx, err := client.GetRawTransaction(
+				context.Background(),
+				"9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab",
+			)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedTransaction, tx)
+			}
+		})
+	}
+}
+
+func TestGetMempoolEntry(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedEntry *MempoolEntry
+		expectedError error
+	}{
+		"successful": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_mempool_entry_response.json"),
+					url:    url,
+				},
+			},
+			expectedEntry: &MempoolEntry{
+				Size:   234,
+				Fee:    0.00000234,
+				Time:   1601398200,
+				Height: 1000,
+				Depends: []string{
+					"37b4fcc8e0b229412faeab8baad45d3eb8e4eec41840d6ac2103987163459e75",
+				},
+			},
+		},
+		"not in mempool": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_mempool_entry_not_found_response.json"),
+					url:    url,
+				},
+			},
+			expectedError: ErrTransactionNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			entry, err := client.GetMempoolEntry(
+				context.Background(),
+				"9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab",
+			)
+			if test.expectedError != nil {
+				assert.True(errors.Is(err, test.expectedError))
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedEntry, entry)
+			}
+		})
+	}
+}
+
+func TestParseTransaction(t *testing.T) {
+	spentCoin := &types.AccountCoin{
+		Account: &types.AccountIdentifier{
+			Address: "mvRcu7gGRp9nmRrpGhLdBJcn6BwvV8jNrS",
+		},
+		Coin: &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "37b4fcc8e0b229412faeab8baad45d3eb8e4eec41840d6ac2103987163459e75:1",
+			},
+			Amount: &types.Amount{
+				Value:    "3820000",
+				Currency: MainnetCurrency,
+			},
+		},
+	}
+	outputOperation := &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{
+			Index:        1,
+			NetworkIndex: int64Pointer(0),
+		},
+		Type:   OutputOpType,
+		Status: types.String(SuccessStatus),
+		Account: &types.AccountIdentifier{
+			Address: "mmtKKnjqTPdkBnBMbNt5Yu2SCwpMaEshEL",
+		},
+		Amount: &types.Amount{
+			Value:    "3810000",
+			Currency: MainnetCurrency,
+		},
+		CoinChange: &types.CoinChange{
+			CoinAction: types.CoinCreated,
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab:0",
+			},
+		},
+		Metadata: mustMarshalMap(&OperationMetadata{
+			ScriptPubKey: mempoolTransaction.Outputs[0].ScriptPubKey,
+		}),
+	}
+
+	tests := map[string]struct {
+		coins map[string]*types.AccountCoin
+
+		expectedTransaction *types.Transaction
+		expectedCoins       map[string]*types.AccountCoin
+		expectedError       error
+	}{
+		"successful": {
+			coins: map[string]*types.AccountCoin{
+				spentCoin.Coin.CoinIdentifier.Identifier: spentCoin,
+			},
+			expectedTransaction: &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{
+					Hash: "9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab",
+				},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index:        0,
+							NetworkIndex: int64Pointer(0),
+						},
+						Type:    InputOpType,
+						Status:  types.String(SuccessStatus),
+						Account: spentCoin.Account,
+						Amount: &types.Amount{
+							Value:    "-3820000",
+							Currency: MainnetCurrency,
+						},
+						CoinChange: &types.CoinChange{
+							CoinAction:     types.CoinSpent,
+							CoinIdentifier: spentCoin.Coin.CoinIdentifier,
+						},
+						Metadata: mustMarshalMap(&OperationMetadata{
+							ScriptSig: mempoolTransaction.Inputs[0].ScriptSig,
+							Sequence:  4294967295,
+						}),
+					},
+					outputOperation,
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index:        2,
+							NetworkIndex: int64Pointer(1),
+						},
+						Type:   OutputOpType,
+						Status: types.String(SuccessStatus),
+						Account: &types.AccountIdentifier{
+							Address: "6a0568656c6c6f",
+						},
+						Amount: &types.Amount{
+							Value:    "0",
+							Currency: MainnetCurrency,
+						},
+						Metadata: mustMarshalMap(&OperationMetadata{
+							ScriptPubKey: mempoolTransaction.Outputs[1].ScriptPubKey,
+						}),
+					},
+				},
+				Metadata: mustMarshalMap(&TransactionMetadata{
+					Size:    234,
+					Version: 3,
+				}),
+			},
+			expectedCoins: map[string]*types.AccountCoin{
+				"9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab:0": {
+					Account: outputOperation.Account,
+					Coin: &types.Coin{
+						CoinIdentifier: outputOperation.CoinChange.CoinIdentifier,
+						Amount:         outputOperation.Amount,
+					},
+				},
+			},
+		},
+		"missing input coin": {
+			coins:         map[string]*types.AccountCoin{},
+			expectedError: errors.New("error finding previous tx"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			client := NewClient("", MainnetGenesisBlockIdentifier, MainnetCurrency)
+			tx, err := client.ParseTransaction(context.Background(), mempoolTransaction, test.coins)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+				return
+			}
+
+			assert.NoError(err)
+			assert.Equal(test.expectedTransaction, tx)
+
+			coins, err := client.ParseCoins(mempoolTransaction)
+			assert.NoError(err)
+			assert.Equal(test.expectedCoins, coins)
+		})
+	}
+}
+
 // loadFixture takes a file name and returns the response fixture.
 func loadFixture(fileName string) string {
 	content, err := ioutil.ReadFile(fmt.Sprintf("client_fixtures/%s", fileName))
@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync/atomic"
 
 	"github.com/thoughtnetwork/rosetta-thought/utils"
 
@@ -61,9 +62,27 @@ func logPipe(ctx context.Context, pipe io.ReadCloser, identifier string) error {
 	}
 }
 
+// Process reports whether the thoughtd
+// started by StartThoughtd is running.
+type Process struct {
+	running atomic.Bool
+}
+
+// Running returns true if thoughtd has
+// started and not yet exited.
+func (p *Process) Running() bool {
+	return p.running.Load()
+}
+
 // StartThoughtd starts a thoughtd daemon in another goroutine
-// and logs the results to the console.
-func StartThoughtd(ctx context.Context, configPath string, g *errgroup.Group) error {
+// and logs the results to the console. Whether thoughtd is
+// running is recorded in process.
+func StartThoughtd(
+	ctx context.Context,
+	configPath string,
+	g *errgroup.Group,
+	process *Process,
+) error {
 	logger := utils.ExtractLogger(ctx, "thoughtd")
 	cmd := exec.Command(
 		"/app/thoughtd",
@@ -92,6 +111,9 @@ func StartThoughtd(ctx context.Context, configPath string, g *errgroup.Group) er
 		return fmt.Errorf("%w: unable to start thoughtd", err)
 	}
 
+	process.running.Store(true)
+	defer process.running.Store(false)
+
 	g.Go(func() error {
 		<-ctx.Done()
 
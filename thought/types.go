@@ -34,6 +34,10 @@ const (
 	// in TestnetNetworkIdentifier.
 	TestnetNetwork string = "Testnet3"
 
+	// RegtestNetwork is the value of the network
+	// in RegtestNetworkIdentifier.
+	RegtestNetwork string = "Regtest"
+
 	// Decimals is the decimals value
 	// used in Currency.
 	Decimals = 8
@@ -73,6 +77,11 @@ const (
 	// as the ScriptPubKey.Type for OP_RETURN
 	// locking scripts.
 	NullData = "nulldata"
+
+	// PubKey is returned by thoughtd
+	// as the ScriptPubKey.Type for
+	// pay-to-pubkey locking scripts.
+	PubKey = "pubkey"
 )
 
 // Fee estimate constants
@@ -83,12 +92,15 @@ const (
 	InputSize             = 148              // P2PKH 1 input
 	OutputOverhead        = 9                // 8 value, 1 script size
 	P2PKHScriptPubkeySize = 25               // P2PKH size
+	InputOverhead         = 41               // 32 prev hash, 4 prev index, 4 sequence, 1 script size
+	MultiSigSignatureSize = 74               // 1 push, 72 DER signature, 1 sighash type
+	DustThreshold         = 546              // P2PKH output value below which thoughtd will not relay
 )
 
 var (
 	// MainnetGenesisBlockIdentifier is the genesis block for mainnet.
 	MainnetGenesisBlockIdentifier = &types.BlockIdentifier{
-		Hash: "00000000917e049641189c33d6b1275155e89b7b498b3b4f16d488f60afe513b",
+		Hash: chaincfg.MainNetParams.GenesisHash.String(),
 	}
 
 	// MainnetParams are the params for mainnet.
@@ -102,7 +114,7 @@ var (
 
 	// TestnetGenesisBlockIdentifier is the genesis block for testnet.
 	TestnetGenesisBlockIdentifier = &types.BlockIdentifier{
-		Hash: "00000000917e049641189c33d6b1275155e89b7b498b3b4f16d488f60afe513b",
+		Hash: chaincfg.TestNet3Params.GenesisHash.String(),
 	}
 
 	// TestnetParams are the params for testnet.
@@ -114,6 +126,15 @@ var (
 		Decimals: Decimals,
 	}
 
+	// RegtestParams are the params for regtest.
+	RegtestParams = &chaincfg.RegressionNetParams
+
+	// RegtestCurrency is the *types.Currency for regtest.
+	RegtestCurrency = &types.Currency{
+		Symbol:   "rTHT",
+		Decimals: Decimals,
+	}
+
 	// OperationTypes are all supported operation.Types.
 	OperationTypes = []string{
 		InputOpType,
@@ -263,6 +284,17 @@ type TransactionMetadata struct {
 	Weight   int64 `json:"weight,omitempty"`
 }
 
+// MempoolEntry is the mempool data for a transaction
+// returned by `getmempoolentry`. This struct only contains
+// the information necessary for this implementation.
+type MempoolEntry struct {
+	Size    int64    `json:"size"`
+	Fee     float64  `json:"fee"`
+	Time    int64    `json:"time"`
+	Height  int64    `json:"height"`
+	Depends []string `json:"depends"`
+}
+
 // Input is a raw input in a Thought transaction.
 type Input struct {
 	TxHash      string     `json:"txid"`
@@ -318,6 +350,36 @@ type OperationMetadata struct {
 	ScriptPubKey *ScriptPubKey `json:"scriptPubKey,omitempty"`
 }
 
+// HistoryTransaction is a transaction in the history of an
+// account and the net amount it adds to the balance of the
+// account (negative if the balance decreases).
+type HistoryTransaction struct {
+	BlockIdentifier       *types.BlockIdentifier
+	TransactionIdentifier *types.TransactionIdentifier
+	NetAmount             *types.Amount
+}
+
+// Statistics are the aggregates of the
+// unspent coins at a block.
+type Statistics struct {
+	BlockIdentifier *types.BlockIdentifier
+
+	// Supply is the value of all unspent coins.
+	Supply       *types.Amount
+	CoinCount    int64
+	AddressCount int64
+
+	// RichList is the funded addresses with the largest
+	// balances, in descending order of balance.
+	RichList []*AccountBalance
+}
+
+// AccountBalance is the balance of an account.
+type AccountBalance struct {
+	AccountIdentifier *types.AccountIdentifier
+	Balance           *types.Amount
+}
+
 // request represents the JSON-RPC request body
 type request struct {
 	JSONRPC string        `json:"jsonrpc"`
@@ -500,6 +562,52 @@ func (r rawMempoolResponse) Err() error {
 	)
 }
 
+// rawTransactionResponse is the response body for `getrawtransaction` requests.
+type rawTransactionResponse struct {
+	Result *Transaction   `json:"result"`
+	Error  *responseError `json:"error"`
+}
+
+func (r rawTransactionResponse) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+
+	if r.Error.Code == transactionNotFoundErrCode {
+		return ErrTransactionNotFound
+	}
+
+	return fmt.Errorf(
+		"%w: error JSON RPC response, code: %d, message: %s",
+		ErrJSONRPCError,
+		r.Error.Code,
+		r.Error.Message,
+	)
+}
+
+// mempoolEntryResponse is the response body for `getmempoolentry` requests.
+type mempoolEntryResponse struct {
+	Result *MempoolEntry  `json:"result"`
+	Error  *responseError `json:"error"`
+}
+
+func (m mempoolEntryResponse) Err() error {
+	if m.Error == nil {
+		return nil
+	}
+
+	if m.Error.Code == transactionNotFoundErrCode {
+		return ErrTransactionNotFound
+	}
+
+	return fmt.Errorf(
+		"%w: error JSON RPC response, code: %d, message: %s",
+		ErrJSONRPCError,
+		m.Error.Code,
+		m.Error.Message,
+	)
+}
+
 // CoinIdentifier converts a tx hash and vout into
 // the canonical CoinIdentifier.Identifier used in
 // rosetta-thought.
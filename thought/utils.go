@@ -15,6 +15,8 @@
 package thought
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -68,3 +70,23 @@ func ParseSingleAddress(
 
 	return class, address, nil
 }
+
+// ParsePubKeyScript returns the hex-encoded public
+// key of a hex-encoded pay-to-pubkey pkscript.
+func ParsePubKeyScript(scriptHex string) (string, error) {
+	script, err := hex.DecodeString(scriptHex)
+	if err != nil {
+		return "", fmt.Errorf("%w unable to decode script", err)
+	}
+
+	if txscript.GetScriptClass(script) != txscript.PubKeyTy {
+		return "", errors.New("script is not pay-to-pubkey")
+	}
+
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		return "", fmt.Errorf("%w unable to parse script", err)
+	}
+
+	return hex.EncodeToString(pushes[0]), nil
+}
@@ -6,6 +6,8 @@ package chaincfg
 
 import (
 	"fmt"
+
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/chaincfg/chainhash"
 )
 
 // NetMagic represents which Thought network a message belongs to.
@@ -51,6 +53,9 @@ type Params struct {
     // DefaultPort defines the default peer-to-peer port for the network.
     DefaultPort string
 
+    // GenesisHash is the starting block hash.
+    GenesisHash *chainhash.Hash
+
     // Address encoding magics
     PubKeyHashAddrID  byte // First byte of a P2PKH address
     ScriptHashAddrID  byte // First byte of a P2SH address
@@ -71,6 +76,9 @@ var MainNetParams = Params{
   Net:   MainNet,
   DefaultPort: "10618",
 
+  // Chain parameters
+  GenesisHash: newHashFromStr("00000000917e049641189c33d6b1275155e89b7b498b3b4f16d488f60afe513b"),
+
   // Address encoding magics
   PubKeyHashAddrID:  0x07, 
   ScriptHashAddrID:  0x09, 
@@ -94,6 +102,9 @@ var TestNet3Params = Params{
   Net:   TestNet3,
   DefaultPort: "11618",
 
+  // Chain parameters
+  GenesisHash: newHashFromStr("00000000917e049641189c33d6b1275155e89b7b498b3b4f16d488f60afe513b"),
+
   // Address encoding magics
   PubKeyHashAddrID:  0x6d,
   ScriptHashAddrID:  0xc1,
@@ -107,3 +118,41 @@ var TestNet3Params = Params{
   // address generation.
   HDCoinType: 1,
 }
+
+// RegressionNetParams defines the network parameters for the regression test
+// Thought network. Blocks are mined on demand, so this network is only used
+// for private chains in tests. Addresses and keys are encoded as on testnet.
+//
+// Net and GenesisHash are not populated because they have not been taken
+// from Thought Core's chain parameters. The genesis hash is configured
+// with REGTEST_GENESIS_HASH instead.
+var RegressionNetParams = Params{
+  Name:  "regtest",
+  DefaultPort: "12618",
+
+  // Address encoding magics
+  PubKeyHashAddrID:  0x6d,
+  ScriptHashAddrID:  0xc1,
+  PrivateKeyID:   0xeb,
+
+  // BIP32 hierarchical deterministic extended key magics
+  HDPublicKeyID: [4]byte{0x5d, 0x40, 0x5f, 0x7a}, // starts with tpub
+  HDPrivateKeyID: [4]byte{0xb6, 0xf1, 0x3f, 0x50}, // starts with tprv
+
+  // BIP44 coin type used in the hierarchical deterministic path for
+  // address generation.
+  HDCoinType: 1,
+}
+
+// newHashFromStr converts the passed big-endian hex string into a
+// chainhash.Hash.  It only differs from the one available in chainhash in that
+// it panics on an error since it will only (and must only) be called with
+// hard-coded, and therefore known good, hashes.
+func newHashFromStr(hexStr string) *chainhash.Hash {
+	hash, err := chainhash.NewHashFromStr(hexStr)
+	if err != nil {
+		panic(err)
+	}
+
+	return hash
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2014-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+// References:
+//   [BIP32]: BIP0032 - Hierarchical Deterministic Wallets
+//   https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/base58"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/chaincfg"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/thtec"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/util"
+)
+
+const (
+	// HardenedKeyStart is the index at which a hardened key starts.  Each
+	// extended key has 2^31 normal child keys and 2^31 hardened child keys.
+	// Thus the range for normal child keys is [0, 2^31 - 1] and the range
+	// for hardened child keys is [2^31, 2^32 - 1].
+	HardenedKeyStart = 0x80000000 // 2^31
+
+	// serializedKeyLen is the length of a serialized public or private
+	// extended key.  It consists of 4 bytes version, 1 byte depth, 4 bytes
+	// fingerprint, 4 bytes child number, 32 bytes chain code, and 33 bytes
+	// public/private key data.
+	serializedKeyLen = 4 + 1 + 4 + 4 + 32 + 33 // 78 bytes
+)
+
+var (
+	// ErrDeriveHardFromPublic describes an error in which the caller
+	// attempted to derive a hardened extended key from a public key.
+	ErrDeriveHardFromPublic = errors.New("cannot derive a hardened key " +
+		"from a public key")
+
+	// ErrInvalidChild describes an error in which the child at a specific
+	// index is invalid due to the derived key falling outside of the valid
+	// range for secp256k1 private keys.  This error indicates the caller
+	// should simply ignore the invalid child extended key at this index and
+	// go to the next index.
+	ErrInvalidChild = errors.New("the extended key at this index is invalid")
+
+	// ErrPrivateKey describes an error in which the provided extended key
+	// is a private key.  Only extended public keys are supported.
+	ErrPrivateKey = errors.New("extended private keys are not supported")
+
+	// ErrWrongNetwork describes an error in which the version of the
+	// provided extended key is not for the expected network.
+	ErrWrongNetwork = errors.New("the extended key is for the wrong network")
+
+	// ErrBadChecksum describes an error in which the checksum encoded with
+	// a serialized extended key does not match the calculated value.
+	ErrBadChecksum = errors.New("bad extended key checksum")
+
+	// ErrInvalidKeyLen describes an error in which the provided serialized
+	// key is not the expected length.
+	ErrInvalidKeyLen = errors.New("the provided serialized extended key " +
+		"length is invalid")
+)
+
+// ExtendedKey houses all the information needed to support a hierarchical
+// deterministic extended public key.  See the package overview
+// documentation for more details on how to use extended keys.
+type ExtendedKey struct {
+	version   []byte
+	key       []byte // This will be the compressed public key
+	chainCode []byte
+	parentFP  []byte
+	depth     uint8
+	childNum  uint32
+}
+
+// Depth returns the current derivation level with respect to the root.
+//
+// The root key has depth zero, and the field has a maximum of 255 due to
+// how depth is serialized.
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// ChildIndex returns the index at which the child extended key was derived.
+//
+// Extended keys with depth value of 0 (the master key) have a child index
+// of 0.
+func (k *ExtendedKey) ChildIndex() uint32 {
+	return k.childNum
+}
+
+// Derive returns a derived child extended public key at the given index.
+// Since only public keys are supported, i must be less than
+// HardenedKeyStart.
+//
+// NOTE: There is an extremely small chance (< 1 in 2^127) the specific child
+// index does not derive to a usable child.  The ErrInvalidChild error will be
+// returned if this should occur, and the caller is expected to ignore the
+// invalid child and simply increment to the next index.
+func (k *ExtendedKey) Derive(i uint32) (*ExtendedKey, error) {
+	if i >= HardenedKeyStart {
+		return nil, ErrDeriveHardFromPublic
+	}
+
+	// The data used to derive the child key is the parent public key
+	// followed by the 4-byte big-endian child index:
+	//   data = serP(point(kpar)) || ser32(i)
+	data := make([]byte, len(k.key)+4)
+	copy(data, k.key)
+	binary.BigEndian.PutUint32(data[len(k.key):], i)
+
+	// Take the HMAC-SHA512 of the current key's chain code and the derived
+	// data:
+	//   I = HMAC-SHA512(Key = chainCode, Data = data)
+	hmac512 := hmac.New(sha512.New, k.chainCode)
+	_, _ = hmac512.Write(data)
+	ilr := hmac512.Sum(nil)
+
+	// Split "I" into two 32-byte sequences Il and Ir where:
+	//   Il = intermediate key used to derive the child
+	//   Ir = child chain code
+	il := ilr[:len(ilr)/2]
+	childChainCode := ilr[len(ilr)/2:]
+
+	// Both derived public or private keys rely on treating the left 32-byte
+	// sequence calculated above (Il) as a 256-bit integer that must be
+	// within the valid range for a secp256k1 private key.  There is a small
+	// chance (< 1 in 2^127) this condition will not hold, and in that case,
+	// a child extended key can't be created for this index and the caller
+	// should simply increment to the next index.
+	var ilNum thtec.ModNScalar
+	if overflow := ilNum.SetByteSlice(il); overflow || ilNum.IsZero() {
+		return nil, ErrInvalidChild
+	}
+
+	// The algorithm used to derive the public child key is:
+	//   childKey = serP(point(parse256(Il)) + parentKey)
+	parentKey, err := thtec.ParsePubKey(k.key)
+	if err != nil {
+		return nil, err
+	}
+
+	var ilPoint, parentPoint, childPoint thtec.JacobianPoint
+	thtec.ScalarBaseMultNonConst(&ilNum, &ilPoint)
+	parentKey.AsJacobian(&parentPoint)
+	thtec.AddNonConst(&ilPoint, &parentPoint, &childPoint)
+
+	// The child key is invalid if it is the point at infinity.
+	if (childPoint.X.IsZero() && childPoint.Y.IsZero()) || childPoint.Z.IsZero() {
+		return nil, ErrInvalidChild
+	}
+	childPoint.ToAffine()
+	childKey := thtec.NewPublicKey(&childPoint.X, &childPoint.Y).SerializeCompressed()
+
+	// The fingerprint of the parent for the derived child is the first 4
+	// bytes of the RIPEMD160(SHA256(parentPubKey)).
+	parentFP := util.Hash160(k.key)[:4]
+
+	return &ExtendedKey{
+		version:   k.version,
+		key:       childKey,
+		chainCode: childChainCode,
+		parentFP:  parentFP,
+		depth:     k.depth + 1,
+		childNum:  i,
+	}, nil
+}
+
+// ECPubKey converts the extended key to a thtec public key and returns it.
+func (k *ExtendedKey) ECPubKey() (*thtec.PublicKey, error) {
+	return thtec.ParsePubKey(k.key)
+}
+
+// Address converts the extended key to a standard thought pay-to-pubkey-hash
+// address for the passed network.
+func (k *ExtendedKey) Address(net *chaincfg.Params) (*util.AddressPubKeyHash, error) {
+	return util.NewAddressPubKeyHash(util.Hash160(k.key), net)
+}
+
+// String returns the extended key as a human-readable base58-encoded string.
+func (k *ExtendedKey) String() string {
+	var childNumBytes [4]byte
+	binary.BigEndian.PutUint32(childNumBytes[:], k.childNum)
+
+	// The serialized format is:
+	//   version (4) || depth (1) || parent fingerprint (4)) ||
+	//   child num (4) || chain code (32) || key data (33) || checksum (4)
+	serializedBytes := make([]byte, 0, serializedKeyLen+4)
+	serializedBytes = append(serializedBytes, k.version...)
+	serializedBytes = append(serializedBytes, k.depth)
+	serializedBytes = append(serializedBytes, k.parentFP...)
+	serializedBytes = append(serializedBytes, childNumBytes[:]...)
+	serializedBytes = append(serializedBytes, k.chainCode...)
+	serializedBytes = append(serializedBytes, k.key...)
+
+	checkSum := doubleHashB(serializedBytes)[:4]
+	serializedBytes = append(serializedBytes, checkSum...)
+	return base58.Encode(serializedBytes)
+}
+
+// NewKeyFromString returns a new extended public key instance from a
+// base58-encoded extended key for the passed network.
+func NewKeyFromString(key string, net *chaincfg.Params) (*ExtendedKey, error) {
+	// The base58-decoded extended key must consist of a serialized payload
+	// plus an additional 4 bytes for the checksum.
+	decoded := base58.Decode(key)
+	if len(decoded) != serializedKeyLen+4 {
+		return nil, ErrInvalidKeyLen
+	}
+
+	// The serialized format is:
+	//   version (4) || depth (1) || parent fingerprint (4)) ||
+	//   child num (4) || chain code (32) || key data (33) || checksum (4)
+
+	// Split the payload and checksum up and ensure the checksum matches.
+	payload := decoded[:len(decoded)-4]
+	checkSum := decoded[len(decoded)-4:]
+	expectedCheckSum := doubleHashB(payload)[:4]
+	if !bytes.Equal(checkSum, expectedCheckSum) {
+		return nil, ErrBadChecksum
+	}
+
+	// Deserialize each of the payload fields.
+	version := payload[:4]
+	depth := payload[4:5][0]
+	parentFP := payload[5:9]
+	childNum := binary.BigEndian.Uint32(payload[9:13])
+	chainCode := payload[13:45]
+	keyData := payload[45:78]
+
+	if bytes.Equal(version, net.HDPrivateKeyID[:]) {
+		return nil, ErrPrivateKey
+	}
+
+	if !bytes.Equal(version, net.HDPublicKeyID[:]) {
+		return nil, ErrWrongNetwork
+	}
+
+	// Ensure the public key parses correctly and is actually on the
+	// secp256k1 curve.
+	if _, err := thtec.ParsePubKey(keyData); err != nil {
+		return nil, err
+	}
+
+	return &ExtendedKey{
+		version:   version,
+		key:       keyData,
+		chainCode: chainCode,
+		parentFP:  parentFP,
+		depth:     depth,
+		childNum:  childNum,
+	}, nil
+}
+
+// doubleHashB calculates sha256(sha256(b)) and returns the resulting bytes.
+func doubleHashB(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2014-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/chaincfg"
+	"github.com/thoughtnetwork/rosetta-thought/thoughtd/util/hdkeychain"
+)
+
+// bip0032Params uses the bitcoin mainnet version bytes of the BIP32 test
+// vectors, so the vectors can be used verbatim.
+var bip0032Params = &chaincfg.Params{
+	Name:           "bip0032",
+	HDPrivateKeyID: [4]byte{0x04, 0x88, 0xad, 0xe4}, // starts with xprv
+	HDPublicKeyID:  [4]byte{0x04, 0x88, 0xb2, 0x1e}, // starts with xpub
+}
+
+// TestBIP0032Vectors tests the public derivation chains of the BIP32 test
+// vectors.  Hardened children cannot be derived from an extended public key,
+// so every test starts at the last hardened key of its chain.
+func TestBIP0032Vectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		path     []uint32
+		want     string
+		depth    uint8
+		childNum uint32
+	}{
+		// Test vector 1
+		{
+			name:     "test vector 1 chain m/0H/1",
+			start:    "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+			path:     []uint32{1},
+			want:     "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+			depth:    2,
+			childNum: 1,
+		},
+		{
+			name:     "test vector 1 chain m/0H/1/2H/2",
+			start:    "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			path:     []uint32{2},
+			want:     "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+			depth:    4,
+			childNum: 2,
+		},
+		{
+			name:     "test vector 1 chain m/0H/1/2H/2/1000000000",
+			start:    "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			path:     []uint32{2, 1000000000},
+			want:     "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+			depth:    5,
+			childNum: 1000000000,
+		},
+
+		// Test vector 2
+		{
+			name:     "test vector 2 chain m",
+			start:    "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+			path:     []uint32{},
+			want:     "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+			depth:    0,
+			childNum: 0,
+		},
+		{
+			name:     "test vector 2 chain m/0",
+			start:    "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+			path:     []uint32{0},
+			want:     "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+			depth:    1,
+			childNum: 0,
+		},
+		{
+			name:     "test vector 2 chain m/0/2147483647H/1",
+			start:    "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a",
+			path:     []uint32{1},
+			want:     "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon",
+			depth:    3,
+			childNum: 1,
+		},
+		{
+			name:     "test vector 2 chain m/0/2147483647H/1/2147483646H/2",
+			start:    "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL",
+			path:     []uint32{2},
+			want:     "xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt",
+			depth:    5,
+			childNum: 2,
+		},
+	}
+
+	for i, test := range tests {
+		extKey, err := hdkeychain.NewKeyFromString(test.start, bip0032Params)
+		if err != nil {
+			t.Errorf("NewKeyFromString #%d (%s): unexpected error "+
+				"creating extended key: %v", i, test.name, err)
+			continue
+		}
+
+		for _, childNum := range test.path {
+			var err error
+			extKey, err = extKey.Derive(childNum)
+			if err != nil {
+				t.Errorf("Derive #%d (%s): unexpected error: %v",
+					i, test.name, err)
+				break
+			}
+		}
+		if err != nil {
+			continue
+		}
+
+		if extKey.Depth() != test.depth {
+			t.Errorf("Depth #%d (%s): mismatched depth -- got %d, "+
+				"want %d", i, test.name, extKey.Depth(), test.depth)
+			continue
+		}
+
+		if extKey.ChildIndex() != test.childNum {
+			t.Errorf("ChildIndex #%d (%s): mismatched child index "+
+				"-- got %d, want %d", i, test.name,
+				extKey.ChildIndex(), test.childNum)
+			continue
+		}
+
+		if pubStr := extKey.String(); pubStr != test.want {
+			t.Errorf("Derive #%d (%s): mismatched serialized "+
+				"public extended key -- got: %s, want: %s", i,
+				test.name, pubStr, test.want)
+			continue
+		}
+	}
+}
+
+// TestAddress ensures P2PKH addresses derived from an extended key match
+// addresses computed by an independent BIP32 implementation.
+func TestAddress(t *testing.T) {
+	// The BIP32 test vector 1 key m/0H with testnet magic bytes
+	xpub := "LoBz4NyCak3EYnSWUPpZZbE2YSeCBE6RDYdTxzyrT6zZYG6zEFcrQDmpsDaUeQka3jdiMCBw9RqVpVoD8DUD3v2Fdis8DjojPT9iJukDMWQ1DhuB"
+	tests := []struct {
+		chain uint32
+		index uint32
+		want  string
+	}{
+		{chain: 0, index: 0, want: "m3mSCWMxoAQFUgHb3eZyzXbqyYaqwBPSUi"},
+		{chain: 0, index: 1, want: "m2rCeWMc9AaqYLcTRAqDonN6V3EaSMpMdo"},
+		{chain: 0, index: 2, want: "m4s6FNVrSdxa6ktFCUqS2GMmGacrcyEJMf"},
+		{chain: 1, index: 0, want: "m9vbyS69oFRHShgF3Diy5GJvf8JeoaiHpj"},
+		{chain: 1, index: 1, want: "kw7hAuYXhS86zJcuXgJhD7TuJdzkkre8wc"},
+	}
+
+	extKey, err := hdkeychain.NewKeyFromString(xpub, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewKeyFromString: unexpected error: %v", err)
+	}
+
+	for i, test := range tests {
+		chainKey, err := extKey.Derive(test.chain)
+		if err != nil {
+			t.Errorf("Derive #%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		addrKey, err := chainKey.Derive(test.index)
+		if err != nil {
+			t.Errorf("Derive #%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		addr, err := addrKey.Address(&chaincfg.TestNet3Params)
+		if err != nil {
+			t.Errorf("Address #%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if addr.EncodeAddress() != test.want {
+			t.Errorf("Address #%d: mismatched address -- got %s, "+
+				"want %s", i, addr.EncodeAddress(), test.want)
+		}
+	}
+}
+
+// TestDeriveHardened ensures hardened children cannot be derived from an
+// extended public key.
+func TestDeriveHardened(t *testing.T) {
+	extKey, err := hdkeychain.NewKeyFromString(
+		"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		bip0032Params,
+	)
+	if err != nil {
+		t.Fatalf("NewKeyFromString: unexpected error: %v", err)
+	}
+
+	for _, childNum := range []uint32{
+		hdkeychain.HardenedKeyStart,
+		hdkeychain.HardenedKeyStart + 1,
+		0xffffffff,
+	} {
+		if _, err := extKey.Derive(childNum); !errors.Is(err, hdkeychain.ErrDeriveHardFromPublic) {
+			t.Errorf("Derive %d: mismatched error -- got %v, want %v",
+				childNum, err, hdkeychain.ErrDeriveHardFromPublic)
+		}
+	}
+}
+
+// TestErrors performs some negative tests for various invalid cases to ensure
+// the errors are handled properly.
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		net  *chaincfg.Params
+		err  error
+	}{
+		{
+			name: "invalid key length",
+			key:  "xpub1234",
+			net:  bip0032Params,
+			err:  hdkeychain.ErrInvalidKeyLen,
+		},
+		{
+			name: "bad checksum",
+			key:  "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet7",
+			net:  bip0032Params,
+			err:  hdkeychain.ErrBadChecksum,
+		},
+		{
+			name: "private key",
+			key:  "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+			net:  bip0032Params,
+			err:  hdkeychain.ErrPrivateKey,
+		},
+		{
+			name: "wrong version",
+			key:  "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+			net:  &chaincfg.TestNet3Params,
+			err:  hdkeychain.ErrWrongNetwork,
+		},
+		{
+			name: "mainnet key on testnet",
+			key:  "LJVu9KB6TGUsKHD6HNro6fupKTVh9mSAkyC1c2x5CnTbxe63j841svMM34txUt1od92AqwwrmTZVZLczF8dcsiLcvNj93Cr9dwpiWDoqA2f2QAx1",
+			net:  &chaincfg.TestNet3Params,
+			err:  hdkeychain.ErrWrongNetwork,
+		},
+	}
+
+	for i, test := range tests {
+		_, err := hdkeychain.NewKeyFromString(test.key, test.net)
+		if !errors.Is(err, test.err) {
+			t.Errorf("NewKeyFromString #%d (%s): mismatched error "+
+				"-- got: %v, want: %v", i, test.name, err, test.err)
+		}
+	}
+}
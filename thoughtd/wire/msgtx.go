@@ -14,9 +14,11 @@ import (
 )
 
 const (
-	// MainnetTxVersion and TestnetTxVersion are the transaction versions of each chain.
+	// MainnetTxVersion, TestnetTxVersion and RegtestTxVersion are the
+	// transaction versions of each chain.
 	MainnetTxVersion = 3
 	TestnetTxVersion = 2
+	RegtestTxVersion = 2
 
 	// MaxTxInSequenceNum is the maximum sequence number the sequence field
 	// of a transaction input can be.